@@ -7,6 +7,7 @@ import (
 
 var (
 	AuthPermitConfig AuthPermitAll
+	JwtConfig        Jwt
 )
 
 // Http配置
@@ -14,6 +15,12 @@ type AuthPermitAll struct {
 	PermitAll []interface{}
 }
 
+// Jwt 网关本地校验令牌的配置, 密钥需与 oauth-service 签发令牌使用的密钥一致
+type Jwt struct {
+	Secret       string
+	CacheSeconds int //校验结果的缓存时长, 为 0 时不缓存
+}
+
 func Match(str string) bool {
 	if len(AuthPermitConfig.PermitAll) > 0 {
 		targetValue := AuthPermitConfig.PermitAll
@@ -9,7 +9,9 @@ import (
 )
 
 const (
-	kConfigType = "CONFIG_TYPE"
+	kConfigType      = "CONFIG_TYPE"
+	kJwtSecret       = "jwt.secret"
+	kJwtCacheSeconds = "jwt.cacheSeconds"
 )
 
 var Logger log.Logger
@@ -27,7 +29,17 @@ func init() {
 	if err := conf.Sub("auth", &AuthPermitConfig); err != nil {
 		Logger.Log("Fail to parse config", err)
 	}
+	// Redis 仅用于集群范围的限流, 未配置时只能使用本地限流
+	if viper.IsSet("redis") {
+		if err := conf.Sub("redis", &conf.Redis); err != nil {
+			Logger.Log("Fail to parse redis", err)
+		}
+	}
+	JwtConfig.Secret = viper.GetString(kJwtSecret)
+	JwtConfig.CacheSeconds = viper.GetInt(kJwtCacheSeconds)
 }
 func initDefault() {
 	viper.SetDefault(kConfigType, "yaml")
+	viper.SetDefault(kJwtSecret, "secret")
+	viper.SetDefault(kJwtCacheSeconds, 30)
 }
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// RouteConfig 网关路由配置, 从 routes.yaml 读取, 文件修改后自动重新加载
+// 请求按 Routes 的顺序匹配, 均未匹配时沿用原有规则: 以路径第一段作为服务名转发,
+// Aliases 可将路径第一段映射为其他服务名, 以对外屏蔽真实服务名
+type RouteConfig struct {
+	Routes         []RouteRule
+	Aliases        map[string]string      //路径第一段 -> 服务名, 配置加载时键会被转为小写
+	Services       map[string]ServiceRule //服务名 -> 转发到该服务的默认配置
+	RateLimit      RateLimitConfig
+	Outlier        OutlierRule
+	Transport      TransportRule
+	LoadBalance    string //默认的负载均衡策略, 为空时随机选择实例
+	ConsistentHash ConsistentHashRule
+}
+
+// ServiceRule 服务级别的默认配置, 路由未设置的项使用所属服务的配置
+type ServiceRule struct {
+	Timeout     int //超时时间, 单位毫秒
+	Hystrix     HystrixRule
+	Retry       RetryRule
+	LoadBalance string //负载均衡策略: random, weight_round_robin, least_conn, consistent_hash
+}
+
+// HystrixRule 断路器配置, 为 0 的项使用上一级配置或 hystrix 的默认值
+type HystrixRule struct {
+	MaxConcurrentRequests  int //最大并发请求数, 修改后会重建所有断路器
+	ErrorPercentThreshold  int //统计窗口内错误率达到该百分比时打开断路器
+	RequestVolumeThreshold int //统计窗口内请求数达到该值后才计算错误率
+	SleepWindow            int //断路器打开后经过该时间(毫秒)放行一个请求探测服务是否恢复
+}
+
+// RouteRule 单条路由规则
+type RouteRule struct {
+	Name        string            //路由名称, 同时作为 hystrix 命令名, 为空时使用目标服务名
+	Prefix      string            //路径前缀, 与 Regex 二选一
+	Regex       string            //路径正则表达式
+	Methods     []string          //允许的请求方法, 为空时不限制
+	Headers     map[string]string //需要匹配的请求头及其正则表达式
+	StripPrefix bool              //转发前去掉匹配的路径前缀, 仅对 Prefix 有效
+	Rewrite     string            //使用 Prefix 时替换匹配的前缀, 使用 Regex 时为转发路径, 可通过 $1 等引用分组
+	Service     string            //目标服务名
+	Timeout     int               //超时时间, 单位毫秒, 为 0 时使用所属服务的配置或默认值
+	Hystrix     HystrixRule       //断路器配置
+	Cache       CacheRule         //响应缓存配置
+	Retry       RetryRule         //重试及对冲请求配置
+}
+
+// RetryRule 转发失败时的重试配置, 为 0 的项使用所属服务的配置, 均未设置时不重试、不对冲
+// 只有连接实例失败(请求未到达后端)时才重试, 且只重试幂等的请求方法, 每次重试换一个未尝试过的实例
+type RetryRule struct {
+	Attempts   int //最多重试次数
+	HedgeDelay int //GET 请求超过该时间(毫秒)未返回时向另一个实例发出对冲请求, 采用先返回的响应
+}
+
+// OutlierRule 异常实例摘除配置, 为 0 的项使用默认值
+// 实例连续失败(连接失败、超时或返回 502/503/504)达到阈值后被摘除一段时间, 期间不再向其转发请求
+type OutlierRule struct {
+	ConsecutiveFailures int //连续失败该次数后摘除, 默认 5
+	EjectionTime        int //首次摘除的时长(毫秒), 默认 30000; 恢复后再次被摘除时时长按次数递增
+	MaxEjectionPercent  int //服务被摘除的实例超过该百分比时忽略摘除, 避免流量集中到少数实例, 默认 50
+}
+
+// CacheRule 响应缓存配置, 只缓存状态码为 200 且后端未通过 Cache-Control 禁止缓存的响应,
+// 缓存在网关实例间不共享, 且不区分用户, 只适用于与用户无关的只读接口
+type CacheRule struct {
+	Ttl     int      //缓存时间, 单位毫秒, 为 0 时不缓存; 后端响应的 max-age 更短时以 max-age 为准
+	Methods []string //缓存的请求方法, 为空时只缓存 GET; 非 GET 请求以请求体区分缓存
+}
+
+// TransportRule 网关到上游服务的连接池配置, 每个服务一个连接池, 为 0 的项使用默认值
+type TransportRule struct {
+	MaxIdleConns          int //所有实例的最大空闲连接数, 默认 1000
+	MaxIdleConnsPerHost   int //每个实例的最大空闲连接数, 默认 100
+	MaxConnsPerHost       int //每个实例的最大连接数, 默认不限制
+	IdleConnTimeout       int //空闲连接的保留时间(毫秒), 默认 90000
+	DialTimeout           int //建立连接的超时时间(毫秒), 默认 1000
+	ResponseHeaderTimeout int //等待响应头的超时时间(毫秒), 默认只受路由超时限制
+}
+
+// ConsistentHashRule 一致性哈希负载均衡配置, 使用该策略的服务按用户 id 将同一用户的请求转发到同一实例
+type ConsistentHashRule struct {
+	WarmUp int //新实例的预热时长(毫秒), 期间归属新实例的用户逐步迁移过去, 默认 60000
+}
+
+// 限流的计数维度
+const (
+	RateLimitKeyRoute  = "route"  //按路由
+	RateLimitKeyClient = "client" //按令牌所属的客户端
+	RateLimitKeyUser   = "user"   //按令牌所属的用户
+	RateLimitKeyIp     = "ip"     //按客户端 IP
+)
+
+// 限流计数的存储方式
+const (
+	RateLimitBackendLocal = "local" //网关实例内存, 各实例分别计数
+	RateLimitBackendRedis = "redis" //Redis, 所有网关实例共享配额
+)
+
+// RateLimitConfig 网关限流配置, 请求需通过所有匹配规则的令牌桶才会被转发
+type RateLimitConfig struct {
+	Backend string //为空时使用 local
+	Rules   []RateLimitRule
+}
+
+// RateLimitRule 单条限流规则
+type RateLimitRule struct {
+	Name   string   //规则名称, 不同规则的计数互相独立
+	Routes []string //生效的路由名称, 为空时对所有路由生效
+	Key    string   //计数维度, 请求缺少该维度(如未携带令牌)时不受此规则限制
+	Rate   float64  //每秒生成的令牌数
+	Burst  int      //令牌桶容量
+}
+
+var routeViper = viper.New()
+
+func init() {
+	routeViper.SetConfigName("routes")
+	routeViper.AddConfigPath("./")
+	routeViper.SetConfigType("yaml")
+}
+
+// LoadRouteConfig 读取路由配置, 配置文件不存在时返回空配置
+func LoadRouteConfig() (*RouteConfig, error) {
+	if err := routeViper.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
+			return &RouteConfig{}, nil
+		}
+		return nil, err
+	}
+	return parseRouteConfig()
+}
+
+// WatchRouteConfig 监听路由配置文件, 修改后重新解析并回调 onChange, 解析失败时回调 onError
+// 需在 LoadRouteConfig 之后调用, 启动时配置文件不存在则不监听
+func WatchRouteConfig(onChange func(*RouteConfig), onError func(error)) {
+	if routeViper.ConfigFileUsed() == "" {
+		// 配置文件不存在时 viper 的 WatchConfig 会一直阻塞
+		return
+	}
+	routeViper.OnConfigChange(func(fsnotify.Event) {
+		routeConfig, err := parseRouteConfig()
+		if err != nil {
+			onError(err)
+			return
+		}
+		onChange(routeConfig)
+	})
+	routeViper.WatchConfig()
+}
+
+func parseRouteConfig() (*RouteConfig, error) {
+	var routeConfig RouteConfig
+	if err := routeViper.Unmarshal(&routeConfig); err != nil {
+		return nil, err
+	}
+	return &routeConfig, nil
+}
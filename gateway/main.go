@@ -73,11 +73,14 @@ func main() {
 
 	errc := make(chan error)
 
-	//启用hystrix实时监控，监听端口为9010
+	//启用hystrix实时监控，监听端口为9010, /admin/breakers 返回各断路器的状态和配置
 	hystrixStreamHandler := hystrix.NewStreamHandler()
 	hystrixStreamHandler.Start()
+	adminMux := http.NewServeMux()
+	adminMux.Handle("/admin/breakers", hystrixRouter.BreakerHandler())
+	adminMux.Handle("/", hystrixStreamHandler)
 	go func() {
-		errc <- http.ListenAndServe(net.JoinHostPort("", "9010"), hystrixStreamHandler)
+		errc <- http.ListenAndServe(net.JoinHostPort("", "9010"), adminMux)
 	}()
 
 	go func() {
@@ -0,0 +1,28 @@
+package proxy
+
+import "sync"
+
+const proxyBufferSize = 32 << 10
+
+// BufferPool 实现 httputil.BufferPool, 复用反向代理复制响应体时使用的缓冲区
+type BufferPool struct {
+	pool sync.Pool
+}
+
+func NewBufferPool() *BufferPool {
+	return &BufferPool{
+		pool: sync.Pool{
+			New: func() interface{} {
+				return make([]byte, proxyBufferSize)
+			},
+		},
+	}
+}
+
+func (p *BufferPool) Get() []byte {
+	return p.pool.Get().([]byte)
+}
+
+func (p *BufferPool) Put(buf []byte) {
+	p.pool.Put(buf)
+}
@@ -0,0 +1,161 @@
+package proxy
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lixichongAAA/seckill/pkg/common"
+)
+
+// TransportOptions 网关到上游服务的连接池配置
+type TransportOptions struct {
+	MaxIdleConns          int           //所有实例的最大空闲连接数
+	MaxIdleConnsPerHost   int           //每个实例的最大空闲连接数
+	MaxConnsPerHost       int           //每个实例的最大连接数, 为 0 时不限制
+	IdleConnTimeout       time.Duration //空闲连接的保留时间
+	DialTimeout           time.Duration //建立连接的超时时间
+	KeepAlive             time.Duration //TCP keep-alive 探测间隔
+	ResponseHeaderTimeout time.Duration //发送请求后等待响应头的超时时间, 为 0 时只受路由超时限制
+}
+
+// DefaultTransportOptions 默认的连接池配置
+// http.Transport 默认每个实例只保留 2 个空闲连接, 并发较高时大部分连接用完即关闭, 因此需要调大
+func DefaultTransportOptions() TransportOptions {
+	return TransportOptions{
+		MaxIdleConns:        1000,
+		MaxIdleConnsPerHost: 100,
+		IdleConnTimeout:     90 * time.Second,
+		DialTimeout:         time.Second,
+		KeepAlive:           30 * time.Second,
+	}
+}
+
+// TransportPool 为每个上游服务维护一个 http.Transport, 请求复用到该服务各实例的长连接;
+// 服务的实例列表变化时关闭空闲连接, 避免保留到已下线实例的连接, 连接池配置变化时重建所有 Transport
+type TransportPool struct {
+	lock      sync.RWMutex
+	options   TransportOptions
+	wrap      func(http.RoundTripper) http.RoundTripper
+	upstreams map[string]*upstream
+}
+
+type upstream struct {
+	transport    *http.Transport
+	roundTripper http.RoundTripper
+	instances    []*common.ServiceInstance //创建或上次刷新时的实例列表
+	addrs        map[string]bool
+}
+
+// NewTransportPool wrap 用于在 Transport 外增加追踪等逻辑, 可以为 nil
+func NewTransportPool(options TransportOptions, wrap func(http.RoundTripper) http.RoundTripper) *TransportPool {
+	return &TransportPool{
+		options:   options,
+		wrap:      wrap,
+		upstreams: make(map[string]*upstream),
+	}
+}
+
+// Get 返回转发到服务的 RoundTripper, instances 为服务当前的实例列表
+func (p *TransportPool) Get(service string, instances []*common.ServiceInstance) http.RoundTripper {
+	p.lock.RLock()
+	up, ok := p.upstreams[service]
+	if ok && sameSlice(up.instances, instances) {
+		p.lock.RUnlock()
+		return up.roundTripper
+	}
+	p.lock.RUnlock()
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	up, ok = p.upstreams[service]
+	if !ok {
+		up = p.newUpstream()
+		p.upstreams[service] = up
+	}
+	if !sameSlice(up.instances, instances) {
+		up.refresh(instances)
+	}
+	return up.roundTripper
+}
+
+// SetOptions 更新连接池配置, 配置变化时重建所有 Transport, 旧 Transport 上的请求完成后连接随之关闭
+func (p *TransportPool) SetOptions(options TransportOptions) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if options == p.options {
+		return
+	}
+	p.options = options
+	for service, up := range p.upstreams {
+		up.transport.CloseIdleConnections()
+		delete(p.upstreams, service)
+	}
+}
+
+// Close 关闭所有空闲连接
+func (p *TransportPool) Close() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for service, up := range p.upstreams {
+		up.transport.CloseIdleConnections()
+		delete(p.upstreams, service)
+	}
+}
+
+// newUpstream 调用方需持有锁
+func (p *TransportPool) newUpstream() *upstream {
+	transport := NewTransport(p.options)
+	up := &upstream{
+		transport:    transport,
+		roundTripper: transport,
+		addrs:        make(map[string]bool),
+	}
+	if p.wrap != nil {
+		up.roundTripper = p.wrap(transport)
+	}
+	return up
+}
+
+// refresh 记录新的实例列表, 有实例下线时关闭空闲连接; 关闭后到仍在线实例的连接按需重新建立
+func (up *upstream) refresh(instances []*common.ServiceInstance) {
+	addrs := make(map[string]bool, len(instances))
+	for _, instance := range instances {
+		addrs[net.JoinHostPort(instance.Host, strconv.Itoa(instance.Port))] = true
+	}
+	for addr := range up.addrs {
+		if !addrs[addr] {
+			up.transport.CloseIdleConnections()
+			break
+		}
+	}
+	up.instances = instances
+	up.addrs = addrs
+}
+
+// NewTransport 按配置创建 http.Transport
+func NewTransport(options TransportOptions) *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   options.DialTimeout,
+			KeepAlive: options.KeepAlive,
+		}).DialContext,
+		MaxIdleConns:          options.MaxIdleConns,
+		MaxIdleConnsPerHost:   options.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       options.MaxConnsPerHost,
+		IdleConnTimeout:       options.IdleConnTimeout,
+		ResponseHeaderTimeout: options.ResponseHeaderTimeout,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// sameSlice 服务发现在实例列表变化时会替换整个切片, 因此比较切片本身即可判断列表是否变化
+func sameSlice(a, b []*common.ServiceInstance) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	return len(a) == 0 || &a[0] == &b[0]
+}
@@ -0,0 +1,123 @@
+package proxy
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/lixichongAAA/seckill/pkg/common"
+	"github.com/openzipkin/zipkin-go"
+	zipkinhttpsvr "github.com/openzipkin/zipkin-go/middleware/http"
+	"github.com/openzipkin/zipkin-go/reporter"
+)
+
+func newBackend() (*httptest.Server, []*common.ServiceInstance) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result":"ok"}`))
+	}))
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+	return server, []*common.ServiceInstance{{Host: host, Port: portNum}}
+}
+
+func TestTransportPool(t *testing.T) {
+	options := DefaultTransportOptions()
+	pool := NewTransportPool(options, nil)
+	instances := []*common.ServiceInstance{{Host: "127.0.0.1", Port: 9000}, {Host: "127.0.0.1", Port: 9001}}
+
+	first := pool.Get("sk-app", instances)
+	if pool.Get("sk-app", instances) != first {
+		t.Error("same instance list should reuse the transport")
+	}
+	if pool.Get("sk-admin", instances) == first {
+		t.Error("different services should not share the transport")
+	}
+	if pool.Get("sk-app", instances[:1]) != first {
+		t.Error("instance list change should keep the transport")
+	}
+	if addrs := pool.upstreams["sk-app"].addrs; len(addrs) != 1 || !addrs["127.0.0.1:9000"] {
+		t.Errorf("instance list not refreshed: %v", addrs)
+	}
+
+	pool.SetOptions(options)
+	if pool.Get("sk-app", instances) != first {
+		t.Error("same options should keep the transport")
+	}
+	options.MaxIdleConnsPerHost = 10
+	pool.SetOptions(options)
+	second := pool.Get("sk-app", instances)
+	if second == first {
+		t.Error("options change should rebuild the transport")
+	}
+	if second.(*http.Transport).MaxIdleConnsPerHost != 10 {
+		t.Error("new options not applied")
+	}
+}
+
+// BenchmarkReverseProxyPerRequest 每个请求新建追踪 Transport 和 ReverseProxy, 底层为默认的 http.Transport
+func BenchmarkReverseProxyPerRequest(b *testing.B) {
+	server, _ := newBackend()
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+	tracer, _ := zipkin.NewTracer(reporter.NewNoopReporter())
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			roundTrip, _ := zipkinhttpsvr.NewTransport(tracer, zipkinhttpsvr.TransportTrace(true))
+			proxy := httputil.NewSingleHostReverseProxy(target)
+			proxy.Transport = roundTrip
+			serveOnce(b, proxy)
+		}
+	})
+	b.StopTimer()
+	http.DefaultTransport.(*http.Transport).CloseIdleConnections()
+}
+
+// BenchmarkReverseProxyPooled 共用 ReverseProxy, 使用 TransportPool 中按服务缓存的 Transport
+func BenchmarkReverseProxyPooled(b *testing.B) {
+	server, instances := newBackend()
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+	tracer, _ := zipkin.NewTracer(reporter.NewNoopReporter())
+	pool := NewTransportPool(DefaultTransportOptions(), func(rt http.RoundTripper) http.RoundTripper {
+		roundTrip, _ := zipkinhttpsvr.NewTransport(tracer, zipkinhttpsvr.RoundTripper(rt), zipkinhttpsvr.TransportTrace(true))
+		return roundTrip
+	})
+	defer pool.Close()
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return pool.Get("backend", instances).RoundTrip(req)
+	})
+	proxy.BufferPool = NewBufferPool()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			serveOnce(b, proxy)
+		}
+	})
+}
+
+func serveOnce(b *testing.B, proxy *httputil.ReverseProxy) {
+	req := httptest.NewRequest(http.MethodGet, "/sec/list", nil)
+	rec := httptest.NewRecorder()
+	proxy.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		b.Fatalf("unexpected status %d", rec.Code)
+	}
+	ioutil.ReadAll(rec.Body)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,59 @@
+package route
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lixichongAAA/seckill/pkg/loadbalance"
+)
+
+type balancerKey struct {
+	service string
+	balance string
+}
+
+// balancerPool 按服务和负载均衡策略保存负载均衡器
+// 加权轮询、最少连接和一致性哈希的状态都是针对一个服务的实例列表计算的, 不能在服务之间共用
+type balancerPool struct {
+	lock       sync.Mutex
+	balancers  map[balancerKey]loadbalance.LoadBalance
+	hashWarmUp time.Duration //一致性哈希新实例的预热时长
+}
+
+func newBalancerPool() *balancerPool {
+	return &balancerPool{
+		balancers:  make(map[balancerKey]loadbalance.LoadBalance),
+		hashWarmUp: loadbalance.DefaultHashWarmUp,
+	}
+}
+
+// Get 返回服务使用的负载均衡器, 不存在时按策略创建; 策略名称已在加载路由表时校验过
+func (pool *balancerPool) Get(service, balance string) loadbalance.LoadBalance {
+	key := balancerKey{service: service, balance: balance}
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+	if balancer, ok := pool.balancers[key]; ok {
+		return balancer
+	}
+	balancer, err := loadbalance.New(balance)
+	if err != nil {
+		balancer = &loadbalance.RandomLoadBalance{}
+	}
+	if hash, ok := balancer.(*loadbalance.ConsistentHashLoadBalance); ok {
+		hash.SetWarmUp(pool.hashWarmUp)
+	}
+	pool.balancers[key] = balancer
+	return balancer
+}
+
+// SetHashWarmUp 修改已有及之后创建的一致性哈希负载均衡器的预热时长
+func (pool *balancerPool) SetHashWarmUp(warmUp time.Duration) {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+	pool.hashWarmUp = warmUp
+	for _, balancer := range pool.balancers {
+		if hash, ok := balancer.(*loadbalance.ConsistentHashLoadBalance); ok {
+			hash.SetWarmUp(warmUp)
+		}
+	}
+}
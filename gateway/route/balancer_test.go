@@ -0,0 +1,68 @@
+package route
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/lixichongAAA/seckill/pkg/common"
+	"github.com/lixichongAAA/seckill/pkg/loadbalance"
+)
+
+func TestBalancerPoolInterleavedServices(t *testing.T) {
+	pool := newBalancerPool()
+	app := []*common.ServiceInstance{
+		{Host: "10.0.0.1", Port: 9030, Weight: 3},
+		{Host: "10.0.0.2", Port: 9030, Weight: 2},
+		{Host: "10.0.0.3", Port: 9030, Weight: 1},
+	}
+	admin := []*common.ServiceInstance{
+		{Host: "10.0.1.1", Port: 9040, Weight: 1},
+		{Host: "10.0.1.2", Port: 9040, Weight: 1},
+	}
+
+	expectedApp := []int{0, 1, 0, 2, 1, 0}
+	expectedAdmin := []int{0, 1, 0, 1, 0, 1}
+	for i := range expectedApp {
+		got, _ := pool.Get("sk-app", loadbalance.WeightRoundRobin).SelectService(app)
+		if got != app[expectedApp[i]] {
+			t.Fatalf("sk-app request %d: got %s, want %s", i, got.Host, app[expectedApp[i]].Host)
+		}
+		got, _ = pool.Get("sk-admin", loadbalance.WeightRoundRobin).SelectService(admin)
+		if got != admin[expectedAdmin[i]] {
+			t.Fatalf("sk-admin request %d: got %s, want %s", i, got.Host, admin[expectedAdmin[i]].Host)
+		}
+	}
+}
+
+func TestBalancerPoolGet(t *testing.T) {
+	pool := newBalancerPool()
+	if pool.Get("sk-app", loadbalance.LeastConn) != pool.Get("sk-app", loadbalance.LeastConn) {
+		t.Error("same service and strategy should share a balancer")
+	}
+	if pool.Get("sk-app", loadbalance.LeastConn) == pool.Get("sk-admin", loadbalance.LeastConn) {
+		t.Error("services should not share a balancer")
+	}
+	if pool.Get("sk-app", loadbalance.LeastConn) == pool.Get("sk-app", loadbalance.Random) {
+		t.Error("strategies should not share a balancer")
+	}
+
+	// 预热时长对已创建的和之后创建的一致性哈希负载均衡器都生效
+	hash := pool.Get("sk-app", loadbalance.ConsistentHash)
+	pool.SetHashWarmUp(0)
+	if pool.hashWarmUp != 0 {
+		t.Error("warm-up should be updated")
+	}
+	instances := []*common.ServiceInstance{{Host: "10.0.0.1", Port: 9030, Weight: 1}}
+	if _, err := hash.(loadbalance.KeyedLoadBalance).SelectServiceByKey(instances, "1"); err != nil {
+		t.Fatal(err)
+	}
+	newInstance := []*common.ServiceInstance{instances[0], {Host: "10.0.0.2", Port: 9030, Weight: 1}}
+	picked := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		got, _ := pool.Get("sk-admin", loadbalance.ConsistentHash).(loadbalance.KeyedLoadBalance).SelectServiceByKey(newInstance, strconv.Itoa(i))
+		picked[got.Host] = true
+	}
+	if !picked["10.0.0.2"] {
+		t.Error("new instance should get traffic without warm-up")
+	}
+}
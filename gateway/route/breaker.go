@@ -0,0 +1,57 @@
+package route
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/afex/hystrix-go/hystrix"
+)
+
+// BreakerState 断路器的当前状态及生效的配置
+type BreakerState struct {
+	Name                   string `json:"name"`
+	Open                   bool   `json:"open"`
+	Timeout                int64  `json:"timeout"` //毫秒
+	MaxConcurrentRequests  int    `json:"max_concurrent_requests"`
+	ErrorPercentThreshold  int    `json:"error_percent_threshold"`
+	RequestVolumeThreshold uint64 `json:"request_volume_threshold"`
+	SleepWindow            int64  `json:"sleep_window"` //毫秒
+}
+
+// BreakerStates 返回网关已配置的所有断路器的状态, 按名称排序
+func (router HystrixRouter) BreakerStates() []*BreakerState {
+	settings := hystrix.GetCircuitSettings()
+	states := make([]*BreakerState, 0)
+	router.svcMap.Range(func(key, value interface{}) bool {
+		name := key.(string)
+		state := &BreakerState{
+			Name: name,
+		}
+		if circuit, _, err := hystrix.GetCircuit(name); err == nil {
+			state.Open = circuit.IsOpen()
+		}
+		if setting, ok := settings[name]; ok {
+			state.Timeout = int64(setting.Timeout / time.Millisecond)
+			state.MaxConcurrentRequests = setting.MaxConcurrentRequests
+			state.ErrorPercentThreshold = setting.ErrorPercentThreshold
+			state.RequestVolumeThreshold = setting.RequestVolumeThreshold
+			state.SleepWindow = int64(setting.SleepWindow / time.Millisecond)
+		}
+		states = append(states, state)
+		return true
+	})
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Name < states[j].Name
+	})
+	return states
+}
+
+// BreakerHandler 以 JSON 返回断路器状态, 挂载在网关的监控端口上, 不对外暴露
+func (router HystrixRouter) BreakerHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json;charset=utf-8")
+		json.NewEncoder(w).Encode(router.BreakerStates())
+	})
+}
@@ -0,0 +1,219 @@
+package route
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxCacheEntries     = 10000    //最多缓存的响应数
+	maxCacheBodySize    = 1 << 20  //超过该大小的响应不缓存
+	maxCacheRequestBody = 64 << 10 //请求体超过该大小时不缓存
+)
+
+var errCacheFetchFailed = errors.New("fetch response failed")
+
+// cachedResponse 缓存的后端响应
+type cachedResponse struct {
+	status   int
+	header   http.Header
+	body     []byte
+	storedAt time.Time
+	expireAt time.Time
+}
+
+// ResponseCache 网关的响应缓存, 对同一 key 的并发未命中请求只转发一次, 其余请求等待并共享结果,
+// 避免缓存过期瞬间大量请求同时到达后端
+type ResponseCache struct {
+	lock    sync.Mutex
+	entries map[string]*cachedResponse
+	calls   map[string]*cacheCall
+}
+
+type cacheCall struct {
+	wg   sync.WaitGroup
+	resp *cachedResponse
+	err  error
+}
+
+func NewResponseCache() *ResponseCache {
+	return &ResponseCache{
+		entries: make(map[string]*cachedResponse),
+		calls:   make(map[string]*cacheCall),
+	}
+}
+
+// Get 返回未过期的缓存
+func (c *ResponseCache) Get(key string, now time.Time) (*cachedResponse, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	resp, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !now.Before(resp.expireAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return resp, true
+}
+
+// Do 执行 fetch 获取响应, 同一 key 同时只有一个 fetch 在执行; shared 表示结果来自其他请求的 fetch
+// fetch 返回的响应 expireAt 晚于当前时间时写入缓存
+func (c *ResponseCache) Do(key string, fetch func() (*cachedResponse, error)) (resp *cachedResponse, shared bool, err error) {
+	c.lock.Lock()
+	if call, ok := c.calls[key]; ok {
+		c.lock.Unlock()
+		call.wg.Wait()
+		return call.resp, true, call.err
+	}
+	call := &cacheCall{}
+	call.wg.Add(1)
+	c.calls[key] = call
+	c.lock.Unlock()
+
+	// fetch 发生 panic 时也要释放等待的请求
+	defer func() {
+		if call.resp == nil && call.err == nil {
+			call.err = errCacheFetchFailed
+		}
+		c.lock.Lock()
+		delete(c.calls, key)
+		if call.err == nil && call.resp.expireAt.After(time.Now()) {
+			c.store(key, call.resp)
+		}
+		c.lock.Unlock()
+		call.wg.Done()
+	}()
+
+	call.resp, call.err = fetch()
+	return call.resp, false, call.err
+}
+
+// store 写入缓存, 调用方需持有锁
+func (c *ResponseCache) store(key string, resp *cachedResponse) {
+	if len(c.entries) >= maxCacheEntries {
+		// 先清理过期的缓存, 仍然超出上限时整体清空
+		now := time.Now()
+		for k, v := range c.entries {
+			if !now.Before(v.expireAt) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxCacheEntries {
+			c.entries = make(map[string]*cachedResponse)
+		}
+	}
+	c.entries[key] = resp
+}
+
+// detachedContext 保留请求上下文中的值(追踪信息等), 但不随请求取消;
+// 多个请求共享的转发不能因为发起转发的客户端断开而失败
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+// cacheKey 以请求方法、路由、转发路径和查询参数作为缓存 key, 非 GET/HEAD 请求还包含请求体的摘要
+// 读取后会恢复请求体; 请求体过大时返回 false, 不使用缓存
+func cacheKey(r *http.Request, target *Target) (string, bool) {
+	key := r.Method + " " + target.Name + " " + target.Path + "?" + r.URL.RawQuery
+	if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Body == nil {
+		return key, true
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxCacheRequestBody+1))
+	r.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+	if err != nil || len(body) > maxCacheRequestBody {
+		return "", false
+	}
+	sum := sha1.Sum(body)
+	return key + " " + hex.EncodeToString(sum[:]), true
+}
+
+// responseBuffer 在内存中记录后端响应, 用于写入缓存后再返回给各个请求
+type responseBuffer struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseBuffer() *responseBuffer {
+	return &responseBuffer{
+		header: make(http.Header),
+		status: http.StatusOK,
+	}
+}
+
+func (b *responseBuffer) Header() http.Header {
+	return b.header
+}
+
+func (b *responseBuffer) Write(data []byte) (int, error) {
+	return b.body.Write(data)
+}
+
+func (b *responseBuffer) WriteHeader(status int) {
+	b.status = status
+}
+
+// toCachedResponse 根据后端响应的状态码和 Cache-Control 计算缓存的过期时间, 不可缓存时过期时间即为当前时间
+func (b *responseBuffer) toCachedResponse(ttl time.Duration, now time.Time) *cachedResponse {
+	resp := &cachedResponse{
+		status:   b.status,
+		header:   b.header,
+		body:     b.body.Bytes(),
+		storedAt: now,
+		expireAt: now,
+	}
+	if b.status != http.StatusOK || len(resp.body) > maxCacheBodySize {
+		return resp
+	}
+
+	for _, directive := range strings.Split(b.header.Get("Cache-Control"), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-store" || directive == "no-cache" || directive == "private":
+			return resp
+		case strings.HasPrefix(directive, "max-age="):
+			if seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age=")); err == nil && time.Duration(seconds)*time.Second < ttl {
+				ttl = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	resp.expireAt = now.Add(ttl)
+	return resp
+}
+
+// writeCachedResponse 将响应写给客户端, X-Cache 标明是否命中缓存, Age 为响应已缓存的秒数
+// 后端未设置 Cache-Control 时以缓存的剩余时间作为 max-age
+func writeCachedResponse(w http.ResponseWriter, resp *cachedResponse, hit bool, now time.Time) {
+	header := w.Header()
+	for k, v := range resp.header {
+		header[k] = v
+	}
+	if hit {
+		header.Set("X-Cache", "HIT")
+		header.Set("Age", strconv.Itoa(int(now.Sub(resp.storedAt)/time.Second)))
+	} else {
+		header.Set("X-Cache", "MISS")
+	}
+	if header.Get("Cache-Control") == "" && resp.expireAt.After(now) {
+		header.Set("Cache-Control", "max-age="+strconv.Itoa(int((resp.expireAt.Sub(now)+time.Second-1)/time.Second)))
+	}
+	w.WriteHeader(resp.status)
+	w.Write(resp.body)
+}
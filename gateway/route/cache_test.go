@@ -0,0 +1,86 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestResponseCacheDo(t *testing.T) {
+	cache := NewResponseCache()
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	results := make(chan error, 3)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer func() {
+			if recover() == nil {
+				results <- errors.New("panic should reach the fetching request")
+			}
+		}()
+		cache.Do("key", func() (*cachedResponse, error) {
+			close(started)
+			<-release
+			panic("fetch failed")
+		})
+	}()
+	<-started
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, shared, err := cache.Do("key", func() (*cachedResponse, error) {
+				return nil, errors.New("waiting request should not fetch")
+			})
+			if !shared || err != errCacheFetchFailed {
+				results <- errors.New("waiting request should get the failed fetch")
+			}
+		}()
+	}
+	// 等待请求进入 Do 后再让 fetch panic
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("waiting requests were not released after panic")
+	}
+	close(results)
+	for err := range results {
+		t.Error(err)
+	}
+
+	resp, shared, err := cache.Do("key", func() (*cachedResponse, error) {
+		return &cachedResponse{status: 200, expireAt: time.Now().Add(time.Minute)}, nil
+	})
+	if err != nil || shared || resp.status != 200 {
+		t.Fatalf("fetch after panic failed: %v", err)
+	}
+	if _, ok := cache.Get("key", time.Now()); !ok {
+		t.Error("response should be cached")
+	}
+}
+
+func TestDetachedContext(t *testing.T) {
+	type key struct{}
+	parent, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "span"))
+	ctx := detachedContext{parent}
+	cancel()
+	if ctx.Err() != nil || ctx.Done() != nil {
+		t.Error("detached context should not be cancelled with its parent")
+	}
+	if ctx.Value(key{}) != "span" {
+		t.Error("detached context should keep the parent values")
+	}
+}
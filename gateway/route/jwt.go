@@ -0,0 +1,157 @@
+package route
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// 网关校验令牌后转发给后端服务的用户信息请求头, 客户端携带的同名请求头会被清除
+const (
+	HeaderUserId          = "X-User-Id"
+	HeaderUserName        = "X-User-Name"
+	HeaderUserAuthorities = "X-User-Authorities" //以逗号分隔
+)
+
+const maxTokenCacheSize = 10000 //令牌校验结果的最大缓存数
+
+var (
+	ErrTokenRequired = errors.New("token is required")
+	ErrInvalidToken  = errors.New("invalid token")
+)
+
+// Identity 令牌绑定的用户信息
+type Identity struct {
+	UserId      int64
+	Username    string
+	Authorities []string
+	ClientId    string //令牌所属的客户端
+}
+
+// tokenClaims 与 oauth-service 中 JwtTokenEnhancer 签发的令牌声明一致, 只保留网关需要的字段
+type tokenClaims struct {
+	UserDetails struct {
+		UserId      int64
+		Username    string
+		Authorities []string
+	}
+	ClientDetails struct {
+		ClientId string
+	}
+	jwt.StandardClaims
+}
+
+// TokenVerifier 使用与 oauth-service 共享的密钥在本地校验 JWT 令牌的签名和有效期,
+// 避免每个请求都通过 gRPC 调用 CheckToken; 校验结果缓存 cacheTTL 时长, 且不超过令牌的有效期
+type TokenVerifier struct {
+	secretKey []byte
+	cacheTTL  time.Duration
+	lock      sync.Mutex
+	cache     map[string]*cachedIdentity
+}
+
+type cachedIdentity struct {
+	identity *Identity
+	expireAt time.Time
+}
+
+func NewTokenVerifier(secretKey string, cacheTTL time.Duration) *TokenVerifier {
+	return &TokenVerifier{
+		secretKey: []byte(secretKey),
+		cacheTTL:  cacheTTL,
+		cache:     make(map[string]*cachedIdentity),
+	}
+}
+
+// Verify 校验 Authorization 请求头中的令牌, 返回令牌绑定的用户信息
+func (v *TokenVerifier) Verify(authorization string) (*Identity, error) {
+	tokenValue := strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer "))
+	if tokenValue == "" {
+		return nil, ErrTokenRequired
+	}
+
+	now := time.Now()
+	if identity, ok := v.load(tokenValue, now); ok {
+		return identity, nil
+	}
+
+	claims := &tokenClaims{}
+	token, err := jwt.ParseWithClaims(tokenValue, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidToken
+		}
+		return v.secretKey, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	identity := &Identity{
+		UserId:      claims.UserDetails.UserId,
+		Username:    claims.UserDetails.Username,
+		Authorities: claims.UserDetails.Authorities,
+		ClientId:    claims.ClientDetails.ClientId,
+	}
+	expireAt := now.Add(v.cacheTTL)
+	if tokenExpire := time.Unix(claims.ExpiresAt, 0); claims.ExpiresAt > 0 && tokenExpire.Before(expireAt) {
+		expireAt = tokenExpire
+	}
+	v.store(tokenValue, identity, expireAt, now)
+	return identity, nil
+}
+
+func (v *TokenVerifier) load(tokenValue string, now time.Time) (*Identity, bool) {
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	cached, ok := v.cache[tokenValue]
+	if !ok {
+		return nil, false
+	}
+	if !now.Before(cached.expireAt) {
+		delete(v.cache, tokenValue)
+		return nil, false
+	}
+	return cached.identity, true
+}
+
+func (v *TokenVerifier) store(tokenValue string, identity *Identity, expireAt time.Time, now time.Time) {
+	if v.cacheTTL <= 0 {
+		return
+	}
+	v.lock.Lock()
+	defer v.lock.Unlock()
+	if len(v.cache) >= maxTokenCacheSize {
+		// 先清理过期的缓存, 仍然超出上限时整体清空
+		for key, cached := range v.cache {
+			if !now.Before(cached.expireAt) {
+				delete(v.cache, key)
+			}
+		}
+		if len(v.cache) >= maxTokenCacheSize {
+			v.cache = make(map[string]*cachedIdentity)
+		}
+	}
+	v.cache[tokenValue] = &cachedIdentity{
+		identity: identity,
+		expireAt: expireAt,
+	}
+}
+
+// removeIdentityHeaders 清除客户端伪造的用户信息请求头
+func removeIdentityHeaders(r *http.Request) {
+	r.Header.Del(HeaderUserId)
+	r.Header.Del(HeaderUserName)
+	r.Header.Del(HeaderUserAuthorities)
+}
+
+// setIdentityHeaders 将令牌绑定的用户信息写入请求头转发给后端服务
+func setIdentityHeaders(r *http.Request, identity *Identity) {
+	r.Header.Set(HeaderUserId, strconv.FormatInt(identity.UserId, 10))
+	r.Header.Set(HeaderUserName, identity.Username)
+	r.Header.Set(HeaderUserAuthorities, strings.Join(identity.Authorities, ","))
+}
@@ -0,0 +1,141 @@
+package route
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const testSecret = "secret"
+
+func signToken(t *testing.T, method jwt.SigningMethod, key interface{}, expiresAt int64) string {
+	claims := tokenClaims{}
+	claims.UserDetails.UserId = 7
+	claims.UserDetails.Username = "alice"
+	claims.UserDetails.Authorities = []string{"Simple", "Admin"}
+	claims.ClientDetails.ClientId = "app"
+	claims.ExpiresAt = expiresAt
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestTokenVerifierVerify(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour).Unix()
+	valid := signToken(t, jwt.SigningMethodHS256, []byte(testSecret), expiresAt)
+	tests := []struct {
+		name          string
+		authorization string
+		err           error
+	}{
+		{"bearer", "Bearer " + valid, nil},
+		{"without bearer prefix", valid, nil},
+		{"hs512", "Bearer " + signToken(t, jwt.SigningMethodHS512, []byte(testSecret), expiresAt), nil},
+		{"no expiry", "Bearer " + signToken(t, jwt.SigningMethodHS256, []byte(testSecret), 0), nil},
+		{"missing", "", ErrTokenRequired},
+		{"bearer only", "Bearer ", ErrTokenRequired},
+		{"bad signature", "Bearer " + signToken(t, jwt.SigningMethodHS256, []byte("other"), expiresAt), ErrInvalidToken},
+		{"expired", "Bearer " + signToken(t, jwt.SigningMethodHS256, []byte(testSecret), time.Now().Add(-time.Minute).Unix()), ErrInvalidToken},
+		{"unsigned", "Bearer " + signToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, expiresAt), ErrInvalidToken},
+		{"malformed", "Bearer abc.def", ErrInvalidToken},
+	}
+	for _, test := range tests {
+		identity, err := NewTokenVerifier(testSecret, time.Minute).Verify(test.authorization)
+		if err != test.err {
+			t.Errorf("%s: got err %v, want %v", test.name, err, test.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if identity.UserId != 7 || identity.Username != "alice" || identity.ClientId != "app" || len(identity.Authorities) != 2 {
+			t.Errorf("%s: got identity %+v", test.name, identity)
+		}
+	}
+}
+
+func TestTokenVerifierCache(t *testing.T) {
+	now := time.Now()
+	shortLived := signToken(t, jwt.SigningMethodHS256, []byte(testSecret), now.Add(30*time.Second).Unix())
+	longLived := signToken(t, jwt.SigningMethodHS256, []byte(testSecret), now.Add(time.Hour).Unix())
+	tests := []struct {
+		name     string
+		cacheTTL time.Duration
+		token    string
+		cached   bool
+		expireAt time.Time //缓存的最晚到期时间
+	}{
+		{"cache ttl", time.Minute, longLived, true, now.Add(time.Minute + time.Second)},
+		{"token expiry", time.Minute, shortLived, true, now.Add(30 * time.Second)},
+		{"no cache", 0, longLived, false, time.Time{}},
+	}
+	for _, test := range tests {
+		verifier := NewTokenVerifier(testSecret, test.cacheTTL)
+		first, err := verifier.Verify("Bearer " + test.token)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		cached, ok := verifier.cache[test.token]
+		if ok != test.cached {
+			t.Errorf("%s: cached %v, want %v", test.name, ok, test.cached)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if cached.expireAt.After(test.expireAt) {
+			t.Errorf("%s: cached until %v, want no later than %v", test.name, cached.expireAt, test.expireAt)
+		}
+		if second, _ := verifier.Verify(test.token); second != first {
+			t.Errorf("%s: second verify should hit the cache", test.name)
+		}
+		// 到期后重新校验
+		if _, ok := verifier.load(test.token, cached.expireAt); ok || verifier.cache[test.token] != nil {
+			t.Errorf("%s: expired entry should be removed", test.name)
+		}
+	}
+}
+
+func TestTokenVerifierCacheLimit(t *testing.T) {
+	verifier := NewTokenVerifier(testSecret, time.Minute)
+	now := time.Now()
+	for i := 0; i < maxTokenCacheSize; i++ {
+		// 一半的缓存已过期
+		expireAt := now.Add(time.Minute)
+		if i%2 == 0 {
+			expireAt = now.Add(-time.Second)
+		}
+		verifier.cache[strconv.Itoa(i)] = &cachedIdentity{identity: &Identity{}, expireAt: expireAt}
+	}
+	verifier.store("new", &Identity{}, now.Add(time.Minute), now)
+	if len(verifier.cache) != maxTokenCacheSize/2+1 || verifier.cache["new"] == nil {
+		t.Errorf("expired entries should be swept first, got %d entries", len(verifier.cache))
+	}
+
+	for i := 0; len(verifier.cache) < maxTokenCacheSize; i++ {
+		verifier.cache["live"+strconv.Itoa(i)] = &cachedIdentity{identity: &Identity{}, expireAt: now.Add(time.Minute)}
+	}
+	verifier.store("newer", &Identity{}, now.Add(time.Minute), now)
+	if len(verifier.cache) != 1 || verifier.cache["newer"] == nil {
+		t.Errorf("cache should be reset when still full, got %d entries", len(verifier.cache))
+	}
+}
+
+func TestIdentityHeaders(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set(HeaderUserId, "1")
+	r.Header.Set(HeaderUserName, "mallory")
+	removeIdentityHeaders(r)
+	if r.Header.Get(HeaderUserId) != "" || r.Header.Get(HeaderUserName) != "" {
+		t.Error("forged identity headers should be removed")
+	}
+	setIdentityHeaders(r, &Identity{UserId: 7, Username: "alice", Authorities: []string{"Simple", "Admin"}})
+	if r.Header.Get(HeaderUserId) != "7" || r.Header.Get(HeaderUserName) != "alice" || r.Header.Get(HeaderUserAuthorities) != "Simple,Admin" {
+		t.Errorf("got headers %v", r.Header)
+	}
+}
@@ -0,0 +1,131 @@
+package route
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lixichongAAA/seckill/gateway/config"
+	"github.com/lixichongAAA/seckill/pkg/common"
+)
+
+const (
+	defaultConsecutiveFailures = 5     //默认连续失败多少次后摘除实例
+	defaultEjectionTime        = 30000 //默认首次摘除时长, 单位毫秒
+	defaultMaxEjectionPercent  = 50    //默认最多摘除的实例百分比
+	maxEjectionMultiple        = 10    //摘除时长最多为首次摘除时长的倍数
+)
+
+// outlierPolicy 由异常实例摘除配置编译而成
+type outlierPolicy struct {
+	consecutiveFailures int
+	ejectionTime        time.Duration
+	maxEjectionPercent  int
+}
+
+func compileOutlier(rule config.OutlierRule) (outlierPolicy, error) {
+	if rule.ConsecutiveFailures < 0 || rule.EjectionTime < 0 {
+		return outlierPolicy{}, errors.New("outlier settings must not be negative")
+	}
+	if rule.MaxEjectionPercent < 0 || rule.MaxEjectionPercent > 100 {
+		return outlierPolicy{}, errors.New("maxEjectionPercent must be between 0 and 100")
+	}
+	return outlierPolicy{
+		consecutiveFailures: firstPositive(rule.ConsecutiveFailures, defaultConsecutiveFailures),
+		ejectionTime:        time.Duration(firstPositive(rule.EjectionTime, defaultEjectionTime)) * time.Millisecond,
+		maxEjectionPercent:  firstPositive(rule.MaxEjectionPercent, defaultMaxEjectionPercent),
+	}, nil
+}
+
+// OutlierDetector 记录各服务实例的连续失败次数, 将连续失败的实例摘除一段时间;
+// 摘除到期后实例重新参与负载均衡, 若仍然连续失败则以更长的时长再次摘除, 成功一次即清零
+type OutlierDetector struct {
+	lock   sync.Mutex
+	policy outlierPolicy
+	hosts  map[string]*hostHealth //实例地址 -> 健康状况, 只记录有失败的实例
+}
+
+type hostHealth struct {
+	failures     int       //连续失败次数
+	ejections    int       //连续被摘除的次数
+	ejectedUntil time.Time //摘除到期时间
+}
+
+func NewOutlierDetector() *OutlierDetector {
+	policy, _ := compileOutlier(config.OutlierRule{})
+	return &OutlierDetector{
+		policy: policy,
+		hosts:  make(map[string]*hostHealth),
+	}
+}
+
+// SetPolicy 路由配置重新加载后更新摘除策略, 已摘除的实例按原到期时间恢复
+func (d *OutlierDetector) SetPolicy(policy outlierPolicy) {
+	d.lock.Lock()
+	d.policy = policy
+	d.lock.Unlock()
+}
+
+// Filter 返回可用于转发的实例, 排除 exclude 中已尝试过的实例和被摘除的实例;
+// 服务被摘除的实例比例超过上限时忽略摘除, 只排除已尝试过的实例
+func (d *OutlierDetector) Filter(instances []*common.ServiceInstance, exclude map[string]bool, now time.Time) []*common.ServiceInstance {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	available := make([]*common.ServiceInstance, 0, len(instances))
+	healthy := make([]*common.ServiceInstance, 0, len(instances))
+	ejected := 0
+	for _, instance := range instances {
+		addr := instanceAddr(instance)
+		if health, ok := d.hosts[addr]; ok && now.Before(health.ejectedUntil) {
+			ejected++
+			if !exclude[addr] {
+				available = append(available, instance)
+			}
+			continue
+		}
+		if !exclude[addr] {
+			available = append(available, instance)
+			healthy = append(healthy, instance)
+		}
+	}
+	if ejected*100 > len(instances)*d.policy.maxEjectionPercent {
+		return available
+	}
+	return healthy
+}
+
+// Report 记录一次转发的结果, 连续失败达到阈值时摘除该实例
+func (d *OutlierDetector) Report(addr string, success bool, now time.Time) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	health, ok := d.hosts[addr]
+	if success {
+		if ok && !now.Before(health.ejectedUntil) {
+			delete(d.hosts, addr)
+		}
+		return
+	}
+	if !ok {
+		health = &hostHealth{}
+		d.hosts[addr] = health
+	}
+	if now.Before(health.ejectedUntil) {
+		return
+	}
+	health.failures++
+	if health.failures < d.policy.consecutiveFailures {
+		return
+	}
+	health.failures = 0
+	if health.ejections < maxEjectionMultiple {
+		health.ejections++
+	}
+	health.ejectedUntil = now.Add(d.policy.ejectionTime * time.Duration(health.ejections))
+}
+
+func instanceAddr(instance *common.ServiceInstance) string {
+	return fmt.Sprintf("%s:%d", instance.Host, instance.Port)
+}
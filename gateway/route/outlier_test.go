@@ -0,0 +1,137 @@
+package route
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lixichongAAA/seckill/gateway/config"
+	"github.com/lixichongAAA/seckill/pkg/common"
+)
+
+func TestCompileOutlier(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  config.OutlierRule
+		want  outlierPolicy
+		valid bool
+	}{
+		{"defaults", config.OutlierRule{}, outlierPolicy{defaultConsecutiveFailures, defaultEjectionTime * time.Millisecond, defaultMaxEjectionPercent}, true},
+		{"configured", config.OutlierRule{ConsecutiveFailures: 3, EjectionTime: 1000, MaxEjectionPercent: 100}, outlierPolicy{3, time.Second, 100}, true},
+		{"negative failures", config.OutlierRule{ConsecutiveFailures: -1}, outlierPolicy{}, false},
+		{"negative ejection time", config.OutlierRule{EjectionTime: -1}, outlierPolicy{}, false},
+		{"percent over 100", config.OutlierRule{MaxEjectionPercent: 101}, outlierPolicy{}, false},
+	}
+	for _, test := range tests {
+		policy, err := compileOutlier(test.rule)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: got err %v", test.name, err)
+			continue
+		}
+		if policy != test.want {
+			t.Errorf("%s: got %+v, want %+v", test.name, policy, test.want)
+		}
+	}
+}
+
+func TestOutlierDetectorEjection(t *testing.T) {
+	start := time.Now()
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+	type report struct {
+		ms      int
+		success bool
+	}
+	tests := []struct {
+		name         string
+		reports      []report
+		ejectedUntil int //摘除到期时间(毫秒), 为 0 表示未被摘除
+	}{
+		{"below threshold", []report{{0, false}, {1, false}}, 0},
+		{"consecutive failures", []report{{0, false}, {1, false}, {2, false}}, 1002},
+		{"success resets failures", []report{{0, false}, {1, false}, {2, true}, {3, false}, {4, false}}, 0},
+		{"failures while ejected are ignored", []report{{0, false}, {1, false}, {2, false}, {500, false}, {501, false}, {502, false}}, 1002},
+		{"success while ejected keeps ejection", []report{{0, false}, {1, false}, {2, false}, {500, true}, {1002, false}, {1003, false}, {1004, false}}, 3004},
+		{"success after ejection resets", []report{{0, false}, {1, false}, {2, false}, {1002, true}, {1003, false}, {1004, false}, {1005, false}}, 2005},
+	}
+	for _, test := range tests {
+		detector := NewOutlierDetector()
+		detector.SetPolicy(outlierPolicy{consecutiveFailures: 3, ejectionTime: time.Second, maxEjectionPercent: 100})
+		for _, r := range test.reports {
+			detector.Report("10.0.0.1:9030", r.success, at(r.ms))
+		}
+		var until time.Time
+		if health, ok := detector.hosts["10.0.0.1:9030"]; ok && health.ejections > 0 {
+			until = health.ejectedUntil
+		}
+		if test.ejectedUntil == 0 && !until.IsZero() || test.ejectedUntil != 0 && !until.Equal(at(test.ejectedUntil)) {
+			t.Errorf("%s: ejected until %v, want %dms", test.name, until.Sub(start), test.ejectedUntil)
+		}
+	}
+}
+
+func TestOutlierDetectorEjectionMultiple(t *testing.T) {
+	detector := NewOutlierDetector()
+	detector.SetPolicy(outlierPolicy{consecutiveFailures: 1, ejectionTime: time.Second, maxEjectionPercent: 100})
+	now := time.Now()
+	for i := 1; i <= maxEjectionMultiple+2; i++ {
+		detector.Report("a", false, now)
+		want := i
+		if want > maxEjectionMultiple {
+			want = maxEjectionMultiple
+		}
+		if got := detector.hosts["a"].ejectedUntil.Sub(now); got != time.Duration(want)*time.Second {
+			t.Fatalf("ejection %d: got %v, want %ds", i, got, want)
+		}
+		now = detector.hosts["a"].ejectedUntil
+	}
+}
+
+func TestOutlierDetectorFilter(t *testing.T) {
+	instances := []*common.ServiceInstance{
+		{Host: "10.0.0.1", Port: 9030},
+		{Host: "10.0.0.2", Port: 9030},
+		{Host: "10.0.0.3", Port: 9030},
+		{Host: "10.0.0.4", Port: 9030},
+	}
+	tests := []struct {
+		name       string
+		maxPercent int
+		ejected    []int
+		exclude    []int
+		want       []int
+	}{
+		{"none ejected", 50, nil, nil, []int{0, 1, 2, 3}},
+		{"ejected", 50, []int{0}, nil, []int{1, 2, 3}},
+		{"at max percent", 50, []int{0, 1}, nil, []int{2, 3}},
+		{"over max percent", 50, []int{0, 1, 2}, nil, []int{0, 1, 2, 3}},
+		{"over max percent with tried", 50, []int{0, 1, 2}, []int{1, 3}, []int{0, 2}},
+		{"tried", 50, []int{0}, []int{1}, []int{2, 3}},
+		{"all ejected allowed", 100, []int{0, 1, 2, 3}, nil, []int{}},
+		{"no ejection allowed", 0, []int{0}, nil, []int{0, 1, 2, 3}},
+	}
+	now := time.Now()
+	for _, test := range tests {
+		detector := NewOutlierDetector()
+		detector.SetPolicy(outlierPolicy{consecutiveFailures: 1, ejectionTime: time.Second, maxEjectionPercent: test.maxPercent})
+		for _, i := range test.ejected {
+			detector.Report(instanceAddr(instances[i]), false, now)
+		}
+		exclude := make(map[string]bool)
+		for _, i := range test.exclude {
+			exclude[instanceAddr(instances[i])] = true
+		}
+		got := detector.Filter(instances, exclude, now)
+		if len(got) != len(test.want) {
+			t.Errorf("%s: got %d instances, want %v", test.name, len(got), test.want)
+			continue
+		}
+		for j, i := range test.want {
+			if got[j] != instances[i] {
+				t.Errorf("%s: got %s at %d, want %s", test.name, got[j].Host, j, instances[i].Host)
+			}
+		}
+		// 摘除到期后实例恢复
+		if recovered := detector.Filter(instances, nil, now.Add(time.Second)); len(recovered) != len(instances) {
+			t.Errorf("%s: got %d instances after ejection expired", test.name, len(recovered))
+		}
+	}
+}
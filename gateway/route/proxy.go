@@ -0,0 +1,261 @@
+package route
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"time"
+
+	"github.com/lixichongAAA/seckill/gateway/config"
+	"github.com/lixichongAAA/seckill/gateway/proxy"
+	"github.com/lixichongAAA/seckill/pkg/common"
+	"github.com/lixichongAAA/seckill/pkg/discover"
+	"github.com/lixichongAAA/seckill/pkg/loadbalance"
+)
+
+const maxRetryRequestBody = 64 << 10 //请求体超过该大小时不重试
+
+// idempotentMethods 可以安全重试的请求方法
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodOptions: true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+}
+
+// proxyAttemptKey 请求 context 中保存本次转发的 key
+type proxyAttemptKey struct{}
+
+// proxyAttempt 一次转发的目标及结果, 通过请求的 context 传递给网关共用的反向代理
+type proxyAttempt struct {
+	target    *Target
+	addr      string
+	transport http.RoundTripper
+	status    int
+	err       error
+}
+
+func attemptFrom(r *http.Request) *proxyAttempt {
+	return r.Context().Value(proxyAttemptKey{}).(*proxyAttempt)
+}
+
+// newReverseProxy 创建网关共用的反向代理, 转发地址和使用的连接池由每次转发的 proxyAttempt 决定
+func newReverseProxy() *httputil.ReverseProxy {
+	return &httputil.ReverseProxy{
+		// 设置代理服务地址信息, 转发路径由路由表决定
+		Director: func(req *http.Request) {
+			attempt := attemptFrom(req)
+			req.URL.Scheme = "http"
+			req.URL.Host = attempt.addr
+			req.URL.Path = attempt.target.Path
+			req.URL.RawPath = ""
+		},
+		Transport: attemptTransport{},
+		ModifyResponse: func(resp *http.Response) error {
+			attemptFrom(resp.Request).status = resp.StatusCode
+			return nil
+		},
+		//反向代理失败时错误处理
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			attemptFrom(r).err = err
+		},
+		BufferPool: proxy.NewBufferPool(),
+	}
+}
+
+// attemptTransport 使用本次转发所属服务的连接池发送请求
+type attemptTransport struct{}
+
+func (attemptTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return attemptFrom(req).transport.RoundTrip(req)
+}
+
+// transportOptions 合并连接池配置与默认值
+func transportOptions(rule config.TransportRule) (proxy.TransportOptions, error) {
+	if rule.MaxIdleConns < 0 || rule.MaxIdleConnsPerHost < 0 || rule.MaxConnsPerHost < 0 ||
+		rule.IdleConnTimeout < 0 || rule.DialTimeout < 0 || rule.ResponseHeaderTimeout < 0 {
+		return proxy.TransportOptions{}, errors.New("transport settings must not be negative")
+	}
+	options := proxy.DefaultTransportOptions()
+	options.MaxIdleConns = firstPositive(rule.MaxIdleConns, options.MaxIdleConns)
+	options.MaxIdleConnsPerHost = firstPositive(rule.MaxIdleConnsPerHost, options.MaxIdleConnsPerHost)
+	options.MaxConnsPerHost = rule.MaxConnsPerHost
+	if rule.IdleConnTimeout > 0 {
+		options.IdleConnTimeout = time.Duration(rule.IdleConnTimeout) * time.Millisecond
+	}
+	if rule.DialTimeout > 0 {
+		options.DialTimeout = time.Duration(rule.DialTimeout) * time.Millisecond
+	}
+	options.ResponseHeaderTimeout = time.Duration(rule.ResponseHeaderTimeout) * time.Millisecond
+	return options, nil
+}
+
+// selectInstance 查询服务实例, 排除已尝试过的和被摘除的实例后按负载均衡策略选择一个, 同时返回该服务的连接池
+// 请求 context 中的 hash key 供一致性哈希策略使用, 选中的实例需在转发结束后通过 loadbalance.Done 释放
+func (router HystrixRouter) selectInstance(r *http.Request, target *Target, tried map[string]bool) (*common.ServiceInstance, http.RoundTripper, error) {
+	discoveryClient, err := discover.Client()
+	if err != nil {
+		return nil, nil, discover.NoInstanceExistedErr
+	}
+	instances := discoveryClient.DiscoverServices(target.Service, discover.Logger)
+	transport := router.transports.Get(target.Service, instances)
+	instances = router.outliers.Filter(instances, tried, time.Now())
+	if len(instances) == 0 {
+		return nil, nil, discover.NoInstanceExistedErr
+	}
+	instance, err := loadbalance.Select(r.Context(), router.balancers.Get(target.Service, target.Balance), instances)
+	return instance, transport, err
+}
+
+// proxyTo 将请求转发到指定实例, 并将结果计入实例的健康状况:
+// 连接失败、超时及 502/503/504 响应视为失败, 被主动取消的请求不计入
+func (router HystrixRouter) proxyTo(w http.ResponseWriter, r *http.Request, target *Target, instance *common.ServiceInstance, transport http.RoundTripper) error {
+	attempt := &proxyAttempt{
+		target:    target,
+		addr:      instanceAddr(instance),
+		transport: transport,
+	}
+	router.logger.Log("route", target.Name, "service id", instance.Host, instance.Port)
+	defer loadbalance.Done(router.balancers.Get(target.Service, target.Balance), instance)
+	router.proxy.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), proxyAttemptKey{}, attempt)))
+
+	if attempt.err != nil && r.Context().Err() == context.Canceled {
+		return attempt.err
+	}
+	failed := attempt.err != nil || attempt.status == http.StatusBadGateway ||
+		attempt.status == http.StatusServiceUnavailable || attempt.status == http.StatusGatewayTimeout
+	router.outliers.Report(attempt.addr, !failed, time.Now())
+	return attempt.err
+}
+
+// proxyWithRetry 转发请求, 连接实例失败时换一个未尝试过的实例重试
+// 只重试幂等请求, 请求体会先读入内存以便重发, 请求体过大时不重试
+func (router HystrixRouter) proxyWithRetry(w http.ResponseWriter, r *http.Request, target *Target) error {
+	attempts := 1
+	var body []byte
+	if target.Retries > 0 && idempotentMethods[r.Method] {
+		var ok bool
+		if body, ok = bufferRequestBody(r); ok {
+			attempts += target.Retries
+		}
+	}
+
+	tried := make(map[string]bool)
+	var err error
+	for i := 0; i < attempts; i++ {
+		instance, transport, selectErr := router.selectInstance(r, target, tried)
+		if selectErr != nil {
+			if err == nil {
+				err = selectErr
+			}
+			return err
+		}
+		tried[instanceAddr(instance)] = true
+		if i > 0 && body != nil {
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+		if err = router.proxyTo(w, r, target, instance, transport); err == nil || !isConnectError(err) {
+			return err
+		}
+		router.logger.Log("route", target.Name, "connect instance failed", err)
+	}
+	return err
+}
+
+// proxyWithHedge 转发 GET 请求, 超过 target.Hedge 仍未返回时向另一个实例发出对冲请求,
+// 采用先返回的响应并取消另一个请求; 首个请求连接失败时立即发出对冲请求
+// 为保证只有一个响应写给客户端, 响应先写入内存, 不适用于大响应或流式响应
+func (router HystrixRouter) proxyWithHedge(w http.ResponseWriter, r *http.Request, target *Target) error {
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	type result struct {
+		buffer *responseBuffer
+		err    error
+	}
+	results := make(chan result, 2)
+	tried := make(map[string]bool)
+	launch := func() error {
+		instance, transport, err := router.selectInstance(r, target, tried)
+		if err != nil {
+			return err
+		}
+		tried[instanceAddr(instance)] = true
+		go func() {
+			buffer := newResponseBuffer()
+			err := router.proxyTo(buffer, r.WithContext(ctx), target, instance, transport)
+			results <- result{buffer, err}
+		}()
+		return nil
+	}
+
+	if err := launch(); err != nil {
+		return err
+	}
+	pending, hedged := 1, false
+	timer := time.NewTimer(target.Hedge)
+	defer timer.Stop()
+
+	var err error
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if !hedged {
+				hedged = true
+				if launch() == nil {
+					pending++
+				}
+			}
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				res.buffer.writeTo(w)
+				return nil
+			}
+			err = res.err
+			if !hedged && isConnectError(res.err) {
+				hedged = true
+				if launch() == nil {
+					pending++
+				}
+			}
+		}
+	}
+	return err
+}
+
+// bufferRequestBody 将请求体读入内存并替换为可重复读取的副本, 请求体过大时恢复原请求体并返回 false
+func bufferRequestBody(r *http.Request) ([]byte, bool) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, true
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxRetryRequestBody+1))
+	if err != nil || len(body) > maxRetryRequestBody {
+		r.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+		return nil, false
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, true
+}
+
+// isConnectError 判断是否为连接实例失败, 此时请求尚未发送到后端, 可以安全地换实例重试
+func isConnectError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// writeTo 将记录的响应写给客户端
+func (b *responseBuffer) writeTo(w http.ResponseWriter) {
+	header := w.Header()
+	for k, v := range b.header {
+		header[k] = v
+	}
+	w.WriteHeader(b.status)
+	w.Write(b.body.Bytes())
+}
@@ -0,0 +1,131 @@
+package route
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/lixichongAAA/seckill/gateway/proxy"
+	"github.com/lixichongAAA/seckill/pkg/discover"
+	"github.com/lixichongAAA/seckill/pkg/loadbalance"
+)
+
+func newTestRouter() HystrixRouter {
+	return HystrixRouter{
+		svcMap:    &sync.Map{},
+		logger:    log.NewNopLogger(),
+		balancers: newBalancerPool(),
+		outliers:  NewOutlierDetector(),
+		transports: proxy.NewTransportPool(proxy.DefaultTransportOptions(), func(rt http.RoundTripper) http.RoundTripper {
+			return rt
+		}),
+		proxy: newReverseProxy(),
+	}
+}
+
+// newBackend 启动一个延迟 delay 后返回 body 的实例, closed 为 true 时实例已关闭, 连接会被拒绝
+func newBackend(t *testing.T, body string, delay time.Duration, closed bool) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	if closed {
+		server.Close()
+	} else {
+		t.Cleanup(server.Close)
+	}
+	return server, &requests
+}
+
+func registerBackends(t *testing.T, registry *discover.MemoryDiscoveryClient, service string, servers ...*httptest.Server) {
+	for i, server := range servers {
+		host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+		if !registry.Register(service+strconv.Itoa(i), host, "", port, service, 1, nil, nil, nil) {
+			t.Fatal("register instance failed")
+		}
+	}
+}
+
+func TestProxyWithHedge(t *testing.T) {
+	registry := discover.NewMemoryDiscoveryClient()
+	discover.SetClient(registry)
+
+	tests := []struct {
+		name     string
+		first    string //首先选中的实例的响应, closed 表示实例已关闭
+		firstDly time.Duration
+		second   string
+		hedge    time.Duration
+		want     string //为空表示转发失败
+		maxTime  time.Duration
+	}{
+		{"first returns before hedge", "first", 0, "second", 500 * time.Millisecond, "first", 400 * time.Millisecond},
+		{"hedge after delay", "first", 2 * time.Second, "second", 50 * time.Millisecond, "second", time.Second},
+		{"hedge on connect error", "closed", 0, "second", 2 * time.Second, "second", time.Second},
+		{"all instances down", "closed", 0, "closed", 50 * time.Millisecond, "", time.Second},
+	}
+	for i, test := range tests {
+		service := "hedge-" + strconv.Itoa(i)
+		first, firstRequests := newBackend(t, test.first, test.firstDly, test.first == "closed")
+		second, secondRequests := newBackend(t, test.second, 0, test.second == "closed")
+		// 权重相同时加权轮询先选择第一个实例
+		registerBackends(t, registry, service, first, second)
+
+		router := newTestRouter()
+		target := &Target{Name: service, Service: service, Path: "/", Hedge: test.hedge, Balance: loadbalance.WeightRoundRobin}
+		w := httptest.NewRecorder()
+		start := time.Now()
+		err := router.proxyWithHedge(w, httptest.NewRequest("GET", "/", nil).WithContext(context.Background()), target)
+		elapsed := time.Since(start)
+
+		if test.want == "" {
+			if err == nil {
+				t.Errorf("%s: forwarding should fail", test.name)
+			}
+		} else if err != nil || w.Body.String() != test.want {
+			t.Errorf("%s: got %q %v, want %q", test.name, w.Body.String(), err, test.want)
+		}
+		if elapsed > test.maxTime {
+			t.Errorf("%s: took %v", test.name, elapsed)
+		}
+		if test.first != "closed" && atomic.LoadInt32(firstRequests) != 1 {
+			t.Errorf("%s: first instance got %d requests", test.name, atomic.LoadInt32(firstRequests))
+		}
+		// 首个请求在对冲延迟内返回时不发出对冲请求
+		hedged := test.want != test.first
+		if test.second != "closed" && (atomic.LoadInt32(secondRequests) == 1) != hedged {
+			t.Errorf("%s: second instance got %d requests, hedged %v", test.name, atomic.LoadInt32(secondRequests), hedged)
+		}
+	}
+}
+
+func TestProxyWithHedgeSingleInstance(t *testing.T) {
+	registry := discover.NewMemoryDiscoveryClient()
+	discover.SetClient(registry)
+	backend, requests := newBackend(t, "only", 200*time.Millisecond, false)
+	registerBackends(t, registry, "hedge-single", backend)
+
+	// 没有其他实例可对冲时等待首个请求返回
+	router := newTestRouter()
+	target := &Target{Name: "hedge-single", Service: "hedge-single", Path: "/", Hedge: 50 * time.Millisecond, Balance: loadbalance.Random}
+	w := httptest.NewRecorder()
+	if err := router.proxyWithHedge(w, httptest.NewRequest("GET", "/", nil), target); err != nil || w.Body.String() != "only" {
+		t.Errorf("got %q %v", w.Body.String(), err)
+	}
+	if n := atomic.LoadInt32(requests); n != 1 {
+		t.Errorf("got %d requests", n)
+	}
+}
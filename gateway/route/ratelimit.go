@@ -0,0 +1,203 @@
+package route
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/lixichongAAA/seckill/gateway/config"
+	"golang.org/x/time/rate"
+)
+
+const (
+	rateLimitKeyPrefix = "gateway:rate_limit:"
+	bucketIdleTimeout  = time.Minute * 10 //本地令牌桶闲置超过该时间后被清理
+)
+
+// RateLimiter 令牌桶限流
+type RateLimiter interface {
+	// Allow 从 key 对应的令牌桶中取一个令牌, 令牌不足时返回需要等待的时间
+	Allow(key string, limit float64, burst int) (bool, time.Duration, error)
+}
+
+// compiledRateLimit 由限流规则编译而成
+type compiledRateLimit struct {
+	config.RateLimitRule
+	routes map[string]bool
+}
+
+func compileRateLimit(rule config.RateLimitRule) (*compiledRateLimit, error) {
+	if rule.Name == "" {
+		return nil, errors.New("name is required")
+	}
+	switch rule.Key {
+	case config.RateLimitKeyRoute, config.RateLimitKeyClient, config.RateLimitKeyUser, config.RateLimitKeyIp:
+	default:
+		return nil, errors.New("unknown key " + rule.Key)
+	}
+	if rule.Rate <= 0 || rule.Burst <= 0 {
+		return nil, errors.New("rate and burst must be positive")
+	}
+
+	limit := &compiledRateLimit{
+		RateLimitRule: rule,
+		routes:        make(map[string]bool, len(rule.Routes)),
+	}
+	for _, route := range rule.Routes {
+		limit.routes[route] = true
+	}
+	return limit, nil
+}
+
+// bucketKey 返回请求在该规则下的令牌桶, 规则不适用于该请求时返回 false
+func (limit *compiledRateLimit) bucketKey(target *Target, identity *Identity, clientIp string) (string, bool) {
+	if len(limit.routes) > 0 && !limit.routes[target.Name] {
+		return "", false
+	}
+
+	var value string
+	switch limit.Key {
+	case config.RateLimitKeyRoute:
+		value = target.Name
+	case config.RateLimitKeyClient:
+		if identity == nil || identity.ClientId == "" {
+			return "", false
+		}
+		value = identity.ClientId
+	case config.RateLimitKeyUser:
+		if identity == nil {
+			return "", false
+		}
+		value = strconv.FormatInt(identity.UserId, 10)
+	case config.RateLimitKeyIp:
+		if clientIp == "" {
+			return "", false
+		}
+		value = clientIp
+	}
+	return limit.Name + ":" + value, true
+}
+
+// LocalRateLimiter 在网关实例内存中维护令牌桶
+type LocalRateLimiter struct {
+	lock      sync.Mutex
+	buckets   map[string]*localBucket
+	lastSweep time.Time
+}
+
+type localBucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+func NewLocalRateLimiter() *LocalRateLimiter {
+	return &LocalRateLimiter{
+		buckets:   make(map[string]*localBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+func (l *LocalRateLimiter) Allow(key string, limit float64, burst int) (bool, time.Duration, error) {
+	now := time.Now()
+	l.lock.Lock()
+	l.sweep(now)
+	bucket, ok := l.buckets[key]
+	if !ok || bucket.limiter.Burst() != burst {
+		bucket = &localBucket{
+			limiter: rate.NewLimiter(rate.Limit(limit), burst),
+		}
+		l.buckets[key] = bucket
+	} else if bucket.limiter.Limit() != rate.Limit(limit) {
+		// 配置重新加载后沿用原有令牌桶, 只调整速率
+		bucket.limiter.SetLimitAt(now, rate.Limit(limit))
+	}
+	bucket.lastSeen = now
+	limiter := bucket.limiter
+	l.lock.Unlock()
+
+	reservation := limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay, nil
+	}
+	return true, 0, nil
+}
+
+// sweep 清理闲置的令牌桶, 调用方需持有锁
+func (l *LocalRateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < bucketIdleTimeout {
+		return
+	}
+	l.lastSweep = now
+	for key, bucket := range l.buckets {
+		if now.Sub(bucket.lastSeen) >= bucketIdleTimeout {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// tokenBucketScript 在 Redis 中原子地补充并扣减令牌, 令牌桶以 hash 保存剩余令牌数和上次补充时间(毫秒)
+// 返回 {是否允许, 需要等待的毫秒数}
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) * 1000 / rate)
+end
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return {allowed, wait}
+`)
+
+// RedisRateLimiter 在 Redis 中维护令牌桶, 所有网关实例共享配额
+type RedisRateLimiter struct {
+	conn *redis.Client
+}
+
+func NewRedisRateLimiter(conn *redis.Client) *RedisRateLimiter {
+	return &RedisRateLimiter{
+		conn: conn,
+	}
+}
+
+func (l *RedisRateLimiter) Allow(key string, limit float64, burst int) (bool, time.Duration, error) {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	result, err := tokenBucketScript.Run(l.conn, []string{rateLimitKeyPrefix + key},
+		strconv.FormatFloat(limit, 'f', -1, 64), burst, now).Result()
+	if err != nil {
+		return false, 0, err
+	}
+	values, ok := result.([]interface{})
+	if !ok || len(values) != 2 {
+		return false, 0, errors.New("unexpected rate limit script result")
+	}
+	allowed, _ := values[0].(int64)
+	wait, _ := values[1].(int64)
+	return allowed == 1, time.Duration(wait) * time.Millisecond, nil
+}
+
+// retryAfterSeconds 将等待时间向上取整为 Retry-After 的秒数
+func retryAfterSeconds(wait time.Duration) string {
+	seconds := int64(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.FormatInt(seconds, 10)
+}
@@ -0,0 +1,183 @@
+package route
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/lixichongAAA/seckill/gateway/config"
+)
+
+func newRateLimitTable(t *testing.T) *RouteTable {
+	table, err := NewRouteTable(&config.RouteConfig{
+		Routes: []config.RouteRule{
+			{Name: "seckill-kill", Prefix: "/seckill/kill", Methods: []string{"POST"}, Rewrite: "/sec/kill", Service: "sk-app"},
+			{Name: "seckill", Prefix: "/seckill/", Rewrite: "/sec/", Service: "sk-app"},
+		},
+		Aliases: map[string]string{"app": "sk-app"},
+		RateLimit: config.RateLimitConfig{
+			Rules: []config.RateLimitRule{
+				{Name: "seckill-user", Routes: []string{"seckill-kill"}, Key: config.RateLimitKeyUser, Rate: 2, Burst: 5},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return table
+}
+
+// 绕过路由直接按服务名访问同一后端路径时, 路由上的限流规则同样生效
+func TestRateLimitFallbackPath(t *testing.T) {
+	table := newRateLimitTable(t)
+	identity := &Identity{UserId: 7}
+	tests := []struct {
+		path    string
+		limited bool
+	}{
+		{"/seckill/kill", true},
+		{"/sk-app/sec/kill", true},
+		{"/app/sec/kill", true},
+		{"/SK-APP/sec/kill", true},
+		{"/sk-app/sec/list", false},
+		{"/seckill/list", false},
+		{"/sk-admin/sec/kill", false},
+	}
+	for _, test := range tests {
+		target, err := table.Match(httptest.NewRequest("POST", test.path, nil))
+		if err != nil {
+			t.Fatalf("%s: %v", test.path, err)
+		}
+		key, ok := table.rateLimits[0].bucketKey(target, identity, "10.0.0.1")
+		if ok != test.limited {
+			t.Errorf("%s: limited %v, want %v (route %s)", test.path, ok, test.limited, target.Name)
+			continue
+		}
+		if ok && key != "seckill-user:7" {
+			t.Errorf("%s: got bucket %s", test.path, key)
+		}
+	}
+}
+
+func TestRateLimitBucketKey(t *testing.T) {
+	target := &Target{Name: "seckill-kill"}
+	tests := []struct {
+		name     string
+		rule     config.RateLimitRule
+		target   *Target
+		identity *Identity
+		ip       string
+		want     string //为空表示规则不适用
+	}{
+		{"route", config.RateLimitRule{Name: "r", Key: config.RateLimitKeyRoute}, target, nil, "", "r:seckill-kill"},
+		{"other route", config.RateLimitRule{Name: "r", Key: config.RateLimitKeyRoute, Routes: []string{"seckill-list"}}, target, nil, "", ""},
+		{"client", config.RateLimitRule{Name: "c", Key: config.RateLimitKeyClient}, target, &Identity{ClientId: "app"}, "", "c:app"},
+		{"client without token", config.RateLimitRule{Name: "c", Key: config.RateLimitKeyClient}, target, nil, "", ""},
+		{"client without id", config.RateLimitRule{Name: "c", Key: config.RateLimitKeyClient}, target, &Identity{UserId: 1}, "", ""},
+		{"user", config.RateLimitRule{Name: "u", Key: config.RateLimitKeyUser}, target, &Identity{UserId: 1}, "", "u:1"},
+		{"user without token", config.RateLimitRule{Name: "u", Key: config.RateLimitKeyUser}, target, nil, "10.0.0.1", ""},
+		{"ip", config.RateLimitRule{Name: "i", Key: config.RateLimitKeyIp}, target, nil, "10.0.0.1", "i:10.0.0.1"},
+		{"no ip", config.RateLimitRule{Name: "i", Key: config.RateLimitKeyIp}, target, nil, "", ""},
+	}
+	for _, test := range tests {
+		test.rule.Rate, test.rule.Burst = 1, 1
+		limit, err := compileRateLimit(test.rule)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		key, ok := limit.bucketKey(test.target, test.identity, test.ip)
+		if ok != (test.want != "") || key != test.want {
+			t.Errorf("%s: got %q %v, want %q", test.name, key, ok, test.want)
+		}
+	}
+}
+
+func TestCompileRateLimit(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  config.RateLimitRule
+		valid bool
+	}{
+		{"valid", config.RateLimitRule{Name: "a", Key: config.RateLimitKeyIp, Rate: 1, Burst: 1}, true},
+		{"no name", config.RateLimitRule{Key: config.RateLimitKeyIp, Rate: 1, Burst: 1}, false},
+		{"unknown key", config.RateLimitRule{Name: "a", Key: "header", Rate: 1, Burst: 1}, false},
+		{"zero rate", config.RateLimitRule{Name: "a", Key: config.RateLimitKeyIp, Burst: 1}, false},
+		{"zero burst", config.RateLimitRule{Name: "a", Key: config.RateLimitKeyIp, Rate: 1}, false},
+	}
+	for _, test := range tests {
+		if _, err := compileRateLimit(test.rule); (err == nil) != test.valid {
+			t.Errorf("%s: got err %v", test.name, err)
+		}
+	}
+}
+
+func TestLocalRateLimiter(t *testing.T) {
+	limiter := NewLocalRateLimiter()
+	for i := 0; i < 3; i++ {
+		if allowed, _, _ := limiter.Allow("a", 1, 3); !allowed {
+			t.Fatalf("request %d should be allowed within burst", i)
+		}
+	}
+	allowed, wait, err := limiter.Allow("a", 1, 3)
+	if err != nil || allowed || wait <= 0 {
+		t.Fatalf("request over burst should wait, got %v %v %v", allowed, wait, err)
+	}
+	if allowed, _, _ := limiter.Allow("b", 1, 3); !allowed {
+		t.Error("buckets should be independent")
+	}
+	// 容量变化时重建令牌桶
+	if allowed, _, _ := limiter.Allow("a", 1, 5); !allowed {
+		t.Error("bucket should be rebuilt when burst changes")
+	}
+}
+
+// 需要本地运行的 Redis, 否则跳过
+func TestRedisRateLimiter(t *testing.T) {
+	conn := redis.NewClient(&redis.Options{Addr: "127.0.0.1:6379", DialTimeout: 100 * time.Millisecond})
+	defer conn.Close()
+	if err := conn.Ping().Err(); err != nil {
+		t.Skip("redis is not available:", err)
+	}
+	key := "test:" + time.Now().Format(time.RFC3339Nano)
+	defer conn.Del(rateLimitKeyPrefix + key)
+
+	limiter := NewRedisRateLimiter(conn)
+	for i := 0; i < 3; i++ {
+		if allowed, _, err := limiter.Allow(key, 1, 3); err != nil || !allowed {
+			t.Fatalf("request %d should be allowed within burst, got %v %v", i, allowed, err)
+		}
+	}
+	allowed, wait, err := limiter.Allow(key, 1, 3)
+	if err != nil || allowed || wait <= 0 || wait > time.Second {
+		t.Fatalf("request over burst should wait, got %v %v %v", allowed, wait, err)
+	}
+	if ttl := conn.PTTL(rateLimitKeyPrefix + key).Val(); ttl <= 0 || ttl > 4*time.Second {
+		t.Errorf("bucket should expire once refilled, got ttl %v", ttl)
+	}
+}
+
+func TestRedisRateLimiterUnavailable(t *testing.T) {
+	conn := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: 100 * time.Millisecond, MaxRetries: -1})
+	defer conn.Close()
+	if _, _, err := NewRedisRateLimiter(conn).Allow("a", 1, 1); err == nil {
+		t.Error("unreachable redis should return an error")
+	}
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	tests := []struct {
+		wait time.Duration
+		want string
+	}{
+		{0, "1"},
+		{300 * time.Millisecond, "1"},
+		{time.Second, "1"},
+		{1500 * time.Millisecond, "2"},
+	}
+	for _, test := range tests {
+		if got := retryAfterSeconds(test.wait); got != test.want {
+			t.Errorf("%v: got %s, want %s", test.wait, got, test.want)
+		}
+	}
+}
@@ -3,18 +3,20 @@ package route
 import (
 	"context"
 	"errors"
-	"fmt"
+	"net"
 	"net/http"
 	"net/http/httputil"
-	"strings"
+	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/afex/hystrix-go/hystrix"
 	"github.com/go-kit/kit/log"
+	"github.com/go-redis/redis"
 	"github.com/lixichongAAA/seckill/gateway/config"
-	"github.com/lixichongAAA/seckill/pb"
-	"github.com/lixichongAAA/seckill/pkg/client"
-	"github.com/lixichongAAA/seckill/pkg/discover"
+	"github.com/lixichongAAA/seckill/gateway/proxy"
+	conf "github.com/lixichongAAA/seckill/pkg/config"
 	"github.com/lixichongAAA/seckill/pkg/loadbalance"
 	"github.com/openzipkin/zipkin-go"
 	zipkinhttpsvr "github.com/openzipkin/zipkin-go/middleware/http"
@@ -22,50 +24,164 @@ import (
 
 // HystrixRouter hystrix路由
 type HystrixRouter struct {
-	svcMap      *sync.Map      //服务实例，存储已经通过hystrix监控服务列表
-	logger      log.Logger     //日志工具
-	fallbackMsg string         //回调消息
-	tracer      *zipkin.Tracer //服务追踪对象
-	loadbalance loadbalance.LoadBalance
+	svcMap      *sync.Map              //hystrix 命令名 -> 断路器配置，存储已经通过hystrix监控的命令
+	logger      log.Logger             //日志工具
+	fallbackMsg string                 //回调消息
+	tracer      *zipkin.Tracer         //服务追踪对象
+	balancers   *balancerPool          //各服务的负载均衡器
+	table       *atomic.Value          //当前生效的路由表(*RouteTable)
+	verifier    *TokenVerifier         //令牌校验
+	limiters    map[string]RateLimiter //限流计数的存储方式 -> 限流器, 重新加载配置时沿用
+	cache       *ResponseCache         //响应缓存
+	outliers    *OutlierDetector       //异常实例摘除
+	transports  *proxy.TransportPool   //各上游服务的连接池
+	proxy       *httputil.ReverseProxy //共用的反向代理
 }
 
-func Routes(zipkinTracer *zipkin.Tracer, fbMsg string, logger log.Logger) http.Handler {
-	return HystrixRouter{
+func Routes(zipkinTracer *zipkin.Tracer, fbMsg string, logger log.Logger) HystrixRouter {
+	router := HystrixRouter{
 		svcMap:      &sync.Map{},
 		logger:      logger,
 		fallbackMsg: fbMsg,
 		tracer:      zipkinTracer,
-		loadbalance: &loadbalance.RandomLoadBalance{},
+		balancers:   newBalancerPool(),
+		table:       &atomic.Value{},
+		verifier:    NewTokenVerifier(config.JwtConfig.Secret, time.Duration(config.JwtConfig.CacheSeconds)*time.Second),
+		limiters: map[string]RateLimiter{
+			config.RateLimitBackendLocal: NewLocalRateLimiter(),
+		},
+		cache:    NewResponseCache(),
+		outliers: NewOutlierDetector(),
+		// 为反向代理增加追踪逻辑, 使用 zipkin 的 RoundTrip 包装各服务的连接池
+		transports: proxy.NewTransportPool(proxy.DefaultTransportOptions(), func(rt http.RoundTripper) http.RoundTripper {
+			roundTrip, _ := zipkinhttpsvr.NewTransport(zipkinTracer, zipkinhttpsvr.RoundTripper(rt), zipkinhttpsvr.TransportTrace(true))
+			return roundTrip
+		}),
+		proxy: newReverseProxy(),
 	}
+	if conf.Redis.Host != "" {
+		router.limiters[config.RateLimitBackendRedis] = NewRedisRateLimiter(redis.NewClient(&redis.Options{
+			Addr:     conf.Redis.Host,
+			Password: conf.Redis.Password,
+			DB:       conf.Redis.Db,
+		}))
+	}
+	router.table.Store(&RouteTable{})
+
+	routeConfig, err := config.LoadRouteConfig()
+	if err != nil {
+		logger.Log("load route config failed", err)
+		return router
+	}
+	router.reloadRoutes(routeConfig)
+	config.WatchRouteConfig(router.reloadRoutes, func(err error) {
+		logger.Log("reload route config failed", err)
+	})
+	return router
 }
 
-func preFilter(r *http.Request) bool {
+// reloadRoutes 编译新的路由配置并替换当前路由表, 配置有误时保留原路由表
+func (router HystrixRouter) reloadRoutes(routeConfig *config.RouteConfig) {
+	table, err := NewRouteTable(routeConfig)
+	if err != nil {
+		router.logger.Log("invalid route config", err)
+		return
+	}
+	if _, ok := router.limiters[table.backend]; !ok {
+		router.logger.Log("invalid route config", "rate limit backend "+table.backend+" is not configured")
+		return
+	}
+	router.table.Store(table)
+	router.outliers.SetPolicy(table.outlier)
+	router.transports.SetOptions(table.transport)
+	router.balancers.SetHashWarmUp(table.hashWarmUp)
+	for _, route := range table.routes {
+		router.configureCommand(route.Name, route.command)
+	}
+	router.logger.Log("route table loaded, routes", len(table.routes), "aliases", len(table.aliases))
+}
+
+// configureCommand 断路器配置变化时重新设置, 超时时间、错误率等配置立即生效;
+// hystrix 的并发数在创建断路器时确定, 因此并发数变化时需清空所有断路器, 之后按新配置重建
+func (router HystrixRouter) configureCommand(name string, command hystrix.CommandConfig) {
+	old, ok := router.svcMap.Load(name)
+	if ok && old.(hystrix.CommandConfig) == command {
+		return
+	}
+	//把路由名称作为命令对象，设置参数
+	hystrix.ConfigureCommand(name, command)
+	router.svcMap.Store(name, command)
+	if ok && old.(hystrix.CommandConfig).MaxConcurrentRequests != command.MaxConcurrentRequests {
+		router.logger.Log("hystrix command", name, "max concurrent requests changed, flush circuits", command.MaxConcurrentRequests)
+		hystrix.Flush()
+	}
+}
+
+// preFilter 放行无需鉴权的路径, 其余请求需携带有效令牌, 校验通过后将用户信息通过请求头转发给后端服务
+// 返回令牌绑定的用户信息, 无需鉴权的请求为 nil
+func (router HystrixRouter) preFilter(r *http.Request) (*Identity, bool) {
+	removeIdentityHeaders(r)
+
 	//查询原始请求路径，如：/string-service/calculate/10/5
 	reqPath := r.URL.Path
 	if reqPath == "" {
-		return false
+		return nil, false
 	}
 
-	res := config.Match(reqPath)
+	if config.Match(reqPath) {
+		return nil, true
+	}
 
-	if res {
-		return true
+	identity, err := router.verifier.Verify(r.Header.Get("Authorization"))
+	if err != nil {
+		router.logger.Log("reqPath", reqPath, "verify token failed", err)
+		return nil, false
 	}
-	authToken := r.Header.Get("Authorization")
-	if authToken == "" {
-		return false
+	setIdentityHeaders(r, identity)
+	return identity, true
+}
+
+// rateLimit 依次检查所有适用的限流规则, 被限流时返回需要等待的时间
+// 限流器出错(如 Redis 不可用)时放行, 避免限流组件故障导致网关不可用
+func (router HystrixRouter) rateLimit(table *RouteTable, target *Target, identity *Identity, r *http.Request) (bool, time.Duration) {
+	if len(table.rateLimits) == 0 {
+		return true, 0
 	}
-	oauthClient, _ := client.NewOAuthClient("oauth", nil, nil)
+	limiter := router.limiters[table.backend]
+	ip := clientIp(r)
 
-	resp, remoteErr := oauthClient.CheckToken(context.Background(), nil, &pb.CheckTokenRequest{
-		Token: authToken,
-	})
+	for _, limit := range table.rateLimits {
+		key, ok := limit.bucketKey(target, identity, ip)
+		if !ok {
+			continue
+		}
+		allowed, wait, err := limiter.Allow(key, limit.Rate, limit.Burst)
+		if err != nil {
+			router.logger.Log("rate limit", limit.Name, "err", err)
+			continue
+		}
+		if !allowed {
+			router.logger.Log("rate limit", limit.Name, "key", key, "retry after", wait)
+			return false, wait
+		}
+	}
+	return true, 0
+}
+
+// hashKey 一致性哈希负载均衡使用的 key, 携带令牌的请求使用用户 id, 否则使用客户端 IP
+func hashKey(identity *Identity, r *http.Request) string {
+	if identity != nil {
+		return strconv.FormatInt(identity.UserId, 10)
+	}
+	return clientIp(r)
+}
 
-	if remoteErr != nil || resp == nil {
-		return false
-	} else {
-		return true
+func clientIp(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
 	}
+	return ip
 }
 
 func postFilter() {
@@ -83,66 +199,83 @@ func (router HystrixRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var err error
-	if reqPath == "" || !preFilter(r) {
+	identity, ok := router.preFilter(r)
+	if reqPath == "" || !ok {
 		err = errors.New("illegal request!")
 		w.WriteHeader(403)
 		w.Write([]byte(err.Error()))
 		return
 	}
 
-	//按照分隔符'/'对路径进行分解，获取服务名称serviceName
-	pathArray := strings.Split(reqPath, "/")
-	serviceName := pathArray[1]
+	table := router.table.Load().(*RouteTable)
+	target, err := table.Match(r)
+	if err != nil {
+		w.WriteHeader(404)
+		w.Write([]byte(err.Error()))
+		return
+	}
 
-	//检查是否已经加入监控
-	if _, ok := router.svcMap.Load(serviceName); !ok {
-		//把serviceName作为命令对象，设置参数
-		hystrix.ConfigureCommand(serviceName, hystrix.CommandConfig{Timeout: 1000})
-		router.svcMap.Store(serviceName, serviceName)
+	if allowed, wait := router.rateLimit(table, target, identity, r); !allowed {
+		w.Header().Set("Retry-After", retryAfterSeconds(wait))
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte("too many requests"))
+		return
 	}
 
-	//执行命令
-	err = hystrix.Do(serviceName, func() (err error) {
+	router.configureCommand(target.Name, target.Command)
+	r = r.WithContext(loadbalance.WithHashKey(r.Context(), hashKey(identity, r)))
 
-		//调用consul api查询serviceNam
-		serviceInstance, err := discover.DiscoveryService(serviceName)
-		if err != nil {
-			return err
-		}
-		// 创建 director
-		director := func(req *http.Request) {
-			//重新组织请求路径，去掉服务名称部分
-			destPath := strings.Join(pathArray[2:], "/")
-
-			//随机选择一个服务实例
-			router.logger.Log("service id", serviceInstance.Host, serviceInstance.Port)
-
-			//设置代理服务地址信息
-			//在这里，我们只是根据请求中的服务名直接转发：如果我们需要对外屏蔽服务名，这样的路由转发规则显然是不够的
-			//我们可以增加路由配置的多样性，可以抽出路由配置层，根据指定的规则进行路由转发
-			//如配置名称、头部的信息、请求的参数和请求的body等转发到指定的服务
-			req.URL.Scheme = "http"
-			req.URL.Host = fmt.Sprintf("%s:%d", serviceInstance.Host, serviceInstance.Port)
-			req.URL.Path = "/" + destPath
+	if target.CacheTTL > 0 {
+		if key, ok := cacheKey(r, target); ok {
+			router.serveCached(w, r, target, key)
+			return
 		}
+	}
 
-		var proxyError error = nil
-		// 为反向代理增加追踪逻辑，使用如下RoundTrip代替默认Transport
-		roundTrip, _ := zipkinhttpsvr.NewTransport(router.tracer, zipkinhttpsvr.TransportTrace(true))
+	// Do方法执行失败，响应错误信息
+	if err = router.forward(w, r, target); err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(err.Error()))
+	}
+}
 
-		//反向代理失败时错误处理
-		errorHandler := func(ew http.ResponseWriter, er *http.Request, err error) {
-			proxyError = err
-		}
+// serveCached 优先返回缓存的响应, 未命中时转发请求并缓存响应, 同一 key 的并发请求只转发一次
+func (router HystrixRouter) serveCached(w http.ResponseWriter, r *http.Request, target *Target, key string) {
+	now := time.Now()
+	if resp, ok := router.cache.Get(key, now); ok {
+		writeCachedResponse(w, resp, true, now)
+		return
+	}
 
-		proxy := &httputil.ReverseProxy{
-			Director:     director,
-			Transport:    roundTrip,
-			ErrorHandler: errorHandler,
+	resp, shared, err := router.cache.Do(key, func() (*cachedResponse, error) {
+		// 转发结果由等待同一 key 的请求共享, 不随发起转发的请求取消, 只受路由超时限制
+		ctx, cancel := context.WithTimeout(detachedContext{r.Context()}, target.Timeout)
+		defer cancel()
+		buffer := newResponseBuffer()
+		if err := router.forward(buffer, r.WithContext(ctx), target); err != nil {
+			return nil, err
 		}
-		proxy.ServeHTTP(w, r)
+		return buffer.toCachedResponse(target.CacheTTL, time.Now()), nil
+	})
+	if err != nil {
+		w.WriteHeader(500)
+		w.Write([]byte(err.Error()))
+		return
+	}
+	writeCachedResponse(w, resp, shared, time.Now())
+}
 
-		return proxyError
+// forward 在断路器保护下将请求转发到目标服务, 按路由配置重试或发出对冲请求
+func (router HystrixRouter) forward(w http.ResponseWriter, r *http.Request, target *Target) error {
+	//执行命令
+	return hystrix.Do(target.Name, func() (err error) {
+		//超时后取消转发, 避免 hystrix 返回后代理仍在写响应
+		ctx, cancel := context.WithTimeout(r.Context(), target.Timeout)
+		defer cancel()
+		if target.Hedge > 0 && r.Method == http.MethodGet {
+			return router.proxyWithHedge(w, r.WithContext(ctx), target)
+		}
+		return router.proxyWithRetry(w, r.WithContext(ctx), target)
 
 	}, func(err error) error {
 		//run执行失败，返回fallback信息
@@ -150,10 +283,4 @@ func (router HystrixRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 		return errors.New(router.fallbackMsg)
 	})
-
-	// Do方法执行失败，响应错误信息
-	if err != nil {
-		w.WriteHeader(500)
-		w.Write([]byte(err.Error()))
-	}
 }
@@ -0,0 +1,125 @@
+package route
+
+import (
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"github.com/go-kit/kit/log"
+	"github.com/lixichongAAA/seckill/gateway/config"
+)
+
+func TestConfigureCommand(t *testing.T) {
+	router := HystrixRouter{svcMap: &sync.Map{}, logger: log.NewNopLogger()}
+	base := hystrix.CommandConfig{Timeout: 1000, MaxConcurrentRequests: 10, ErrorPercentThreshold: 50, RequestVolumeThreshold: 20, SleepWindow: 5000}
+	withTimeout, withConcurrency := base, base
+	withTimeout.Timeout = 200
+	withConcurrency.MaxConcurrentRequests = 20
+
+	tests := []struct {
+		name    string
+		command hystrix.CommandConfig
+		flushed bool //是否重建断路器
+	}{
+		{"first", base, false},
+		{"unchanged", base, false},
+		{"timeout changed", withTimeout, false},
+		{"max concurrency changed", withConcurrency, true},
+		{"unchanged after flush", withConcurrency, false},
+	}
+	const name = "test-configure-command"
+	router.configureCommand(name, base)
+	for _, test := range tests {
+		circuit, _, _ := hystrix.GetCircuit(name)
+		router.configureCommand(name, test.command)
+		after, created, _ := hystrix.GetCircuit(name)
+		if (after != circuit) != test.flushed || created != test.flushed {
+			t.Errorf("%s: circuit rebuilt %v, want %v", test.name, after != circuit, test.flushed)
+		}
+		settings := hystrix.GetCircuitSettings()[name]
+		if settings.Timeout != time.Duration(test.command.Timeout)*time.Millisecond || settings.MaxConcurrentRequests != test.command.MaxConcurrentRequests {
+			t.Errorf("%s: got settings %+v", test.name, settings)
+		}
+	}
+}
+
+// 新的并发数在断路器重建后生效
+func TestConfigureCommandMaxConcurrency(t *testing.T) {
+	router := HystrixRouter{svcMap: &sync.Map{}, logger: log.NewNopLogger()}
+	const name = "test-configure-concurrency"
+	command := hystrix.CommandConfig{Timeout: 1000, MaxConcurrentRequests: 1, ErrorPercentThreshold: 50, RequestVolumeThreshold: 20, SleepWindow: 5000}
+	router.configureCommand(name, command)
+
+	concurrent := func() int {
+		release := make(chan struct{})
+		defer close(release)
+		started := 0
+		for i := 0; i < 3; i++ {
+			running := make(chan struct{})
+			hystrix.Go(name, func() error {
+				close(running)
+				<-release
+				return nil
+			}, nil)
+			select {
+			case <-running:
+				started++
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+		return started
+	}
+	if got := concurrent(); got != 1 {
+		t.Fatalf("got %d concurrent requests, want 1", got)
+	}
+	command.MaxConcurrentRequests = 2
+	router.configureCommand(name, command)
+	if got := concurrent(); got != 2 {
+		t.Errorf("got %d concurrent requests after reload, want 2", got)
+	}
+}
+
+type errRateLimiter struct{}
+
+func (errRateLimiter) Allow(key string, limit float64, burst int) (bool, time.Duration, error) {
+	return false, 0, errors.New("redis unavailable")
+}
+
+func TestRouterRateLimit(t *testing.T) {
+	table, err := NewRouteTable(&config.RouteConfig{
+		RateLimit: config.RateLimitConfig{
+			Rules: []config.RateLimitRule{
+				{Name: "route", Key: config.RateLimitKeyRoute, Rate: 1000, Burst: 1000},
+				{Name: "user", Key: config.RateLimitKeyUser, Rate: 1, Burst: 1},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		limiter  RateLimiter
+		identity *Identity
+		allowed  []bool
+	}{
+		{"within burst then limited", NewLocalRateLimiter(), &Identity{UserId: 1}, []bool{true, false}},
+		{"rule not applicable", NewLocalRateLimiter(), nil, []bool{true, true}},
+		// 限流器出错时放行
+		{"limiter error", errRateLimiter{}, &Identity{UserId: 1}, []bool{true, true}},
+	}
+	for _, test := range tests {
+		router := HystrixRouter{logger: log.NewNopLogger(), limiters: map[string]RateLimiter{config.RateLimitBackendLocal: test.limiter}}
+		r := httptest.NewRequest("GET", "/sk-app/sec/list", nil)
+		target, _ := table.Match(r)
+		for i, want := range test.allowed {
+			allowed, wait := router.rateLimit(table, target, test.identity, r)
+			if allowed != want || allowed != (wait == 0) {
+				t.Errorf("%s: request %d got %v %v, want %v", test.name, i, allowed, wait, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package route
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"github.com/lixichongAAA/seckill/gateway/config"
+	"github.com/lixichongAAA/seckill/gateway/proxy"
+	"github.com/lixichongAAA/seckill/pkg/loadbalance"
+)
+
+const defaultRouteTimeout = 1000 //默认超时时间, 单位毫秒
+
+var ErrNoRoute = errors.New("no route matched")
+
+// Target 请求匹配路由后的转发目标
+type Target struct {
+	Name     string                //hystrix 命令名
+	Service  string                //目标服务名
+	Path     string                //转发到目标服务的路径
+	Timeout  time.Duration         //超时时间
+	Command  hystrix.CommandConfig //断路器配置
+	CacheTTL time.Duration         //响应缓存时间, 为 0 时不缓存
+	Retries  int                   //连接实例失败时的最多重试次数
+	Hedge    time.Duration         //GET 请求发出对冲请求前的等待时间, 为 0 时不对冲
+	Balance  string                //负载均衡策略
+}
+
+// RouteTable 由路由配置编译而成, 创建后只读, 重新加载配置时整体替换
+type RouteTable struct {
+	routes     []*compiledRoute
+	aliases    map[string]string
+	services   map[string]config.ServiceRule
+	backend    string //限流计数的存储方式
+	rateLimits []*compiledRateLimit
+	outlier    outlierPolicy
+	transport  proxy.TransportOptions
+	balance    string        //默认的负载均衡策略
+	hashWarmUp time.Duration //一致性哈希新实例的预热时长
+}
+
+type compiledRoute struct {
+	config.RouteRule
+	regex        *regexp.Regexp
+	methods      map[string]bool
+	headers      map[string]*regexp.Regexp
+	command      hystrix.CommandConfig
+	cacheTTL     time.Duration
+	cacheMethods map[string]bool
+	retries      int
+	hedge        time.Duration
+	upstream     string //转发到目标服务的路径前缀, 为空时表示无法确定
+}
+
+// NewRouteTable 校验并编译路由配置
+func NewRouteTable(routeConfig *config.RouteConfig) (*RouteTable, error) {
+	table := &RouteTable{
+		aliases:  routeConfig.Aliases,
+		services: routeConfig.Services,
+	}
+	for name, service := range table.services {
+		if service.Timeout < 0 {
+			return nil, fmt.Errorf("service [%s]: timeout must not be negative", name)
+		}
+		if err := validateHystrix(service.Hystrix); err != nil {
+			return nil, fmt.Errorf("service [%s]: %v", name, err)
+		}
+		if err := validateRetry(service.Retry); err != nil {
+			return nil, fmt.Errorf("service [%s]: %v", name, err)
+		}
+		if _, err := loadbalance.New(service.LoadBalance); err != nil {
+			return nil, fmt.Errorf("service [%s]: %v", name, err)
+		}
+	}
+	if _, err := loadbalance.New(routeConfig.LoadBalance); err != nil {
+		return nil, err
+	}
+	table.balance = routeConfig.LoadBalance
+	if routeConfig.ConsistentHash.WarmUp < 0 {
+		return nil, errors.New("consistent hash warmUp must not be negative")
+	}
+	table.hashWarmUp = loadbalance.DefaultHashWarmUp
+	if routeConfig.ConsistentHash.WarmUp > 0 {
+		table.hashWarmUp = time.Duration(routeConfig.ConsistentHash.WarmUp) * time.Millisecond
+	}
+	for i, rule := range routeConfig.Routes {
+		route, err := compileRoute(rule, table.services[strings.ToLower(rule.Service)])
+		if err != nil {
+			return nil, fmt.Errorf("route %d [%s]: %v", i, rule.Name, err)
+		}
+		table.routes = append(table.routes, route)
+	}
+
+	table.backend = routeConfig.RateLimit.Backend
+	if table.backend == "" {
+		table.backend = config.RateLimitBackendLocal
+	}
+	if table.backend != config.RateLimitBackendLocal && table.backend != config.RateLimitBackendRedis {
+		return nil, errors.New("unknown rate limit backend " + table.backend)
+	}
+	for i, rule := range routeConfig.RateLimit.Rules {
+		limit, err := compileRateLimit(rule)
+		if err != nil {
+			return nil, fmt.Errorf("rate limit %d [%s]: %v", i, rule.Name, err)
+		}
+		table.rateLimits = append(table.rateLimits, limit)
+	}
+
+	outlier, err := compileOutlier(routeConfig.Outlier)
+	if err != nil {
+		return nil, fmt.Errorf("outlier: %v", err)
+	}
+	table.outlier = outlier
+
+	table.transport, err = transportOptions(routeConfig.Transport)
+	if err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+func compileRoute(rule config.RouteRule, service config.ServiceRule) (*compiledRoute, error) {
+	if rule.Service == "" {
+		return nil, errors.New("service is required")
+	}
+	if (rule.Prefix == "") == (rule.Regex == "") {
+		return nil, errors.New("exactly one of prefix and regex is required")
+	}
+	if rule.Timeout < 0 {
+		return nil, errors.New("timeout must not be negative")
+	}
+	if err := validateHystrix(rule.Hystrix); err != nil {
+		return nil, err
+	}
+	if err := validateRetry(rule.Retry); err != nil {
+		return nil, err
+	}
+	if rule.Name == "" {
+		rule.Name = rule.Service
+	}
+
+	route := &compiledRoute{
+		RouteRule:    rule,
+		methods:      make(map[string]bool, len(rule.Methods)),
+		headers:      make(map[string]*regexp.Regexp, len(rule.Headers)),
+		command:      commandConfig(rule.Timeout, rule.Hystrix, service),
+		cacheTTL:     time.Duration(rule.Cache.Ttl) * time.Millisecond,
+		cacheMethods: map[string]bool{http.MethodGet: true},
+	}
+	route.retries, route.hedge = retryConfig(rule.Retry, service)
+	route.upstream = upstreamPrefix(rule)
+	if rule.Cache.Ttl < 0 {
+		return nil, errors.New("cache ttl must not be negative")
+	}
+	if len(rule.Cache.Methods) > 0 {
+		route.cacheMethods = make(map[string]bool, len(rule.Cache.Methods))
+		for _, method := range rule.Cache.Methods {
+			route.cacheMethods[strings.ToUpper(method)] = true
+		}
+	}
+	if rule.Regex != "" {
+		regex, err := regexp.Compile(rule.Regex)
+		if err != nil {
+			return nil, err
+		}
+		route.regex = regex
+	}
+	for _, method := range rule.Methods {
+		route.methods[strings.ToUpper(method)] = true
+	}
+	for name, pattern := range rule.Headers {
+		regex, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("header %s: %v", name, err)
+		}
+		route.headers[http.CanonicalHeaderKey(name)] = regex
+	}
+	return route, nil
+}
+
+// Match 按配置顺序查找第一条匹配的路由, 均未匹配时以路径第一段(或其别名)作为服务名
+// 按服务名转发的路径若也能通过某条路由到达, 则视为该路由的请求, 使其限流、断路器等配置同样生效
+func (t *RouteTable) Match(r *http.Request) (*Target, error) {
+	reqPath := r.URL.Path
+	for _, route := range t.routes {
+		if destPath, ok := route.match(r, reqPath); ok {
+			return t.routeTarget(route, r, destPath), nil
+		}
+	}
+
+	//按照分隔符'/'对路径进行分解，获取服务名称serviceName
+	pathArray := strings.Split(reqPath, "/")
+	if len(pathArray) < 2 || pathArray[1] == "" {
+		return nil, ErrNoRoute
+	}
+	serviceName := pathArray[1]
+	if alias, ok := t.aliases[strings.ToLower(serviceName)]; ok {
+		serviceName = alias
+	}
+	//重新组织请求路径，去掉服务名称部分
+	destPath := "/" + strings.Join(pathArray[2:], "/")
+	if route := t.upstreamRoute(serviceName, destPath); route != nil {
+		return t.routeTarget(route, r, destPath), nil
+	}
+
+	service := t.services[strings.ToLower(serviceName)]
+	command := commandConfig(0, config.HystrixRule{}, service)
+	retries, hedge := retryConfig(config.RetryRule{}, service)
+	return &Target{
+		Name:    serviceName,
+		Service: serviceName,
+		Path:    destPath,
+		Timeout: time.Duration(command.Timeout) * time.Millisecond,
+		Command: command,
+		Retries: retries,
+		Hedge:   hedge,
+		Balance: t.balanceOf(serviceName),
+	}, nil
+}
+
+func (t *RouteTable) routeTarget(route *compiledRoute, r *http.Request, destPath string) *Target {
+	target := &Target{
+		Name:    route.Name,
+		Service: route.Service,
+		Path:    destPath,
+		Timeout: time.Duration(route.command.Timeout) * time.Millisecond,
+		Command: route.command,
+	}
+	if route.cacheMethods[r.Method] {
+		target.CacheTTL = route.cacheTTL
+	}
+	target.Retries, target.Hedge = route.retries, route.hedge
+	target.Balance = t.balanceOf(route.Service)
+	return target
+}
+
+// upstreamRoute 查找转发路径落在其转发范围内的路由, 多条路由匹配时选择转发路径前缀最长的
+func (t *RouteTable) upstreamRoute(service, destPath string) *compiledRoute {
+	var best *compiledRoute
+	for _, route := range t.routes {
+		if route.upstream == "" || !strings.EqualFold(route.Service, service) || !strings.HasPrefix(destPath, route.upstream) {
+			continue
+		}
+		if best == nil || len(route.upstream) > len(best.upstream) {
+			best = route
+		}
+	}
+	return best
+}
+
+// upstreamPrefix 返回路由转发到目标服务的路径前缀, 使用正则表达式的路由取转发路径中第一个分组引用之前的部分
+func upstreamPrefix(rule config.RouteRule) string {
+	if rule.Regex != "" {
+		if rule.Rewrite == "" {
+			return ""
+		}
+		if i := strings.Index(rule.Rewrite, "$"); i >= 0 {
+			return rule.Rewrite[:i]
+		}
+		return rule.Rewrite
+	}
+	if rule.Rewrite != "" {
+		return rule.Rewrite
+	}
+	if rule.StripPrefix {
+		return "/"
+	}
+	return rule.Prefix
+}
+
+// balanceOf 返回服务使用的负载均衡策略, 服务未配置时使用默认策略
+func (t *RouteTable) balanceOf(service string) string {
+	if balance := t.services[strings.ToLower(service)].LoadBalance; balance != "" {
+		return balance
+	}
+	if t.balance != "" {
+		return t.balance
+	}
+	return loadbalance.Random
+}
+
+// match 判断请求是否匹配该路由, 匹配时返回转发路径
+func (route *compiledRoute) match(r *http.Request, reqPath string) (string, bool) {
+	if len(route.methods) > 0 && !route.methods[r.Method] {
+		return "", false
+	}
+	for name, regex := range route.headers {
+		if !regex.MatchString(r.Header.Get(name)) {
+			return "", false
+		}
+	}
+
+	if route.regex != nil {
+		submatch := route.regex.FindStringSubmatchIndex(reqPath)
+		if submatch == nil {
+			return "", false
+		}
+		if route.Rewrite == "" {
+			return reqPath, true
+		}
+		return string(route.regex.ExpandString(nil, route.Rewrite, reqPath, submatch)), true
+	}
+
+	if !strings.HasPrefix(reqPath, route.Prefix) {
+		return "", false
+	}
+	if route.Rewrite != "" {
+		return joinPath(route.Rewrite, strings.TrimPrefix(reqPath, route.Prefix)), true
+	}
+	if route.StripPrefix {
+		return joinPath("/", strings.TrimPrefix(reqPath, route.Prefix)), true
+	}
+	return reqPath, true
+}
+
+// joinPath 拼接路径, 保证两部分之间只有一个 '/'
+func joinPath(base, rest string) string {
+	if rest == "" {
+		return base
+	}
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(rest, "/")
+}
+
+// commandConfig 合并路由和所属服务的断路器配置, 路由的配置优先
+func commandConfig(timeout int, rule config.HystrixRule, service config.ServiceRule) hystrix.CommandConfig {
+	return hystrix.CommandConfig{
+		Timeout:                firstPositive(timeout, service.Timeout, defaultRouteTimeout),
+		MaxConcurrentRequests:  firstPositive(rule.MaxConcurrentRequests, service.Hystrix.MaxConcurrentRequests, hystrix.DefaultMaxConcurrent),
+		ErrorPercentThreshold:  firstPositive(rule.ErrorPercentThreshold, service.Hystrix.ErrorPercentThreshold, hystrix.DefaultErrorPercentThreshold),
+		RequestVolumeThreshold: firstPositive(rule.RequestVolumeThreshold, service.Hystrix.RequestVolumeThreshold, hystrix.DefaultVolumeThreshold),
+		SleepWindow:            firstPositive(rule.SleepWindow, service.Hystrix.SleepWindow, hystrix.DefaultSleepWindow),
+	}
+}
+
+func validateHystrix(rule config.HystrixRule) error {
+	if rule.MaxConcurrentRequests < 0 || rule.RequestVolumeThreshold < 0 || rule.SleepWindow < 0 {
+		return errors.New("hystrix settings must not be negative")
+	}
+	if rule.ErrorPercentThreshold < 0 || rule.ErrorPercentThreshold > 100 {
+		return errors.New("hystrix errorPercentThreshold must be between 0 and 100")
+	}
+	return nil
+}
+
+// retryConfig 合并路由和所属服务的重试配置, 路由的配置优先
+func retryConfig(rule config.RetryRule, service config.ServiceRule) (int, time.Duration) {
+	return firstPositive(rule.Attempts, service.Retry.Attempts),
+		time.Duration(firstPositive(rule.HedgeDelay, service.Retry.HedgeDelay)) * time.Millisecond
+}
+
+func validateRetry(rule config.RetryRule) error {
+	if rule.Attempts < 0 || rule.HedgeDelay < 0 {
+		return errors.New("retry settings must not be negative")
+	}
+	return nil
+}
+
+func firstPositive(values ...int) int {
+	for _, v := range values {
+		if v > 0 {
+			return v
+		}
+	}
+	return 0
+}
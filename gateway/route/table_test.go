@@ -0,0 +1,213 @@
+package route
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"github.com/lixichongAAA/seckill/gateway/config"
+	"github.com/lixichongAAA/seckill/pkg/loadbalance"
+)
+
+func TestRouteTableMatch(t *testing.T) {
+	table, err := NewRouteTable(&config.RouteConfig{
+		Routes: []config.RouteRule{
+			{Name: "kill", Prefix: "/seckill/kill", Methods: []string{"post"}, Rewrite: "/sec/kill", Service: "sk-app"},
+			{Name: "canary", Prefix: "/seckill/", Headers: map[string]string{"X-Canary": "^(1|true)$"}, Rewrite: "/sec/", Service: "sk-app-canary"},
+			{Name: "seckill", Prefix: "/seckill/", Rewrite: "/sec/", Service: "sk-app"},
+			{Name: "product", Regex: `^/products/(\d+)$`, Rewrite: "/product/detail/$1", Service: "sk-admin"},
+			{Name: "activity", Regex: `^/activity/\d+$`, Service: "sk-admin"},
+			{Prefix: "/oauth/", StripPrefix: true, Service: "oauth-service"},
+			{Name: "user", Prefix: "/user/", Service: "user-service"},
+		},
+		Aliases: map[string]string{"app": "sk-app"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		headers map[string]string
+		route   string //为空表示没有匹配的路由
+		service string
+		dest    string
+	}{
+		{"prefix rewrite", "POST", "/seckill/kill", nil, "kill", "sk-app", "/sec/kill"},
+		{"method mismatch", "GET", "/seckill/kill", nil, "seckill", "sk-app", "/sec/kill"},
+		{"header match", "GET", "/seckill/list", map[string]string{"X-Canary": "true"}, "canary", "sk-app-canary", "/sec/list"},
+		{"header mismatch", "GET", "/seckill/list", map[string]string{"X-Canary": "yes"}, "seckill", "sk-app", "/sec/list"},
+		{"regex rewrite", "GET", "/products/42", nil, "product", "sk-admin", "/product/detail/42"},
+		{"regex mismatch", "GET", "/products/42/x", nil, "products", "products", "/42/x"},
+		{"regex without rewrite", "GET", "/activity/7", nil, "activity", "sk-admin", "/activity/7"},
+		{"strip prefix", "POST", "/oauth/token", nil, "oauth-service", "oauth-service", "/token"},
+		{"keep prefix", "GET", "/user/check", nil, "user", "user-service", "/user/check"},
+		{"service name", "GET", "/sk-app/sec/list", nil, "seckill", "sk-app", "/sec/list"},
+		{"alias", "POST", "/APP/sec/kill", nil, "kill", "sk-app", "/sec/kill"},
+		{"unknown service", "GET", "/other/a/b", nil, "other", "other", "/a/b"},
+		{"service root", "GET", "/other", nil, "other", "other", "/"},
+		{"root", "GET", "/", nil, "", "", ""},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(test.method, test.path, nil)
+		for name, value := range test.headers {
+			r.Header.Set(name, value)
+		}
+		target, err := table.Match(r)
+		if test.route == "" {
+			if err != ErrNoRoute {
+				t.Errorf("%s: got err %v, want ErrNoRoute", test.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if target.Name != test.route || target.Service != test.service || target.Path != test.dest {
+			t.Errorf("%s: got %s -> %s%s, want %s -> %s%s", test.name,
+				target.Name, target.Service, target.Path, test.route, test.service, test.dest)
+		}
+	}
+}
+
+func TestRouteTableServiceDefaults(t *testing.T) {
+	table, err := NewRouteTable(&config.RouteConfig{
+		Routes: []config.RouteRule{
+			{Name: "list", Prefix: "/seckill/list", Rewrite: "/sec/list", Service: "sk-app",
+				Cache: config.CacheRule{Ttl: 500}},
+			{Name: "kill", Prefix: "/seckill/kill", Rewrite: "/sec/kill", Service: "SK-APP", Timeout: 200,
+				Hystrix: config.HystrixRule{MaxConcurrentRequests: 50}, Retry: config.RetryRule{Attempts: 1},
+				Cache: config.CacheRule{Ttl: 500, Methods: []string{"post"}}},
+		},
+		Services: map[string]config.ServiceRule{
+			"sk-app": {Timeout: 800, Hystrix: config.HystrixRule{MaxConcurrentRequests: 200, SleepWindow: 3000},
+				Retry: config.RetryRule{Attempts: 2, HedgeDelay: 100}, LoadBalance: loadbalance.LeastConn},
+		},
+		LoadBalance: loadbalance.WeightRoundRobin,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		timeout       time.Duration
+		maxConcurrent int
+		sleepWindow   int
+		retries       int
+		hedge         time.Duration
+		cacheTTL      time.Duration
+		balance       string
+	}{
+		{"service defaults", "GET", "/seckill/list", 800 * time.Millisecond, 200, 3000, 2, 100 * time.Millisecond, 500 * time.Millisecond, loadbalance.LeastConn},
+		{"cache only get", "POST", "/seckill/list", 800 * time.Millisecond, 200, 3000, 2, 100 * time.Millisecond, 0, loadbalance.LeastConn},
+		{"route overrides", "POST", "/seckill/kill", 200 * time.Millisecond, 50, 3000, 1, 100 * time.Millisecond, 500 * time.Millisecond, loadbalance.LeastConn},
+		{"route cache methods", "GET", "/seckill/kill", 200 * time.Millisecond, 50, 3000, 1, 100 * time.Millisecond, 0, loadbalance.LeastConn},
+		{"fallback service", "GET", "/sk-app/other", 800 * time.Millisecond, 200, 3000, 2, 100 * time.Millisecond, 0, loadbalance.LeastConn},
+		{"hystrix defaults", "GET", "/sk-admin/a", defaultRouteTimeout * time.Millisecond, hystrix.DefaultMaxConcurrent, hystrix.DefaultSleepWindow, 0, 0, 0, loadbalance.WeightRoundRobin},
+	}
+	for _, test := range tests {
+		target, err := table.Match(httptest.NewRequest(test.method, test.path, nil))
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if target.Timeout != test.timeout || target.Command.Timeout != int(test.timeout/time.Millisecond) {
+			t.Errorf("%s: got timeout %v, want %v", test.name, target.Timeout, test.timeout)
+		}
+		if target.Command.MaxConcurrentRequests != test.maxConcurrent || target.Command.SleepWindow != test.sleepWindow {
+			t.Errorf("%s: got hystrix %+v", test.name, target.Command)
+		}
+		if target.Retries != test.retries || target.Hedge != test.hedge {
+			t.Errorf("%s: got retries %d hedge %v, want %d %v", test.name, target.Retries, target.Hedge, test.retries, test.hedge)
+		}
+		if target.CacheTTL != test.cacheTTL {
+			t.Errorf("%s: got cache ttl %v, want %v", test.name, target.CacheTTL, test.cacheTTL)
+		}
+		if target.Balance != test.balance {
+			t.Errorf("%s: got balance %s, want %s", test.name, target.Balance, test.balance)
+		}
+	}
+}
+
+func TestNewRouteTableValidation(t *testing.T) {
+	route := config.RouteRule{Prefix: "/a/", Service: "a"}
+	withRoute := func(modify func(rule *config.RouteRule)) *config.RouteConfig {
+		rule := route
+		modify(&rule)
+		return &config.RouteConfig{Routes: []config.RouteRule{rule}}
+	}
+	tests := []struct {
+		name   string
+		config *config.RouteConfig
+		valid  bool
+	}{
+		{"empty", &config.RouteConfig{}, true},
+		{"valid route", withRoute(func(rule *config.RouteRule) {}), true},
+		{"no service", withRoute(func(rule *config.RouteRule) { rule.Service = "" }), false},
+		{"no prefix or regex", withRoute(func(rule *config.RouteRule) { rule.Prefix = "" }), false},
+		{"prefix and regex", withRoute(func(rule *config.RouteRule) { rule.Regex = "^/a" }), false},
+		{"bad regex", withRoute(func(rule *config.RouteRule) { rule.Prefix, rule.Regex = "", "^/a(" }), false},
+		{"bad header regex", withRoute(func(rule *config.RouteRule) { rule.Headers = map[string]string{"X-A": "("} }), false},
+		{"negative timeout", withRoute(func(rule *config.RouteRule) { rule.Timeout = -1 }), false},
+		{"negative hystrix", withRoute(func(rule *config.RouteRule) { rule.Hystrix.SleepWindow = -1 }), false},
+		{"error percent over 100", withRoute(func(rule *config.RouteRule) { rule.Hystrix.ErrorPercentThreshold = 101 }), false},
+		{"negative retry", withRoute(func(rule *config.RouteRule) { rule.Retry.HedgeDelay = -1 }), false},
+		{"negative cache ttl", withRoute(func(rule *config.RouteRule) { rule.Cache.Ttl = -1 }), false},
+		{"negative service timeout", &config.RouteConfig{Services: map[string]config.ServiceRule{"a": {Timeout: -1}}}, false},
+		{"negative service retry", &config.RouteConfig{Services: map[string]config.ServiceRule{"a": {Retry: config.RetryRule{Attempts: -1}}}}, false},
+		{"unknown service balance", &config.RouteConfig{Services: map[string]config.ServiceRule{"a": {LoadBalance: "fastest"}}}, false},
+		{"unknown balance", &config.RouteConfig{LoadBalance: "fastest"}, false},
+		{"negative warm-up", &config.RouteConfig{ConsistentHash: config.ConsistentHashRule{WarmUp: -1}}, false},
+		{"unknown rate limit backend", &config.RouteConfig{RateLimit: config.RateLimitConfig{Backend: "memcached"}}, false},
+		{"bad rate limit rule", &config.RouteConfig{RateLimit: config.RateLimitConfig{Rules: []config.RateLimitRule{{Name: "a", Key: "ip"}}}}, false},
+		{"bad outlier", &config.RouteConfig{Outlier: config.OutlierRule{MaxEjectionPercent: 101}}, false},
+		{"negative transport", &config.RouteConfig{Transport: config.TransportRule{DialTimeout: -1}}, false},
+	}
+	for _, test := range tests {
+		if _, err := NewRouteTable(test.config); (err == nil) != test.valid {
+			t.Errorf("%s: got err %v", test.name, err)
+		}
+	}
+}
+
+func TestUpstreamPrefix(t *testing.T) {
+	tests := []struct {
+		rule config.RouteRule
+		want string
+	}{
+		{config.RouteRule{Prefix: "/seckill/", Rewrite: "/sec/"}, "/sec/"},
+		{config.RouteRule{Prefix: "/oauth/", StripPrefix: true}, "/"},
+		{config.RouteRule{Prefix: "/user/"}, "/user/"},
+		{config.RouteRule{Regex: `^/p/(\d+)$`, Rewrite: "/product/detail/$1"}, "/product/detail/"},
+		{config.RouteRule{Regex: `^/p$`, Rewrite: "/product/list"}, "/product/list"},
+		{config.RouteRule{Regex: `^/p$`}, ""},
+	}
+	for _, test := range tests {
+		if got := upstreamPrefix(test.rule); got != test.want {
+			t.Errorf("%+v: got %q, want %q", test.rule, got, test.want)
+		}
+	}
+}
+
+func TestJoinPath(t *testing.T) {
+	tests := []struct {
+		base, rest, want string
+	}{
+		{"/sec/", "list", "/sec/list"},
+		{"/sec", "/list", "/sec/list"},
+		{"/sec/", "/list", "/sec/list"},
+		{"/sec", "", "/sec"},
+		{"/", "", "/"},
+	}
+	for _, test := range tests {
+		if got := joinPath(test.base, test.rest); got != test.want {
+			t.Errorf("joinPath(%q, %q): got %q, want %q", test.base, test.rest, got, test.want)
+		}
+	}
+}
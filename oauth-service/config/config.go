@@ -5,7 +5,6 @@ import (
 
 	"github.com/go-kit/kit/log"
 	"github.com/lixichongAAA/seckill/pkg/bootstrap"
-	_ "github.com/lixichongAAA/seckill/pkg/bootstrap"
 	conf "github.com/lixichongAAA/seckill/pkg/config"
 	"github.com/openzipkin/zipkin-go"
 	zipkinhttp "github.com/openzipkin/zipkin-go/reporter/http"
@@ -15,12 +14,17 @@ import (
 
 const (
 	kConfigType = "CONFIG_TYPE"
+	kJwtSecret  = "jwt.secret"
 )
 
 var ZipkinTracer *zipkin.Tracer
 var Logger log.Logger
 
+// JwtSecret 签发令牌使用的密钥, 网关使用同一密钥在本地校验令牌
+var JwtSecret string
+
 func init() {
+	bootstrap.Load()
 	Logger = log.NewLogfmtLogger(os.Stderr)
 	Logger = log.With(Logger, "ts", log.DefaultTimestampUTC)
 	Logger = log.With(Logger, "caller", log.DefaultCaller)
@@ -37,6 +41,7 @@ func init() {
 	if err := conf.Sub("trace", &conf.TraceConfig); err != nil {
 		Logger.Log("Fail to parse trace", err)
 	}
+	JwtSecret = viper.GetString(kJwtSecret)
 	zipkinUrl := "http://" + conf.TraceConfig.Host + ":" + conf.TraceConfig.Port + conf.TraceConfig.Url
 	Logger.Log("zipkin url", zipkinUrl)
 	initTracer(zipkinUrl)
@@ -44,6 +49,7 @@ func init() {
 
 func initDefault() {
 	viper.SetDefault(kConfigType, "yaml")
+	viper.SetDefault(kJwtSecret, "secret")
 }
 
 func initTracer(zipkinURL string) {
@@ -52,7 +52,7 @@ func main() {
 
 	// add logging middleware
 
-	tokenEnhancer = service.NewJwtTokenEnhancer("secret")
+	tokenEnhancer = service.NewJwtTokenEnhancer(localconfig.JwtSecret)
 	tokenStore = service.NewJwtTokenStore(tokenEnhancer.(*service.JwtTokenEnhancer))
 	tokenService = service.NewTokenService(tokenStore, tokenEnhancer)
 	userDetailsService = service.NewRemoteUserDetailService()
@@ -23,9 +23,13 @@ type UserDetailsService interface {
 // UserService implement Service interface
 type RemoteUserService struct {
 	userClient client.UserClient
+	clientErr  error //创建 userClient 失败的原因
 }
 
 func (service *RemoteUserService) GetUserDetailByUsername(ctx context.Context, username, password string) (*model.UserDetails, error) {
+	if service.userClient == nil {
+		return nil, service.clientErr
+	}
 
 	response, err := service.userClient.CheckUser(ctx, nil, &pb.UserRequest{
 		Username: username,
@@ -49,9 +53,10 @@ func (service *RemoteUserService) GetUserDetailByUsername(ctx context.Context, u
 
 func NewRemoteUserDetailService() *RemoteUserService {
 
-	userClient, _ := client.NewUserClient("user", nil, nil)
+	userClient, err := client.NewUserClient("user", nil, nil)
 	return &RemoteUserService{
 		userClient: userClient,
+		clientErr:  err,
 	}
 }
 
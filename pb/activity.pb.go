@@ -0,0 +1,968 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: activity.proto
+
+package pb
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type Activity struct {
+	ActivityId           int64    `protobuf:"varint,1,opt,name=ActivityId,proto3" json:"ActivityId,omitempty"`
+	ActivityName         string   `protobuf:"bytes,2,opt,name=ActivityName,proto3" json:"ActivityName,omitempty"`
+	ProductId            int64    `protobuf:"varint,3,opt,name=ProductId,proto3" json:"ProductId,omitempty"`
+	StartTime            int64    `protobuf:"varint,4,opt,name=StartTime,proto3" json:"StartTime,omitempty"`
+	EndTime              int64    `protobuf:"varint,5,opt,name=EndTime,proto3" json:"EndTime,omitempty"`
+	Total                int64    `protobuf:"varint,6,opt,name=Total,proto3" json:"Total,omitempty"`
+	Status               int64    `protobuf:"varint,7,opt,name=Status,proto3" json:"Status,omitempty"`
+	StartTimeStr         string   `protobuf:"bytes,8,opt,name=StartTimeStr,proto3" json:"StartTimeStr,omitempty"`
+	EndTimeStr           string   `protobuf:"bytes,9,opt,name=EndTimeStr,proto3" json:"EndTimeStr,omitempty"`
+	StatusStr            string   `protobuf:"bytes,10,opt,name=StatusStr,proto3" json:"StatusStr,omitempty"`
+	Speed                int64    `protobuf:"varint,11,opt,name=Speed,proto3" json:"Speed,omitempty"`
+	BuyLimit             int64    `protobuf:"varint,12,opt,name=BuyLimit,proto3" json:"BuyLimit,omitempty"`
+	BuyRate              float64  `protobuf:"fixed64,13,opt,name=BuyRate,proto3" json:"BuyRate,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Activity) Reset()         { *m = Activity{} }
+func (m *Activity) String() string { return proto.CompactTextString(m) }
+func (*Activity) ProtoMessage()    {}
+func (*Activity) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a684c9a0549e7832, []int{0}
+}
+
+func (m *Activity) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Activity.Unmarshal(m, b)
+}
+func (m *Activity) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Activity.Marshal(b, m, deterministic)
+}
+func (m *Activity) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Activity.Merge(m, src)
+}
+func (m *Activity) XXX_Size() int {
+	return xxx_messageInfo_Activity.Size(m)
+}
+func (m *Activity) XXX_DiscardUnknown() {
+	xxx_messageInfo_Activity.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Activity proto.InternalMessageInfo
+
+func (m *Activity) GetActivityId() int64 {
+	if m != nil {
+		return m.ActivityId
+	}
+	return 0
+}
+
+func (m *Activity) GetActivityName() string {
+	if m != nil {
+		return m.ActivityName
+	}
+	return ""
+}
+
+func (m *Activity) GetProductId() int64 {
+	if m != nil {
+		return m.ProductId
+	}
+	return 0
+}
+
+func (m *Activity) GetStartTime() int64 {
+	if m != nil {
+		return m.StartTime
+	}
+	return 0
+}
+
+func (m *Activity) GetEndTime() int64 {
+	if m != nil {
+		return m.EndTime
+	}
+	return 0
+}
+
+func (m *Activity) GetTotal() int64 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+func (m *Activity) GetStatus() int64 {
+	if m != nil {
+		return m.Status
+	}
+	return 0
+}
+
+func (m *Activity) GetStartTimeStr() string {
+	if m != nil {
+		return m.StartTimeStr
+	}
+	return ""
+}
+
+func (m *Activity) GetEndTimeStr() string {
+	if m != nil {
+		return m.EndTimeStr
+	}
+	return ""
+}
+
+func (m *Activity) GetStatusStr() string {
+	if m != nil {
+		return m.StatusStr
+	}
+	return ""
+}
+
+func (m *Activity) GetSpeed() int64 {
+	if m != nil {
+		return m.Speed
+	}
+	return 0
+}
+
+func (m *Activity) GetBuyLimit() int64 {
+	if m != nil {
+		return m.BuyLimit
+	}
+	return 0
+}
+
+func (m *Activity) GetBuyRate() float64 {
+	if m != nil {
+		return m.BuyRate
+	}
+	return 0
+}
+
+type SecProductInfoConf struct {
+	ProductId            int64    `protobuf:"varint,1,opt,name=ProductId,proto3" json:"ProductId,omitempty"`
+	StartTime            int64    `protobuf:"varint,2,opt,name=StartTime,proto3" json:"StartTime,omitempty"`
+	EndTime              int64    `protobuf:"varint,3,opt,name=EndTime,proto3" json:"EndTime,omitempty"`
+	Status               int64    `protobuf:"varint,4,opt,name=Status,proto3" json:"Status,omitempty"`
+	Total                int64    `protobuf:"varint,5,opt,name=Total,proto3" json:"Total,omitempty"`
+	Left                 int64    `protobuf:"varint,6,opt,name=Left,proto3" json:"Left,omitempty"`
+	OnePersonBuyLimit    int64    `protobuf:"varint,7,opt,name=OnePersonBuyLimit,proto3" json:"OnePersonBuyLimit,omitempty"`
+	BuyRate              float64  `protobuf:"fixed64,8,opt,name=BuyRate,proto3" json:"BuyRate,omitempty"`
+	SoldMaxLimit         int64    `protobuf:"varint,9,opt,name=SoldMaxLimit,proto3" json:"SoldMaxLimit,omitempty"`
+	ActivityId           int64    `protobuf:"varint,10,opt,name=ActivityId,proto3" json:"ActivityId,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SecProductInfoConf) Reset()         { *m = SecProductInfoConf{} }
+func (m *SecProductInfoConf) String() string { return proto.CompactTextString(m) }
+func (*SecProductInfoConf) ProtoMessage()    {}
+func (*SecProductInfoConf) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a684c9a0549e7832, []int{1}
+}
+
+func (m *SecProductInfoConf) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SecProductInfoConf.Unmarshal(m, b)
+}
+func (m *SecProductInfoConf) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SecProductInfoConf.Marshal(b, m, deterministic)
+}
+func (m *SecProductInfoConf) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SecProductInfoConf.Merge(m, src)
+}
+func (m *SecProductInfoConf) XXX_Size() int {
+	return xxx_messageInfo_SecProductInfoConf.Size(m)
+}
+func (m *SecProductInfoConf) XXX_DiscardUnknown() {
+	xxx_messageInfo_SecProductInfoConf.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SecProductInfoConf proto.InternalMessageInfo
+
+func (m *SecProductInfoConf) GetProductId() int64 {
+	if m != nil {
+		return m.ProductId
+	}
+	return 0
+}
+
+func (m *SecProductInfoConf) GetStartTime() int64 {
+	if m != nil {
+		return m.StartTime
+	}
+	return 0
+}
+
+func (m *SecProductInfoConf) GetEndTime() int64 {
+	if m != nil {
+		return m.EndTime
+	}
+	return 0
+}
+
+func (m *SecProductInfoConf) GetStatus() int64 {
+	if m != nil {
+		return m.Status
+	}
+	return 0
+}
+
+func (m *SecProductInfoConf) GetTotal() int64 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+func (m *SecProductInfoConf) GetLeft() int64 {
+	if m != nil {
+		return m.Left
+	}
+	return 0
+}
+
+func (m *SecProductInfoConf) GetOnePersonBuyLimit() int64 {
+	if m != nil {
+		return m.OnePersonBuyLimit
+	}
+	return 0
+}
+
+func (m *SecProductInfoConf) GetBuyRate() float64 {
+	if m != nil {
+		return m.BuyRate
+	}
+	return 0
+}
+
+func (m *SecProductInfoConf) GetSoldMaxLimit() int64 {
+	if m != nil {
+		return m.SoldMaxLimit
+	}
+	return 0
+}
+
+func (m *SecProductInfoConf) GetActivityId() int64 {
+	if m != nil {
+		return m.ActivityId
+	}
+	return 0
+}
+
+type ListActivitiesRequest struct {
+	Status               []int64  `protobuf:"varint,1,rep,packed,name=Status,proto3" json:"Status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListActivitiesRequest) Reset()         { *m = ListActivitiesRequest{} }
+func (m *ListActivitiesRequest) String() string { return proto.CompactTextString(m) }
+func (*ListActivitiesRequest) ProtoMessage()    {}
+func (*ListActivitiesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a684c9a0549e7832, []int{2}
+}
+
+func (m *ListActivitiesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListActivitiesRequest.Unmarshal(m, b)
+}
+func (m *ListActivitiesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListActivitiesRequest.Marshal(b, m, deterministic)
+}
+func (m *ListActivitiesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListActivitiesRequest.Merge(m, src)
+}
+func (m *ListActivitiesRequest) XXX_Size() int {
+	return xxx_messageInfo_ListActivitiesRequest.Size(m)
+}
+func (m *ListActivitiesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListActivitiesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListActivitiesRequest proto.InternalMessageInfo
+
+func (m *ListActivitiesRequest) GetStatus() []int64 {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type ListActivitiesResponse struct {
+	Activities           []*Activity `protobuf:"bytes,1,rep,name=Activities,proto3" json:"Activities,omitempty"`
+	Err                  string      `protobuf:"bytes,2,opt,name=Err,proto3" json:"Err,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *ListActivitiesResponse) Reset()         { *m = ListActivitiesResponse{} }
+func (m *ListActivitiesResponse) String() string { return proto.CompactTextString(m) }
+func (*ListActivitiesResponse) ProtoMessage()    {}
+func (*ListActivitiesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a684c9a0549e7832, []int{3}
+}
+
+func (m *ListActivitiesResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListActivitiesResponse.Unmarshal(m, b)
+}
+func (m *ListActivitiesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListActivitiesResponse.Marshal(b, m, deterministic)
+}
+func (m *ListActivitiesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListActivitiesResponse.Merge(m, src)
+}
+func (m *ListActivitiesResponse) XXX_Size() int {
+	return xxx_messageInfo_ListActivitiesResponse.Size(m)
+}
+func (m *ListActivitiesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListActivitiesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListActivitiesResponse proto.InternalMessageInfo
+
+func (m *ListActivitiesResponse) GetActivities() []*Activity {
+	if m != nil {
+		return m.Activities
+	}
+	return nil
+}
+
+func (m *ListActivitiesResponse) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type GetActivityRequest struct {
+	ActivityId           int64    `protobuf:"varint,1,opt,name=ActivityId,proto3" json:"ActivityId,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetActivityRequest) Reset()         { *m = GetActivityRequest{} }
+func (m *GetActivityRequest) String() string { return proto.CompactTextString(m) }
+func (*GetActivityRequest) ProtoMessage()    {}
+func (*GetActivityRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a684c9a0549e7832, []int{4}
+}
+
+func (m *GetActivityRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetActivityRequest.Unmarshal(m, b)
+}
+func (m *GetActivityRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetActivityRequest.Marshal(b, m, deterministic)
+}
+func (m *GetActivityRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetActivityRequest.Merge(m, src)
+}
+func (m *GetActivityRequest) XXX_Size() int {
+	return xxx_messageInfo_GetActivityRequest.Size(m)
+}
+func (m *GetActivityRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetActivityRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetActivityRequest proto.InternalMessageInfo
+
+func (m *GetActivityRequest) GetActivityId() int64 {
+	if m != nil {
+		return m.ActivityId
+	}
+	return 0
+}
+
+type ActivityResponse struct {
+	Activity             *Activity `protobuf:"bytes,1,opt,name=Activity,proto3" json:"Activity,omitempty"`
+	Err                  string    `protobuf:"bytes,2,opt,name=Err,proto3" json:"Err,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *ActivityResponse) Reset()         { *m = ActivityResponse{} }
+func (m *ActivityResponse) String() string { return proto.CompactTextString(m) }
+func (*ActivityResponse) ProtoMessage()    {}
+func (*ActivityResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a684c9a0549e7832, []int{5}
+}
+
+func (m *ActivityResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ActivityResponse.Unmarshal(m, b)
+}
+func (m *ActivityResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ActivityResponse.Marshal(b, m, deterministic)
+}
+func (m *ActivityResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ActivityResponse.Merge(m, src)
+}
+func (m *ActivityResponse) XXX_Size() int {
+	return xxx_messageInfo_ActivityResponse.Size(m)
+}
+func (m *ActivityResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ActivityResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ActivityResponse proto.InternalMessageInfo
+
+func (m *ActivityResponse) GetActivity() *Activity {
+	if m != nil {
+		return m.Activity
+	}
+	return nil
+}
+
+func (m *ActivityResponse) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type ListProductConfigsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListProductConfigsRequest) Reset()         { *m = ListProductConfigsRequest{} }
+func (m *ListProductConfigsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListProductConfigsRequest) ProtoMessage()    {}
+func (*ListProductConfigsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a684c9a0549e7832, []int{6}
+}
+
+func (m *ListProductConfigsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListProductConfigsRequest.Unmarshal(m, b)
+}
+func (m *ListProductConfigsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListProductConfigsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListProductConfigsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListProductConfigsRequest.Merge(m, src)
+}
+func (m *ListProductConfigsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListProductConfigsRequest.Size(m)
+}
+func (m *ListProductConfigsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListProductConfigsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListProductConfigsRequest proto.InternalMessageInfo
+
+type ListProductConfigsResponse struct {
+	Configs              []*SecProductInfoConf `protobuf:"bytes,1,rep,name=Configs,proto3" json:"Configs,omitempty"`
+	Err                  string                `protobuf:"bytes,2,opt,name=Err,proto3" json:"Err,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *ListProductConfigsResponse) Reset()         { *m = ListProductConfigsResponse{} }
+func (m *ListProductConfigsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListProductConfigsResponse) ProtoMessage()    {}
+func (*ListProductConfigsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a684c9a0549e7832, []int{7}
+}
+
+func (m *ListProductConfigsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListProductConfigsResponse.Unmarshal(m, b)
+}
+func (m *ListProductConfigsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListProductConfigsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListProductConfigsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListProductConfigsResponse.Merge(m, src)
+}
+func (m *ListProductConfigsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListProductConfigsResponse.Size(m)
+}
+func (m *ListProductConfigsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListProductConfigsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListProductConfigsResponse proto.InternalMessageInfo
+
+func (m *ListProductConfigsResponse) GetConfigs() []*SecProductInfoConf {
+	if m != nil {
+		return m.Configs
+	}
+	return nil
+}
+
+func (m *ListProductConfigsResponse) GetErr() string {
+	if m != nil {
+		return m.Err
+	}
+	return ""
+}
+
+type WatchProductConfigRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WatchProductConfigRequest) Reset()         { *m = WatchProductConfigRequest{} }
+func (m *WatchProductConfigRequest) String() string { return proto.CompactTextString(m) }
+func (*WatchProductConfigRequest) ProtoMessage()    {}
+func (*WatchProductConfigRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a684c9a0549e7832, []int{8}
+}
+
+func (m *WatchProductConfigRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WatchProductConfigRequest.Unmarshal(m, b)
+}
+func (m *WatchProductConfigRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WatchProductConfigRequest.Marshal(b, m, deterministic)
+}
+func (m *WatchProductConfigRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WatchProductConfigRequest.Merge(m, src)
+}
+func (m *WatchProductConfigRequest) XXX_Size() int {
+	return xxx_messageInfo_WatchProductConfigRequest.Size(m)
+}
+func (m *WatchProductConfigRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WatchProductConfigRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WatchProductConfigRequest proto.InternalMessageInfo
+
+type ProductConfigEvent struct {
+	Version              int64                 `protobuf:"varint,1,opt,name=Version,proto3" json:"Version,omitempty"`
+	Full                 bool                  `protobuf:"varint,2,opt,name=Full,proto3" json:"Full,omitempty"`
+	Configs              []*SecProductInfoConf `protobuf:"bytes,3,rep,name=Configs,proto3" json:"Configs,omitempty"`
+	Removed              []int64               `protobuf:"varint,4,rep,packed,name=Removed,proto3" json:"Removed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *ProductConfigEvent) Reset()         { *m = ProductConfigEvent{} }
+func (m *ProductConfigEvent) String() string { return proto.CompactTextString(m) }
+func (*ProductConfigEvent) ProtoMessage()    {}
+func (*ProductConfigEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a684c9a0549e7832, []int{9}
+}
+
+func (m *ProductConfigEvent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ProductConfigEvent.Unmarshal(m, b)
+}
+func (m *ProductConfigEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ProductConfigEvent.Marshal(b, m, deterministic)
+}
+func (m *ProductConfigEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProductConfigEvent.Merge(m, src)
+}
+func (m *ProductConfigEvent) XXX_Size() int {
+	return xxx_messageInfo_ProductConfigEvent.Size(m)
+}
+func (m *ProductConfigEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProductConfigEvent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ProductConfigEvent proto.InternalMessageInfo
+
+func (m *ProductConfigEvent) GetVersion() int64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *ProductConfigEvent) GetFull() bool {
+	if m != nil {
+		return m.Full
+	}
+	return false
+}
+
+func (m *ProductConfigEvent) GetConfigs() []*SecProductInfoConf {
+	if m != nil {
+		return m.Configs
+	}
+	return nil
+}
+
+func (m *ProductConfigEvent) GetRemoved() []int64 {
+	if m != nil {
+		return m.Removed
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*Activity)(nil), "pb.Activity")
+	proto.RegisterType((*SecProductInfoConf)(nil), "pb.SecProductInfoConf")
+	proto.RegisterType((*ListActivitiesRequest)(nil), "pb.ListActivitiesRequest")
+	proto.RegisterType((*ListActivitiesResponse)(nil), "pb.ListActivitiesResponse")
+	proto.RegisterType((*GetActivityRequest)(nil), "pb.GetActivityRequest")
+	proto.RegisterType((*ActivityResponse)(nil), "pb.ActivityResponse")
+	proto.RegisterType((*ListProductConfigsRequest)(nil), "pb.ListProductConfigsRequest")
+	proto.RegisterType((*ListProductConfigsResponse)(nil), "pb.ListProductConfigsResponse")
+	proto.RegisterType((*WatchProductConfigRequest)(nil), "pb.WatchProductConfigRequest")
+	proto.RegisterType((*ProductConfigEvent)(nil), "pb.ProductConfigEvent")
+}
+
+func init() { proto.RegisterFile("activity.proto", fileDescriptor_a684c9a0549e7832) }
+
+var fileDescriptor_a684c9a0549e7832 = []byte{
+	// 627 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x55, 0xcb, 0x6e, 0xd3, 0x40,
+	0x14, 0xad, 0xe3, 0xb4, 0x49, 0x6e, 0x4b, 0x28, 0xa3, 0x12, 0x4d, 0x0d, 0x54, 0x91, 0x57, 0x59,
+	0x54, 0xa1, 0x2a, 0x88, 0x1d, 0x0b, 0x5a, 0x05, 0x14, 0x29, 0xb4, 0x95, 0xdd, 0x02, 0x4b, 0x9c,
+	0xf8, 0x06, 0x2c, 0x25, 0xb6, 0xb1, 0xc7, 0x11, 0xd9, 0xf1, 0x05, 0xfc, 0x0c, 0x1b, 0x3e, 0x0f,
+	0xcd, 0x78, 0xc6, 0x8f, 0xd8, 0x0d, 0xb0, 0x9b, 0x7b, 0xce, 0xdc, 0xd7, 0xf1, 0x19, 0x19, 0xba,
+	0xce, 0x8c, 0x79, 0x2b, 0x8f, 0xad, 0x87, 0x61, 0x14, 0xb0, 0x80, 0x34, 0xc2, 0xa9, 0xf9, 0x43,
+	0x87, 0xf6, 0x1b, 0x09, 0x93, 0x13, 0x00, 0x75, 0x1e, 0xbb, 0x54, 0xeb, 0x6b, 0x03, 0xdd, 0x2a,
+	0x20, 0xc4, 0x84, 0x03, 0x15, 0x5d, 0x39, 0x4b, 0xa4, 0x8d, 0xbe, 0x36, 0xe8, 0x58, 0x25, 0x8c,
+	0x3c, 0x85, 0xce, 0x4d, 0x14, 0xb8, 0xc9, 0x8c, 0x8d, 0x5d, 0xaa, 0x8b, 0x12, 0x39, 0xc0, 0x59,
+	0x9b, 0x39, 0x11, 0xbb, 0xf5, 0x96, 0x48, 0x9b, 0x29, 0x9b, 0x01, 0x84, 0x42, 0x6b, 0xe4, 0xbb,
+	0x82, 0xdb, 0x15, 0x9c, 0x0a, 0xc9, 0x11, 0xec, 0xde, 0x06, 0xcc, 0x59, 0xd0, 0x3d, 0x81, 0xa7,
+	0x01, 0xe9, 0xc1, 0x9e, 0xcd, 0x1c, 0x96, 0xc4, 0xb4, 0x25, 0x60, 0x19, 0xf1, 0x39, 0xb3, 0xa2,
+	0x36, 0x8b, 0x68, 0x3b, 0x9d, 0xb3, 0x88, 0xf1, 0x5d, 0x65, 0x71, 0x7e, 0xa3, 0x23, 0x6e, 0x14,
+	0x10, 0x39, 0x29, 0x4b, 0x62, 0x4e, 0x83, 0xa0, 0x73, 0x80, 0xcf, 0x63, 0x87, 0x88, 0x2e, 0xdd,
+	0x4f, 0xe7, 0x11, 0x01, 0x31, 0xa0, 0x7d, 0x91, 0xac, 0x27, 0xde, 0xd2, 0x63, 0xf4, 0x40, 0x10,
+	0x59, 0xcc, 0x77, 0xbb, 0x48, 0xd6, 0x96, 0xc3, 0x90, 0x3e, 0xe8, 0x6b, 0x03, 0xcd, 0x52, 0xa1,
+	0xf9, 0xbb, 0x01, 0xc4, 0xc6, 0x99, 0x12, 0xc9, 0x9f, 0x07, 0x97, 0x81, 0x3f, 0x2f, 0x0b, 0xa9,
+	0x6d, 0x15, 0xb2, 0xb1, 0x45, 0x48, 0xbd, 0x2c, 0x64, 0x2e, 0x59, 0xb3, 0x24, 0x59, 0x26, 0xf0,
+	0x6e, 0x51, 0x60, 0x02, 0xcd, 0x09, 0xce, 0x99, 0x54, 0x5d, 0x9c, 0xc9, 0x29, 0x3c, 0xba, 0xf6,
+	0xf1, 0x06, 0xa3, 0x38, 0xf0, 0xb3, 0x6d, 0x53, 0xfd, 0xab, 0x44, 0x71, 0xed, 0x76, 0x69, 0x6d,
+	0xf1, 0x91, 0x82, 0x85, 0xfb, 0xde, 0xf9, 0x9e, 0x96, 0xe8, 0x88, 0x12, 0x25, 0x6c, 0xc3, 0x90,
+	0xb0, 0x69, 0x48, 0xf3, 0x39, 0x3c, 0x9e, 0x78, 0x31, 0x93, 0x88, 0x87, 0xb1, 0x85, 0xdf, 0x12,
+	0x8c, 0x59, 0x61, 0x4d, 0xad, 0xaf, 0xe7, 0x6b, 0x9a, 0x9f, 0xa0, 0xb7, 0x99, 0x10, 0x87, 0x81,
+	0x1f, 0x23, 0x39, 0xcd, 0x5a, 0x79, 0x98, 0x66, 0xed, 0x9f, 0x1f, 0x0c, 0xc3, 0xe9, 0x50, 0xb5,
+	0xb3, 0x0a, 0x3c, 0x39, 0x04, 0x7d, 0x14, 0x45, 0xf2, 0x01, 0xf0, 0xa3, 0xf9, 0x12, 0xc8, 0x3b,
+	0x64, 0xd9, 0x65, 0x39, 0xc7, 0x5f, 0x5e, 0x94, 0x79, 0x05, 0x87, 0x79, 0x8a, 0x9c, 0x64, 0x90,
+	0xbf, 0x48, 0x91, 0xb1, 0x39, 0x47, 0xc6, 0xd6, 0x4c, 0xf1, 0x04, 0x8e, 0xf9, 0x7e, 0xd2, 0x27,
+	0xdc, 0x47, 0xde, 0x17, 0x25, 0x8a, 0xf9, 0x19, 0x8c, 0x3a, 0x52, 0xb6, 0x3d, 0x83, 0x96, 0x84,
+	0xe4, 0xf6, 0x3d, 0xde, 0xb5, 0x6a, 0x4c, 0x4b, 0x5d, 0xab, 0x6f, 0xff, 0xd1, 0x61, 0xb3, 0xaf,
+	0xa5, 0x16, 0xaa, 0xfd, 0x4f, 0x0d, 0x48, 0x89, 0x18, 0xad, 0xd0, 0x17, 0x0e, 0xf9, 0x80, 0x51,
+	0xec, 0x05, 0xbe, 0xd4, 0x47, 0x85, 0xdc, 0x7d, 0x6f, 0x93, 0xc5, 0x42, 0x34, 0x68, 0x5b, 0xe2,
+	0x5c, 0x9c, 0x52, 0xff, 0xb7, 0x29, 0x29, 0xb4, 0x2c, 0x5c, 0x06, 0x2b, 0x74, 0x69, 0x53, 0x78,
+	0x41, 0x85, 0xe7, 0xbf, 0x74, 0x78, 0xa8, 0xb4, 0xb4, 0x31, 0x5a, 0x79, 0x33, 0x24, 0x63, 0xe8,
+	0x96, 0x0d, 0x42, 0x8e, 0x79, 0x83, 0x5a, 0x97, 0x19, 0x46, 0x1d, 0x95, 0xca, 0x69, 0xee, 0x90,
+	0xd7, 0xb0, 0x5f, 0x70, 0x04, 0x11, 0x83, 0x56, 0x2d, 0x62, 0x1c, 0x95, 0x3e, 0x6e, 0x9e, 0xfe,
+	0x0a, 0xba, 0x97, 0x11, 0x3a, 0x0c, 0xb3, 0x0a, 0x25, 0x1b, 0x6c, 0xcb, 0xbb, 0x0b, 0xdd, 0xff,
+	0xcf, 0xbb, 0x03, 0x52, 0x75, 0x07, 0x79, 0xa6, 0x56, 0xac, 0xb5, 0x94, 0x71, 0x72, 0x1f, 0x9d,
+	0x95, 0xbd, 0x06, 0x52, 0xb5, 0x44, 0x5a, 0xf6, 0x5e, 0xab, 0x18, 0x42, 0xab, 0xaa, 0x57, 0xcc,
+	0x9d, 0x33, 0x6d, 0xba, 0x27, 0x7e, 0x5e, 0x2f, 0xfe, 0x0c, 0x00, 0x91, 0xed, 0xb9, 0x52, 0xce,
+	0x06, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// ActivityServiceClient is the client API for ActivityService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type ActivityServiceClient interface {
+	// 查询活动列表, Status 为空时返回全部活动
+	ListActivities(ctx context.Context, in *ListActivitiesRequest, opts ...grpc.CallOption) (*ListActivitiesResponse, error)
+	GetActivity(ctx context.Context, in *GetActivityRequest, opts ...grpc.CallOption) (*ActivityResponse, error)
+	CreateActivity(ctx context.Context, in *Activity, opts ...grpc.CallOption) (*ActivityResponse, error)
+	UpdateActivity(ctx context.Context, in *Activity, opts ...grpc.CallOption) (*ActivityResponse, error)
+	// 查询当前需要发布给 sk-app/sk-core 的商品配置
+	ListProductConfigs(ctx context.Context, in *ListProductConfigsRequest, opts ...grpc.CallOption) (*ListProductConfigsResponse, error)
+	// 订阅商品配置, 先推送全量配置, 之后推送带版本号的增量变更
+	WatchProductConfig(ctx context.Context, in *WatchProductConfigRequest, opts ...grpc.CallOption) (ActivityService_WatchProductConfigClient, error)
+}
+
+type activityServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewActivityServiceClient(cc *grpc.ClientConn) ActivityServiceClient {
+	return &activityServiceClient{cc}
+}
+
+func (c *activityServiceClient) ListActivities(ctx context.Context, in *ListActivitiesRequest, opts ...grpc.CallOption) (*ListActivitiesResponse, error) {
+	out := new(ListActivitiesResponse)
+	err := c.cc.Invoke(ctx, "/pb.ActivityService/ListActivities", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *activityServiceClient) GetActivity(ctx context.Context, in *GetActivityRequest, opts ...grpc.CallOption) (*ActivityResponse, error) {
+	out := new(ActivityResponse)
+	err := c.cc.Invoke(ctx, "/pb.ActivityService/GetActivity", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *activityServiceClient) CreateActivity(ctx context.Context, in *Activity, opts ...grpc.CallOption) (*ActivityResponse, error) {
+	out := new(ActivityResponse)
+	err := c.cc.Invoke(ctx, "/pb.ActivityService/CreateActivity", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *activityServiceClient) UpdateActivity(ctx context.Context, in *Activity, opts ...grpc.CallOption) (*ActivityResponse, error) {
+	out := new(ActivityResponse)
+	err := c.cc.Invoke(ctx, "/pb.ActivityService/UpdateActivity", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *activityServiceClient) ListProductConfigs(ctx context.Context, in *ListProductConfigsRequest, opts ...grpc.CallOption) (*ListProductConfigsResponse, error) {
+	out := new(ListProductConfigsResponse)
+	err := c.cc.Invoke(ctx, "/pb.ActivityService/ListProductConfigs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *activityServiceClient) WatchProductConfig(ctx context.Context, in *WatchProductConfigRequest, opts ...grpc.CallOption) (ActivityService_WatchProductConfigClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ActivityService_serviceDesc.Streams[0], "/pb.ActivityService/WatchProductConfig", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &activityServiceWatchProductConfigClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ActivityService_WatchProductConfigClient interface {
+	Recv() (*ProductConfigEvent, error)
+	grpc.ClientStream
+}
+
+type activityServiceWatchProductConfigClient struct {
+	grpc.ClientStream
+}
+
+func (x *activityServiceWatchProductConfigClient) Recv() (*ProductConfigEvent, error) {
+	m := new(ProductConfigEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ActivityServiceServer is the server API for ActivityService service.
+type ActivityServiceServer interface {
+	// 查询活动列表, Status 为空时返回全部活动
+	ListActivities(context.Context, *ListActivitiesRequest) (*ListActivitiesResponse, error)
+	GetActivity(context.Context, *GetActivityRequest) (*ActivityResponse, error)
+	CreateActivity(context.Context, *Activity) (*ActivityResponse, error)
+	UpdateActivity(context.Context, *Activity) (*ActivityResponse, error)
+	// 查询当前需要发布给 sk-app/sk-core 的商品配置
+	ListProductConfigs(context.Context, *ListProductConfigsRequest) (*ListProductConfigsResponse, error)
+	// 订阅商品配置, 先推送全量配置, 之后推送带版本号的增量变更
+	WatchProductConfig(*WatchProductConfigRequest, ActivityService_WatchProductConfigServer) error
+}
+
+// UnimplementedActivityServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedActivityServiceServer struct {
+}
+
+func (*UnimplementedActivityServiceServer) ListActivities(ctx context.Context, req *ListActivitiesRequest) (*ListActivitiesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListActivities not implemented")
+}
+func (*UnimplementedActivityServiceServer) GetActivity(ctx context.Context, req *GetActivityRequest) (*ActivityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetActivity not implemented")
+}
+func (*UnimplementedActivityServiceServer) CreateActivity(ctx context.Context, req *Activity) (*ActivityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateActivity not implemented")
+}
+func (*UnimplementedActivityServiceServer) UpdateActivity(ctx context.Context, req *Activity) (*ActivityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateActivity not implemented")
+}
+func (*UnimplementedActivityServiceServer) ListProductConfigs(ctx context.Context, req *ListProductConfigsRequest) (*ListProductConfigsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListProductConfigs not implemented")
+}
+func (*UnimplementedActivityServiceServer) WatchProductConfig(req *WatchProductConfigRequest, srv ActivityService_WatchProductConfigServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchProductConfig not implemented")
+}
+
+func RegisterActivityServiceServer(s *grpc.Server, srv ActivityServiceServer) {
+	s.RegisterService(&_ActivityService_serviceDesc, srv)
+}
+
+func _ActivityService_ListActivities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListActivitiesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ActivityServiceServer).ListActivities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.ActivityService/ListActivities",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ActivityServiceServer).ListActivities(ctx, req.(*ListActivitiesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ActivityService_GetActivity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetActivityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ActivityServiceServer).GetActivity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.ActivityService/GetActivity",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ActivityServiceServer).GetActivity(ctx, req.(*GetActivityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ActivityService_CreateActivity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Activity)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ActivityServiceServer).CreateActivity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.ActivityService/CreateActivity",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ActivityServiceServer).CreateActivity(ctx, req.(*Activity))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ActivityService_UpdateActivity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Activity)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ActivityServiceServer).UpdateActivity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.ActivityService/UpdateActivity",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ActivityServiceServer).UpdateActivity(ctx, req.(*Activity))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ActivityService_ListProductConfigs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProductConfigsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ActivityServiceServer).ListProductConfigs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.ActivityService/ListProductConfigs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ActivityServiceServer).ListProductConfigs(ctx, req.(*ListProductConfigsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ActivityService_WatchProductConfig_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchProductConfigRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ActivityServiceServer).WatchProductConfig(m, &activityServiceWatchProductConfigServer{stream})
+}
+
+type ActivityService_WatchProductConfigServer interface {
+	Send(*ProductConfigEvent) error
+	grpc.ServerStream
+}
+
+type activityServiceWatchProductConfigServer struct {
+	grpc.ServerStream
+}
+
+func (x *activityServiceWatchProductConfigServer) Send(m *ProductConfigEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+var _ActivityService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.ActivityService",
+	HandlerType: (*ActivityServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListActivities",
+			Handler:    _ActivityService_ListActivities_Handler,
+		},
+		{
+			MethodName: "GetActivity",
+			Handler:    _ActivityService_GetActivity_Handler,
+		},
+		{
+			MethodName: "CreateActivity",
+			Handler:    _ActivityService_CreateActivity_Handler,
+		},
+		{
+			MethodName: "UpdateActivity",
+			Handler:    _ActivityService_UpdateActivity_Handler,
+		},
+		{
+			MethodName: "ListProductConfigs",
+			Handler:    _ActivityService_ListProductConfigs_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchProductConfig",
+			Handler:       _ActivityService_WatchProductConfig_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "activity.proto",
+}
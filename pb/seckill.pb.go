@@ -4,8 +4,12 @@
 package pb
 
 import (
+	context "context"
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
 	math "math"
 )
 
@@ -210,7 +214,7 @@ func init() {
 func init() { proto.RegisterFile("seckill.proto", fileDescriptor_1202afb06d2a3a7a) }
 
 var fileDescriptor_1202afb06d2a3a7a = []byte{
-	// 313 bytes of a gzipped FileDescriptorProto
+	// 310 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x92, 0xcf, 0x4a, 0xc3, 0x40,
 	0x10, 0xc6, 0x4d, 0xda, 0xf4, 0xcf, 0x68, 0x2b, 0x0c, 0x45, 0x96, 0x22, 0x52, 0x82, 0x87, 0x1e,
 	0xa4, 0x07, 0xbd, 0x0b, 0xa5, 0xa7, 0x22, 0x88, 0x24, 0xf5, 0x01, 0xec, 0xee, 0xa8, 0x4b, 0x63,
@@ -229,6 +233,86 @@ var fileDescriptor_1202afb06d2a3a7a = []byte{
 	0x31, 0x3b, 0xca, 0x9b, 0xc0, 0x6a, 0xe1, 0x7b, 0x71, 0xb1, 0xcf, 0xab, 0x93, 0x1c, 0x00, 0x22,
 	0x74, 0x39, 0xe3, 0x88, 0x0d, 0xae, 0x6f, 0xef, 0x61, 0x9c, 0x92, 0x7c, 0xd0, 0x59, 0x96, 0x92,
 	0xfd, 0xd2, 0x92, 0xf0, 0x06, 0xfa, 0xae, 0x26, 0x38, 0x5e, 0x14, 0xdb, 0xc5, 0xe1, 0xf7, 0x4e,
-	0xcf, 0xf7, 0xba, 0xbe, 0x7b, 0x7c, 0xb2, 0xed, 0xf1, 0x5b, 0xb8, 0xfb, 0x0d, 0x00, 0x00, 0xff,
-	0xff, 0xce, 0x46, 0x57, 0x89, 0x1c, 0x02, 0x00, 0x00,
+	0xcf, 0xf7, 0xba, 0xbe, 0x7b, 0x7c, 0xb2, 0xed, 0xf1, 0x5b, 0xb8, 0xfb, 0x1d, 0x00, 0xce, 0x46,
+	0x57, 0x89, 0x1c, 0x02, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// SecKillServiceClient is the client API for SecKillService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type SecKillServiceClient interface {
+	SecKill(ctx context.Context, in *SecRequest, opts ...grpc.CallOption) (*SecResponse, error)
+}
+
+type secKillServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewSecKillServiceClient(cc *grpc.ClientConn) SecKillServiceClient {
+	return &secKillServiceClient{cc}
+}
+
+func (c *secKillServiceClient) SecKill(ctx context.Context, in *SecRequest, opts ...grpc.CallOption) (*SecResponse, error) {
+	out := new(SecResponse)
+	err := c.cc.Invoke(ctx, "/pb.SecKillService/secKill", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SecKillServiceServer is the server API for SecKillService service.
+type SecKillServiceServer interface {
+	SecKill(context.Context, *SecRequest) (*SecResponse, error)
+}
+
+// UnimplementedSecKillServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedSecKillServiceServer struct {
+}
+
+func (*UnimplementedSecKillServiceServer) SecKill(ctx context.Context, req *SecRequest) (*SecResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SecKill not implemented")
+}
+
+func RegisterSecKillServiceServer(s *grpc.Server, srv SecKillServiceServer) {
+	s.RegisterService(&_SecKillService_serviceDesc, srv)
+}
+
+func _SecKillService_SecKill_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SecRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecKillServiceServer).SecKill(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pb.SecKillService/SecKill",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecKillServiceServer).SecKill(ctx, req.(*SecRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _SecKillService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pb.SecKillService",
+	HandlerType: (*SecKillServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "secKill",
+			Handler:    _SecKillService_SecKill_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "seckill.proto",
 }
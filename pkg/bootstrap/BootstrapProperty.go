@@ -25,6 +25,10 @@ type DiscoverConf struct {
 	ServiceName string
 	Weight      int
 	InstanceId  string
+	Type        string //服务发现类型: consul(默认)、static、dns、memory
+	File        string //static: 服务实例列表文件, 环境变量 DISCOVER_STATIC 中的实例会覆盖文件中的同名服务
+	Domain      string //dns: SRV 记录的域名后缀, 查询 <服务名>.<Domain>, 如 service.consul
+	Refresh     int    //dns: 实例列表的刷新间隔(秒), 默认 10
 }
 
 // 配置中心
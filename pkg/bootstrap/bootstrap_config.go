@@ -3,11 +3,20 @@ package bootstrap
 import (
 	"fmt"
 	"log"
+	"sync"
 
 	"github.com/spf13/viper"
 )
 
-func init() {
+var loadOnce sync.Once
+
+// Load 读取 bootstrap.yaml 中的启动配置, 只在首次调用时读取
+// 各服务的 config 包在初始化时调用; 服务发现等组件在首次使用时调用, 配置文件不存在时使用空配置, 便于本地运行和测试
+func Load() {
+	loadOnce.Do(load)
+}
+
+func load() {
 	viper.AutomaticEnv()
 	initBootstrapConfig()
 	//读取yaml文件
@@ -30,6 +39,7 @@ func init() {
 		log.Fatal("Fail to parse rpc server", err)
 	}
 }
+
 func initBootstrapConfig() {
 	//设置读取的配置文件
 	viper.SetConfigName("bootstrap")
@@ -44,6 +54,10 @@ func initBootstrapConfig() {
 func subParse(key string, value interface{}) error {
 	log.Printf("配置文件的前缀为：%v", key)
 	sub := viper.Sub(key)
+	if sub == nil {
+		// 配置文件中没有该项时保留空配置
+		return nil
+	}
 	sub.AutomaticEnv()
 	sub.SetEnvPrefix(key)
 	return sub.Unmarshal(value)
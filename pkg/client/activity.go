@@ -0,0 +1,155 @@
+package client
+
+import (
+	"context"
+
+	"github.com/lixichongAAA/seckill/pb"
+	"github.com/lixichongAAA/seckill/pkg/discover"
+	"github.com/lixichongAAA/seckill/pkg/loadbalance"
+	"github.com/opentracing/opentracing-go"
+	"google.golang.org/grpc"
+)
+
+// ActivityClient 查询及维护 sk-admin 中的秒杀活动
+// sk-admin 会校验调用方的访问令牌, 调用前需通过 metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token) 携带令牌
+// 只读接口接受 sk-admin 配置的 auth.trustedClients 中的客户端签发的令牌, 其余接口需要 OPERATOR 角色
+type ActivityClient interface {
+	ListActivities(ctx context.Context, tracer opentracing.Tracer, request *pb.ListActivitiesRequest) (*pb.ListActivitiesResponse, error)
+	GetActivity(ctx context.Context, tracer opentracing.Tracer, request *pb.GetActivityRequest) (*pb.ActivityResponse, error)
+	CreateActivity(ctx context.Context, tracer opentracing.Tracer, request *pb.Activity) (*pb.ActivityResponse, error)
+	UpdateActivity(ctx context.Context, tracer opentracing.Tracer, request *pb.Activity) (*pb.ActivityResponse, error)
+	ListProductConfigs(ctx context.Context, tracer opentracing.Tracer, request *pb.ListProductConfigsRequest) (*pb.ListProductConfigsResponse, error)
+	// WatchProductConfig 订阅商品配置, 无需携带令牌
+	WatchProductConfig(ctx context.Context, tracer opentracing.Tracer, request *pb.WatchProductConfigRequest) (ProductConfigStream, error)
+}
+
+// ProductConfigStream 商品配置订阅流, 第一个事件为全量配置, 使用结束后需调用 Close 释放连接
+type ProductConfigStream interface {
+	Recv() (*pb.ProductConfigEvent, error)
+	Close() error
+}
+
+type productConfigStream struct {
+	pb.ActivityService_WatchProductConfigClient
+	conn   *grpc.ClientConn
+	cancel context.CancelFunc
+}
+
+func (s *productConfigStream) Close() error {
+	s.cancel()
+	return s.conn.Close()
+}
+
+const (
+	activityListPath      = "/pb.ActivityService/ListActivities"
+	activityGetPath       = "/pb.ActivityService/GetActivity"
+	activityCreatePath    = "/pb.ActivityService/CreateActivity"
+	activityUpdatePath    = "/pb.ActivityService/UpdateActivity"
+	productConfigListPath = "/pb.ActivityService/ListProductConfigs"
+)
+
+// activityRetryMethods 只重试只读的方法, 创建和修改活动超时后服务端可能已处理, 重试会重复创建
+var activityRetryMethods = map[string]bool{
+	activityListPath:      true,
+	activityGetPath:       true,
+	productConfigListPath: true,
+}
+
+type ActivityClientImpl struct {
+	manager     ClientManager           // 客户端管理器
+	serviceName string                  // 服务名称
+	loadBalance loadbalance.LoadBalance // 负载均衡策略
+	tracer      opentracing.Tracer      // 链路追踪系统
+}
+
+func (impl *ActivityClientImpl) ListActivities(ctx context.Context, tracer opentracing.Tracer, request *pb.ListActivitiesRequest) (*pb.ListActivitiesResponse, error) {
+	response := new(pb.ListActivitiesResponse)
+	if err := impl.manager.DecoratorInvoke(activityListPath, "activity_list", tracer, ctx, request, response); err == nil {
+		return response, nil
+	} else {
+		return nil, err
+	}
+}
+
+func (impl *ActivityClientImpl) GetActivity(ctx context.Context, tracer opentracing.Tracer, request *pb.GetActivityRequest) (*pb.ActivityResponse, error) {
+	response := new(pb.ActivityResponse)
+	if err := impl.manager.DecoratorInvoke(activityGetPath, "activity_get", tracer, ctx, request, response); err == nil {
+		return response, nil
+	} else {
+		return nil, err
+	}
+}
+
+func (impl *ActivityClientImpl) CreateActivity(ctx context.Context, tracer opentracing.Tracer, request *pb.Activity) (*pb.ActivityResponse, error) {
+	response := new(pb.ActivityResponse)
+	if err := impl.manager.DecoratorInvoke(activityCreatePath, "activity_create", tracer, ctx, request, response); err == nil {
+		return response, nil
+	} else {
+		return nil, err
+	}
+}
+
+func (impl *ActivityClientImpl) UpdateActivity(ctx context.Context, tracer opentracing.Tracer, request *pb.Activity) (*pb.ActivityResponse, error) {
+	response := new(pb.ActivityResponse)
+	if err := impl.manager.DecoratorInvoke(activityUpdatePath, "activity_update", tracer, ctx, request, response); err == nil {
+		return response, nil
+	} else {
+		return nil, err
+	}
+}
+
+func (impl *ActivityClientImpl) ListProductConfigs(ctx context.Context, tracer opentracing.Tracer, request *pb.ListProductConfigsRequest) (*pb.ListProductConfigsResponse, error) {
+	response := new(pb.ListProductConfigsResponse)
+	if err := impl.manager.DecoratorInvoke(productConfigListPath, "product_config_list", tracer, ctx, request, response); err == nil {
+		return response, nil
+	} else {
+		return nil, err
+	}
+}
+
+func (impl *ActivityClientImpl) WatchProductConfig(ctx context.Context, tracer opentracing.Tracer, request *pb.WatchProductConfigRequest) (ProductConfigStream, error) {
+	conn, err := impl.manager.Dial(tracer)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := pb.NewActivityServiceClient(conn).WatchProductConfig(ctx, request)
+	if err != nil {
+		cancel()
+		conn.Close()
+		return nil, err
+	}
+	return &productConfigStream{
+		ActivityService_WatchProductConfigClient: stream,
+		conn:                                     conn,
+		cancel:                                   cancel,
+	}, nil
+}
+
+func NewActivityClient(serviceName string, lb loadbalance.LoadBalance, tracer opentracing.Tracer) (ActivityClient, error) {
+	if serviceName == "" {
+		serviceName = "sk-admin"
+	}
+	if lb == nil {
+		lb = configuredLoadBalance(serviceName)
+	}
+	discoveryClient, err := discover.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ActivityClientImpl{
+		manager: &DefaultClientManager{
+			serviceName:     serviceName,
+			loadBalance:     lb,
+			discoveryClient: discoveryClient,
+			logger:          discover.Logger,
+			retryPolicy:     configuredRetryPolicy(serviceName),
+			retryMethods:    activityRetryMethods,
+		},
+		serviceName: serviceName,
+		loadBalance: lb,
+		tracer:      tracer,
+	}, nil
+
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	"github.com/grpc-ecosystem/grpc-opentracing/go/otgrpc"
+	"github.com/lixichongAAA/seckill/pkg/common"
+	"github.com/opentracing/opentracing-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+// defaultConnPool 所有客户端共用的连接池, 同一实例只保持一个连接
+var defaultConnPool = NewConnPool()
+
+// ConnPool 按服务实例的 host:port 缓存 gRPC 连接, 同一实例的请求复用连接(gRPC 连接支持多路复用)
+// 服务发现不再返回某个实例时关闭其连接; 连接失败或已关闭时重新建立
+type ConnPool struct {
+	lock      sync.Mutex
+	conns     map[string]*pooledConn               //实例地址 -> 连接
+	instances map[string][]*common.ServiceInstance //服务名 -> 上次同步时的实例列表
+	dialOpts  []grpc.DialOption
+}
+
+type pooledConn struct {
+	conn    *grpc.ClientConn
+	service string
+}
+
+// NewConnPool dialOpts 会追加到默认的连接参数之后
+func NewConnPool(dialOpts ...grpc.DialOption) *ConnPool {
+	return &ConnPool{
+		conns:     make(map[string]*pooledConn),
+		instances: make(map[string][]*common.ServiceInstance),
+		dialOpts:  dialOpts,
+	}
+}
+
+// Get 返回到实例的连接, 没有可用连接时新建; 新建连接不阻塞, 连接过程受调用的 ctx 超时控制
+func (p *ConnPool) Get(service string, instance *common.ServiceInstance) (*grpc.ClientConn, error) {
+	addr := instanceKey(instance)
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if pooled, ok := p.conns[addr]; ok {
+		switch pooled.conn.GetState() {
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			// 连接失败时关闭后重建, 不等待 gRPC 内部的重连退避
+			pooled.conn.Close()
+			delete(p.conns, addr)
+		default:
+			return pooled.conn, nil
+		}
+	}
+
+	opts := append([]grpc.DialOption{
+		grpc.WithInsecure(),
+		// grpc 的一元拦截器, 追踪对象从每次调用的 ctx 中获取
+		grpc.WithUnaryInterceptor(tracingInterceptor),
+	}, p.dialOpts...)
+	conn, err := grpc.Dial(addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	p.conns[addr] = &pooledConn{
+		conn:    conn,
+		service: service,
+	}
+	return conn, nil
+}
+
+// Sync 同步服务的实例列表, 关闭已下线实例的连接; 服务发现在实例变化时会替换整个列表, 列表未变时直接返回
+func (p *ConnPool) Sync(service string, instances []*common.ServiceInstance) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if last, ok := p.instances[service]; ok && sameInstances(last, instances) {
+		return
+	}
+	p.instances[service] = instances
+
+	online := make(map[string]bool, len(instances))
+	for _, instance := range instances {
+		online[instanceKey(instance)] = true
+	}
+	for addr, pooled := range p.conns {
+		if pooled.service == service && !online[addr] {
+			pooled.conn.Close()
+			delete(p.conns, addr)
+		}
+	}
+}
+
+// Close 关闭所有连接
+func (p *ConnPool) Close() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for addr, pooled := range p.conns {
+		pooled.conn.Close()
+		delete(p.conns, addr)
+	}
+	p.instances = make(map[string][]*common.ServiceInstance)
+}
+
+// Len 返回连接数
+func (p *ConnPool) Len() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return len(p.conns)
+}
+
+type tracerCtxKey struct{}
+
+// tracingInterceptor 使用调用时放入 ctx 的追踪对象记录链路, 使连接可以在不同追踪对象的调用间复用
+func tracingInterceptor(ctx context.Context, method string, req, reply interface{},
+	cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	tracer, ok := ctx.Value(tracerCtxKey{}).(opentracing.Tracer)
+	if !ok {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	return otgrpc.OpenTracingClientInterceptor(tracer, otgrpc.LogPayloads())(ctx, method, req, reply, cc, invoker, opts...)
+}
+
+func sameInstances(a, b []*common.ServiceInstance) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	return len(a) == 0 || &a[0] == &b[0]
+}
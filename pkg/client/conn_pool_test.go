@@ -0,0 +1,141 @@
+package client
+
+import (
+	"context"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"github.com/lixichongAAA/seckill/pb"
+	"github.com/lixichongAAA/seckill/pkg/common"
+	"github.com/lixichongAAA/seckill/pkg/loadbalance"
+	"github.com/opentracing/opentracing-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+type stubUserService struct{}
+
+func (stubUserService) Check(ctx context.Context, req *pb.UserRequest) (*pb.UserResponse, error) {
+	return &pb.UserResponse{Result: req.Username == req.Password}, nil
+}
+
+// staticDiscovery 返回固定实例列表的服务发现
+type staticDiscovery struct {
+	lock      sync.Mutex
+	instances []*common.ServiceInstance
+}
+
+func (d *staticDiscovery) Register(instanceId, svcHost, healthCheckUrl, svcPort string, svcName string, weight int, meta map[string]string, tags []string, logger *log.Logger) bool {
+	return true
+}
+
+func (d *staticDiscovery) DeRegister(instanceId string, logger *log.Logger) bool {
+	return true
+}
+
+func (d *staticDiscovery) DiscoverServices(serviceName string, logger *log.Logger) []*common.ServiceInstance {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.instances
+}
+
+func (d *staticDiscovery) set(instances ...*common.ServiceInstance) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.instances = instances
+}
+
+// startUserServer 启动进程内的 gRPC 服务, 返回对应的服务实例
+func startUserServer(t *testing.T) (*grpc.Server, *common.ServiceInstance) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	pb.RegisterUserServiceServer(server, stubUserService{})
+	go server.Serve(listener)
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	grpcPort, _ := strconv.Atoi(port)
+	return server, &common.ServiceInstance{Host: "127.0.0.1", GrpcPort: grpcPort}
+}
+
+func TestDecoratorInvokeReusesConnections(t *testing.T) {
+	first, firstInstance := startUserServer(t)
+	defer first.Stop()
+	second, secondInstance := startUserServer(t)
+	defer second.Stop()
+
+	discovery := &staticDiscovery{}
+	discovery.set(firstInstance)
+	pool := NewConnPool()
+	defer pool.Close()
+	manager := &DefaultClientManager{
+		serviceName:     "user",
+		logger:          log.New(os.Stderr, "", log.LstdFlags),
+		discoveryClient: discovery,
+		loadBalance:     &loadbalance.RandomLoadBalance{},
+		connPool:        pool,
+	}
+	tracer := opentracing.NoopTracer{}
+	hystrix.ConfigureCommand("user_check_pool", hystrix.CommandConfig{MaxConcurrentRequests: 100})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response := new(pb.UserResponse)
+			err := manager.DecoratorInvoke("/pb.UserService/Check", "user_check_pool", tracer, context.Background(),
+				&pb.UserRequest{Username: "xuan", Password: "xuan"}, response)
+			if err != nil || !response.Result {
+				t.Errorf("invoke failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := pool.Len(); n != 1 {
+		t.Fatalf("expected one pooled connection, got %d", n)
+	}
+	conn, _ := pool.Get("user", firstInstance)
+
+	// 服务发现不再返回第一个实例后, 其连接被关闭
+	discovery.set(secondInstance)
+	response := new(pb.UserResponse)
+	if err := manager.DecoratorInvoke("/pb.UserService/Check", "user_check_pool", tracer, context.Background(),
+		&pb.UserRequest{Username: "xuan", Password: "xuan"}, response); err != nil {
+		t.Fatal(err)
+	}
+	if state := conn.GetState(); state != connectivity.Shutdown {
+		t.Errorf("connection to the dropped instance should be closed, state %v", state)
+	}
+	if n := pool.Len(); n != 1 {
+		t.Errorf("expected one pooled connection after the instance change, got %d", n)
+	}
+}
+
+func TestDecoratorInvokeDeadline(t *testing.T) {
+	_, instance := startUserServer(t)
+	discovery := &staticDiscovery{}
+	discovery.set(instance)
+	manager := &DefaultClientManager{
+		serviceName:     "user",
+		logger:          log.New(os.Stderr, "", log.LstdFlags),
+		discoveryClient: discovery,
+		loadBalance:     &loadbalance.RandomLoadBalance{},
+		connPool:        NewConnPool(),
+	}
+	defer manager.connPool.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := manager.DecoratorInvoke("/pb.UserService/Check", "user_check_deadline", opentracing.NoopTracer{}, ctx,
+		&pb.UserRequest{Username: "xuan", Password: "xuan"}, new(pb.UserResponse))
+	if err == nil {
+		t.Error("invoke with a cancelled context should fail")
+	}
+}
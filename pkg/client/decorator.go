@@ -5,16 +5,19 @@ import (
 	"errors"
 	"log"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/afex/hystrix-go/hystrix"
 	"github.com/grpc-ecosystem/grpc-opentracing/go/otgrpc"
 	"github.com/lixichongAAA/seckill/pkg/bootstrap"
+	"github.com/lixichongAAA/seckill/pkg/common"
 	conf "github.com/lixichongAAA/seckill/pkg/config"
 	"github.com/lixichongAAA/seckill/pkg/discover"
 	"github.com/lixichongAAA/seckill/pkg/loadbalance"
 	"github.com/opentracing/opentracing-go"
 	zipkin "github.com/openzipkin-contrib/zipkin-go-opentracing"
+	"github.com/spf13/viper"
 	"google.golang.org/grpc"
 )
 
@@ -22,11 +25,26 @@ var (
 	ErrRPCService = errors.New("no rpc service")
 )
 
-var defaultLoadBalance loadbalance.LoadBalance = &loadbalance.RandomLoadBalance{}
+const kLoadBalance = "loadBalance"
+
+// configuredLoadBalance 按配置 loadBalance.<服务名> 或 loadBalance.default 选择负载均衡策略, 均未配置时随机选择
+func configuredLoadBalance(serviceName string) loadbalance.LoadBalance {
+	name := viper.GetString(kLoadBalance + "." + serviceName)
+	if name == "" {
+		name = viper.GetString(kLoadBalance + ".default")
+	}
+	lb, err := loadbalance.New(name)
+	if err != nil {
+		log.Printf("service %s: %v, use random load balance", serviceName, err)
+		return &loadbalance.RandomLoadBalance{}
+	}
+	return lb
+}
 
 type ClientManager interface {
 	DecoratorInvoke(path string, hystrixName string, tracer opentracing.Tracer,
 		ctx context.Context, inputVal interface{}, outVal interface{}) (err error)
+	Dial(tracer opentracing.Tracer) (*grpc.ClientConn, error)
 }
 
 type DefaultClientManager struct {
@@ -34,6 +52,9 @@ type DefaultClientManager struct {
 	logger          *log.Logger
 	discoveryClient discover.DiscoveryClient
 	loadBalance     loadbalance.LoadBalance
+	connPool        *ConnPool       // 连接池, 为空时使用所有客户端共用的连接池
+	retryPolicy     *RetryPolicy    // 重试策略, 为空时不重试
+	retryMethods    map[string]bool // 按重试策略重试的方法, 为空时所有方法都重试; 非幂等的方法不应重试
 	after           []InvokerAfterFunc
 	before          []InvokerBeforeFunc
 }
@@ -51,50 +72,145 @@ func (manager *DefaultClientManager) DecoratorInvoke(path string, hystrixName st
 			return err
 		}
 	}
-	// 2. 使用 Hystrix 的 Do 方法构造对应的断路器保护
-	if err = hystrix.Do(hystrixName, func() error {
-		// 3. 服务发现， 获得服务提供方的服务实例列表
-		instances := manager.discoveryClient.DiscoverServices(manager.serviceName, manager.logger)
-		// 4. 负载均衡, 使用配置的负载均衡策略来从服务实例列表中选取一个合适的服务实例
-		if instance, err := manager.loadBalance.SelectService(instances); err == nil {
-			// 5. 获得RPC端口，并且发送RPC请求
-			if instance.GrpcPort > 0 {
-				if conn, err := grpc.Dial(instance.Host+":"+strconv.Itoa(instance.GrpcPort), grpc.WithInsecure(),
-					// grpc 的一元拦截器, 详见书
-					grpc.WithUnaryInterceptor(otgrpc.OpenTracingClientInterceptor(genTracer(tracer), otgrpc.LogPayloads())), grpc.WithTimeout(1*time.Second)); err == nil {
-					if err = conn.Invoke(ctx, path, inputVal, outVal); err != nil {
-						return err
-					}
-				} else {
-					return err
-				}
-			} else {
-				return ErrRPCService
-			}
-		} else {
+	// 2. 按重试策略调用, 每次调用都使用 Hystrix 的 Do 方法构造对应的断路器保护
+	policy := manager.policyFor(path)
+	if policy != nil && policy.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, policy.Timeout)
+		defer cancel()
+	}
+	// 重试时优先选择尚未调用过的实例
+	tried := make(map[string]bool)
+	for attempt := 1; ; attempt++ {
+		// 不设置 fallback, 返回原始错误, 以便根据 gRPC 状态码判断是否重试
+		err = hystrix.Do(hystrixName, func() error {
+			return manager.invoke(ctx, path, hystrixName, tracer, inputVal, outVal, tried)
+		}, nil)
+		if !policy.retry(ctx, attempt, err) {
+			break
+		}
+		manager.logger.Printf("retry %s, attempt %d failed: %v", path, attempt, err)
+	}
+	if err != nil {
+		return err
+	}
+	// 调用 ClientManager 的 after 回调函数
+	for _, fn := range manager.after {
+		if err = fn(); err != nil {
 			return err
 		}
+	}
+	return nil
+}
+
+// policyFor 返回方法使用的重试策略, 不重试时为 nil
+func (manager *DefaultClientManager) policyFor(path string) *RetryPolicy {
+	if manager.retryMethods != nil && !manager.retryMethods[path] {
 		return nil
-	}, func(e error) error {
-		return e
-	}); err != nil {
+	}
+	return manager.retryPolicy
+}
+
+// invoke 选取服务实例并发送一次 RPC 请求, tried 记录已调用过的实例, 选取时排除
+func (manager *DefaultClientManager) invoke(ctx context.Context, path string, hystrixName string,
+	tracer opentracing.Tracer, inputVal interface{}, outVal interface{}, tried map[string]bool) error {
+	// 3. 服务发现， 获得服务提供方的服务实例列表, 并关闭已下线实例的连接
+	instances := manager.discoveryClient.DiscoverServices(manager.serviceName, manager.logger)
+	pool := manager.pool()
+	pool.Sync(manager.serviceName, instances)
+	// 4. 负载均衡, 使用配置的负载均衡策略来从未调用过的服务实例中选取一个合适的服务实例
+	// ctx 中通过 loadbalance.WithHashKey 设置了 key 时, 一致性哈希策略按 key 选择
+	instance, err := loadbalance.Select(ctx, manager.loadBalance, excludeTried(instances, tried))
+	if err != nil {
+		return err
+	}
+	defer loadbalance.Done(manager.loadBalance, instance)
+	tried[instanceKey(instance)] = true
+	// 5. 获得RPC端口，从连接池获取连接并发送RPC请求
+	if instance.GrpcPort <= 0 {
+		return ErrRPCService
+	}
+	conn, err := pool.Get(manager.serviceName, instance)
+	if err != nil {
 		return err
-	} else {
-		// 调用 ClientManager 的 after 回调函数
-		for _, fn := range manager.after {
-			if err = fn(); err != nil {
-				return err
-			}
+	}
+	// 调用超过断路器的超时时间后取消, 避免 hystrix 返回后请求仍占用连接
+	callCtx, cancel := context.WithTimeout(context.WithValue(ctx, tracerCtxKey{}, genTracer(tracer)), callTimeout(hystrixName))
+	defer cancel()
+	return conn.Invoke(callCtx, path, inputVal, outVal)
+}
+
+// Dial 通过服务发现和负载均衡选取服务实例并建立连接, 用于流式调用, 连接由调用方关闭
+// 流式调用的生命周期较长, 不经过 Hystrix 断路器
+func (manager *DefaultClientManager) Dial(tracer opentracing.Tracer) (*grpc.ClientConn, error) {
+	instances := manager.discoveryClient.DiscoverServices(manager.serviceName, manager.logger)
+	instance, err := manager.loadBalance.SelectService(instances)
+	if err != nil {
+		return nil, err
+	}
+	// 流式调用的连接长期保持, 不计入实例的进行中请求数
+	loadbalance.Done(manager.loadBalance, instance)
+	if instance.GrpcPort <= 0 {
+		return nil, ErrRPCService
+	}
+	return grpc.Dial(instance.Host+":"+strconv.Itoa(instance.GrpcPort), grpc.WithInsecure(),
+		grpc.WithStreamInterceptor(otgrpc.OpenTracingStreamClientInterceptor(genTracer(tracer))), grpc.WithTimeout(1*time.Second))
+}
+
+// excludeTried 排除已调用过的实例, 全部实例都调用过时返回原列表
+func excludeTried(instances []*common.ServiceInstance, tried map[string]bool) []*common.ServiceInstance {
+	if len(tried) == 0 {
+		return instances
+	}
+	rest := make([]*common.ServiceInstance, 0, len(instances))
+	for _, instance := range instances {
+		if !tried[instanceKey(instance)] {
+			rest = append(rest, instance)
 		}
-		return nil
 	}
+	if len(rest) == 0 {
+		return instances
+	}
+	return rest
+}
+
+func instanceKey(instance *common.ServiceInstance) string {
+	return instance.Host + ":" + strconv.Itoa(instance.GrpcPort)
 }
 
-// 增加 zipkin 追踪
+func (manager *DefaultClientManager) pool() *ConnPool {
+	if manager.connPool != nil {
+		return manager.connPool
+	}
+	return defaultConnPool
+}
+
+// callTimeout 返回 hystrix 命令的超时时间
+func callTimeout(hystrixName string) time.Duration {
+	if settings, ok := hystrix.GetCircuitSettings()[hystrixName]; ok {
+		return settings.Timeout
+	}
+	return time.Duration(hystrix.DefaultTimeout) * time.Millisecond
+}
+
+var (
+	defaultTracer     opentracing.Tracer
+	defaultTracerOnce sync.Once
+)
+
+// 增加 zipkin 追踪, 未指定追踪对象时使用默认的追踪对象, 只创建一次
 func genTracer(tracer opentracing.Tracer) opentracing.Tracer {
 	if tracer != nil {
 		return tracer
 	}
+	defaultTracerOnce.Do(func() {
+		defaultTracer = newDefaultTracer()
+	})
+	return defaultTracer
+}
+
+func newDefaultTracer() opentracing.Tracer {
+	bootstrap.Load()
 	zipkinUrl := "http://" + conf.TraceConfig.Host + ":" + conf.TraceConfig.Port + conf.TraceConfig.Url
 	zipkinRecorder := bootstrap.HttpConfig.Host + ":" + bootstrap.HttpConfig.Port
 	collector, err := zipkin.NewHTTPCollector(zipkinUrl)
@@ -33,15 +33,20 @@ func NewOAuthClient(serviceName string, lb loadbalance.LoadBalance, tracer opent
 		serviceName = "oauth"
 	}
 	if lb == nil {
-		lb = defaultLoadBalance
+		lb = configuredLoadBalance(serviceName)
+	}
+	discoveryClient, err := discover.Client()
+	if err != nil {
+		return nil, err
 	}
 
 	return &OAuthClientImpl{
 		manager: &DefaultClientManager{
 			serviceName:     serviceName,
 			loadBalance:     lb,
-			discoveryClient: discover.ConsulService,
+			discoveryClient: discoveryClient,
 			logger:          discover.Logger,
+			retryPolicy:     configuredRetryPolicy(serviceName),
 		},
 		serviceName: serviceName,
 		loadBalance: lb,
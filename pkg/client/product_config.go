@@ -0,0 +1,122 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/lixichongAAA/seckill/pb"
+	conf "github.com/lixichongAAA/seckill/pkg/config"
+)
+
+const (
+	watchMinBackoff = time.Second      //重新订阅的初始等待时间
+	watchMaxBackoff = time.Second * 30 //重新订阅的最长等待时间
+)
+
+var ErrProductConfigVersionGap = errors.New("product config version gap")
+
+// ProductConfigWatcher 订阅 sk-admin 推送的商品配置, 在本地维护完整的配置列表,
+// 每次变更后将全量配置交给 onUpdate, 供 sk-app/sk-core 替代从 Zookeeper 加载商品配置
+// 连接断开或版本号不连续时重新订阅, 重新订阅后 sk-admin 会先推送全量配置
+type ProductConfigWatcher struct {
+	client   ActivityClient
+	onUpdate func([]*conf.SecProductInfoConf)
+	version  int64
+	confMap  map[int]*conf.SecProductInfoConf //活动Id -> 商品配置
+}
+
+func NewProductConfigWatcher(client ActivityClient, onUpdate func([]*conf.SecProductInfoConf)) *ProductConfigWatcher {
+	return &ProductConfigWatcher{
+		client:   client,
+		onUpdate: onUpdate,
+		confMap:  make(map[int]*conf.SecProductInfoConf),
+	}
+}
+
+// Run 持续订阅商品配置直到 ctx 结束, 由调用方在独立协程中启动
+func (w *ProductConfigWatcher) Run(ctx context.Context) {
+	backoff := watchMinBackoff
+	for {
+		received, err := w.watch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if received {
+			backoff = watchMinBackoff
+		}
+		log.Printf("watch product config failed, retry after %v, err : %v", backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > watchMaxBackoff {
+			backoff = watchMaxBackoff
+		}
+	}
+}
+
+// watch 订阅一次直到出错, received 表示本次订阅是否收到过事件
+func (w *ProductConfigWatcher) watch(ctx context.Context) (received bool, err error) {
+	stream, err := w.client.WatchProductConfig(ctx, nil, &pb.WatchProductConfigRequest{})
+	if err != nil {
+		return false, err
+	}
+	defer stream.Close()
+
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			return received, err
+		}
+		if err = w.apply(event); err != nil {
+			return received, err
+		}
+		received = true
+	}
+}
+
+func (w *ProductConfigWatcher) apply(event *pb.ProductConfigEvent) error {
+	if event.Full {
+		w.confMap = make(map[int]*conf.SecProductInfoConf, len(event.Configs))
+	} else if event.Version != w.version+1 {
+		return ErrProductConfigVersionGap
+	}
+	w.version = event.Version
+
+	for _, v := range event.Configs {
+		w.confMap[int(v.ActivityId)] = fromPbSecProductInfoConf(v)
+	}
+	for _, v := range event.Removed {
+		delete(w.confMap, int(v))
+	}
+
+	secProductInfoList := make([]*conf.SecProductInfoConf, 0, len(w.confMap))
+	for _, v := range w.confMap {
+		secProductInfoList = append(secProductInfoList, v)
+	}
+	sort.Slice(secProductInfoList, func(i, j int) bool {
+		return secProductInfoList[i].ActivityId < secProductInfoList[j].ActivityId
+	})
+	w.onUpdate(secProductInfoList)
+	return nil
+}
+
+func fromPbSecProductInfoConf(v *pb.SecProductInfoConf) *conf.SecProductInfoConf {
+	return &conf.SecProductInfoConf{
+		ActivityId:        int(v.ActivityId),
+		ProductId:         int(v.ProductId),
+		StartTime:         v.StartTime,
+		EndTime:           v.EndTime,
+		Status:            int(v.Status),
+		Total:             int(v.Total),
+		Left:              int(v.Left),
+		OnePersonBuyLimit: int(v.OnePersonBuyLimit),
+		BuyRate:           v.BuyRate,
+		SoldMaxLimit:      int(v.SoldMaxLimit),
+	}
+}
@@ -0,0 +1,185 @@
+package client
+
+import (
+	"context"
+	"log"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const kRetry = "retry"
+
+// RetryConfig 重试配置, 从 retry.<服务名> 或 retry.default 读取, 为 0 的项使用默认值
+type RetryConfig struct {
+	MaxAttempts     int      //最多调用次数(包括首次调用), 1 表示不重试, 默认 3
+	InitialBackoff  int      //首次重试前的等待时间(毫秒), 默认 50
+	MaxBackoff      int      //重试等待时间的上限(毫秒), 默认 1000
+	Multiplier      float64  //每次重试等待时间的增长倍数, 默认 2
+	RetryableCodes  []string //可重试的 gRPC 状态码, 如 UNAVAILABLE, 默认只重试 UNAVAILABLE
+	Timeout         int      //整个调用(包括重试)的超时时间(毫秒), 为 0 时只受调用方 ctx 和断路器超时限制
+	BudgetMaxTokens float64  //重试预算的令牌上限, 默认 10
+	BudgetRatio     float64  //每次成功调用返还的令牌数, 默认 0.1
+}
+
+// RetryPolicy 由重试配置编译而成, 只重试状态码可重试的失败, 断路器打开、并发超限和超时不重试
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	RetryableCodes map[codes.Code]bool
+	Timeout        time.Duration
+	Budget         *RetryBudget
+}
+
+// NewRetryPolicy 按配置创建重试策略, 未知的状态码被忽略
+func NewRetryPolicy(config RetryConfig) *RetryPolicy {
+	policy := &RetryPolicy{
+		MaxAttempts:    config.MaxAttempts,
+		InitialBackoff: time.Duration(config.InitialBackoff) * time.Millisecond,
+		MaxBackoff:     time.Duration(config.MaxBackoff) * time.Millisecond,
+		Multiplier:     config.Multiplier,
+		RetryableCodes: make(map[codes.Code]bool),
+		Timeout:        time.Duration(config.Timeout) * time.Millisecond,
+		Budget:         NewRetryBudget(config.BudgetMaxTokens, config.BudgetRatio),
+	}
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 3
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = 50 * time.Millisecond
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = time.Second
+	}
+	if policy.Multiplier < 1 {
+		policy.Multiplier = 2
+	}
+	for _, name := range config.RetryableCodes {
+		if code, ok := parseCode(name); ok {
+			policy.RetryableCodes[code] = true
+		} else {
+			log.Printf("unknown retryable code %s", name)
+		}
+	}
+	if len(policy.RetryableCodes) == 0 {
+		policy.RetryableCodes[codes.Unavailable] = true
+	}
+	return policy
+}
+
+// configuredRetryPolicy 按配置 retry.<服务名> 或 retry.default 创建重试策略, 均未配置时使用默认值
+func configuredRetryPolicy(serviceName string) *RetryPolicy {
+	var config RetryConfig
+	key := kRetry + "." + serviceName
+	if !viper.IsSet(key) {
+		key = kRetry + ".default"
+	}
+	if viper.IsSet(key) {
+		if err := viper.UnmarshalKey(key, &config); err != nil {
+			log.Printf("service %s: parse retry config: %v", serviceName, err)
+		}
+	}
+	return NewRetryPolicy(config)
+}
+
+// retry 判断第 attempt 次调用的结果是否需要重试, 需要时等待退避时间后返回 true
+// 调用成功时向重试预算返还令牌; 预算不足或等待期间 ctx 结束时不再重试
+func (policy *RetryPolicy) retry(ctx context.Context, attempt int, err error) bool {
+	if policy == nil {
+		return false
+	}
+	if err == nil {
+		policy.Budget.Success()
+		return false
+	}
+	if attempt >= policy.MaxAttempts || !policy.RetryableCodes[status.Code(err)] {
+		return false
+	}
+	if !policy.Budget.Withdraw() {
+		return false
+	}
+
+	timer := time.NewTimer(policy.backoff(attempt))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// backoff 第 attempt 次调用失败后的等待时间, 按指数增长并加入随机抖动, 避免大量客户端同时重试
+func (policy *RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := float64(policy.InitialBackoff)
+	for i := 1; i < attempt; i++ {
+		backoff *= policy.Multiplier
+	}
+	if backoff > float64(policy.MaxBackoff) {
+		backoff = float64(policy.MaxBackoff)
+	}
+	// 在 [backoff/2, backoff) 之间随机
+	return time.Duration(backoff/2 + rand.Float64()*backoff/2)
+}
+
+// RetryBudget 重试预算, 与 gRPC 的 retryThrottling 相同: 令牌初始为上限, 每次重试前的失败扣除 1 个令牌,
+// 每次成功返还 ratio 个令牌, 令牌不超过上限的一半时停止重试; 服务大面积故障时重试随之停止, 避免放大流量
+type RetryBudget struct {
+	lock      sync.Mutex
+	tokens    float64
+	maxTokens float64
+	ratio     float64
+}
+
+func NewRetryBudget(maxTokens, ratio float64) *RetryBudget {
+	if maxTokens <= 0 {
+		maxTokens = 10
+	}
+	if ratio <= 0 {
+		ratio = 0.1
+	}
+	return &RetryBudget{
+		tokens:    maxTokens,
+		maxTokens: maxTokens,
+		ratio:     ratio,
+	}
+}
+
+// Withdraw 记录一次可重试的失败, 返回是否允许重试
+func (b *RetryBudget) Withdraw() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.tokens--
+	if b.tokens < 0 {
+		b.tokens = 0
+	}
+	return b.tokens > b.maxTokens/2
+}
+
+// Success 记录一次成功调用
+func (b *RetryBudget) Success() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.tokens += b.ratio
+	if b.tokens > b.maxTokens {
+		b.tokens = b.maxTokens
+	}
+}
+
+// parseCode 解析状态码名称, 如 UNAVAILABLE、Unavailable 或 RESOURCE_EXHAUSTED
+func parseCode(name string) (codes.Code, bool) {
+	name = strings.Replace(name, "_", "", -1)
+	for code := codes.OK; code <= codes.Unauthenticated; code++ {
+		if strings.EqualFold(code.String(), name) {
+			return code, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,202 @@
+package client
+
+import (
+	"context"
+	"log"
+	"net"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/afex/hystrix-go/hystrix"
+	"github.com/lixichongAAA/seckill/pb"
+	"github.com/lixichongAAA/seckill/pkg/common"
+	"github.com/lixichongAAA/seckill/pkg/loadbalance"
+	"github.com/opentracing/opentracing-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// flakyUserService 前 failures 次调用返回 code
+type flakyUserService struct {
+	calls    int32
+	failures int32
+	code     codes.Code
+}
+
+func (s *flakyUserService) Check(ctx context.Context, req *pb.UserRequest) (*pb.UserResponse, error) {
+	if atomic.AddInt32(&s.calls, 1) <= s.failures {
+		return nil, status.Error(s.code, "flaky")
+	}
+	return &pb.UserResponse{Result: true}, nil
+}
+
+func newFlakyManager(t *testing.T, service *flakyUserService, policy *RetryPolicy) (*DefaultClientManager, func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	pb.RegisterUserServiceServer(server, service)
+	go server.Serve(listener)
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	grpcPort, _ := strconv.Atoi(port)
+
+	discovery := &staticDiscovery{}
+	discovery.set(&common.ServiceInstance{Host: "127.0.0.1", GrpcPort: grpcPort})
+	manager := &DefaultClientManager{
+		serviceName:     "user",
+		logger:          log.New(os.Stderr, "", log.LstdFlags),
+		discoveryClient: discovery,
+		loadBalance:     &loadbalance.RandomLoadBalance{},
+		connPool:        NewConnPool(),
+		retryPolicy:     policy,
+	}
+	return manager, func() {
+		manager.connPool.Close()
+		server.Stop()
+	}
+}
+
+func checkUser(manager *DefaultClientManager) error {
+	return manager.DecoratorInvoke("/pb.UserService/Check", "user_check_retry", opentracing.NoopTracer{}, context.Background(),
+		&pb.UserRequest{Username: "xuan", Password: "xuan"}, new(pb.UserResponse))
+}
+
+func TestRetryTransientFailure(t *testing.T) {
+	hystrix.ConfigureCommand("user_check_retry", hystrix.CommandConfig{ErrorPercentThreshold: 100, RequestVolumeThreshold: 1000})
+	service := &flakyUserService{failures: 2, code: codes.Unavailable}
+	manager, stop := newFlakyManager(t, service, NewRetryPolicy(RetryConfig{InitialBackoff: 1}))
+	defer stop()
+
+	if err := checkUser(manager); err != nil {
+		t.Fatalf("transient failures should be retried: %v", err)
+	}
+	if calls := atomic.LoadInt32(&service.calls); calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestRetryNonRetryableCode(t *testing.T) {
+	service := &flakyUserService{failures: 1, code: codes.InvalidArgument}
+	manager, stop := newFlakyManager(t, service, NewRetryPolicy(RetryConfig{InitialBackoff: 1}))
+	defer stop()
+
+	if err := checkUser(manager); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected the original status, got %v", err)
+	}
+	if calls := atomic.LoadInt32(&service.calls); calls != 1 {
+		t.Errorf("non-retryable code should not be retried, got %d calls", calls)
+	}
+}
+
+func TestRetryMethods(t *testing.T) {
+	hystrix.ConfigureCommand("user_check_retry", hystrix.CommandConfig{ErrorPercentThreshold: 100, RequestVolumeThreshold: 1000})
+	service := &flakyUserService{failures: 1, code: codes.Unavailable}
+	manager, stop := newFlakyManager(t, service, NewRetryPolicy(RetryConfig{InitialBackoff: 1}))
+	defer stop()
+
+	// 未列入 retryMethods 的方法不重试
+	manager.retryMethods = map[string]bool{"/pb.UserService/Other": true}
+	if err := checkUser(manager); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected UNAVAILABLE, got %v", err)
+	}
+	if calls := atomic.LoadInt32(&service.calls); calls != 1 {
+		t.Errorf("method should not be retried, got %d calls", calls)
+	}
+
+	atomic.StoreInt32(&service.calls, 0)
+	manager.retryMethods["/pb.UserService/Check"] = true
+	if err := checkUser(manager); err != nil {
+		t.Fatalf("listed method should be retried: %v", err)
+	}
+	if calls := atomic.LoadInt32(&service.calls); calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestRetryExcludesTriedInstances(t *testing.T) {
+	hystrix.ConfigureCommand("user_check_retry", hystrix.CommandConfig{ErrorPercentThreshold: 100, RequestVolumeThreshold: 1000})
+	services := []*flakyUserService{{failures: 100, code: codes.Unavailable}, {failures: 100, code: codes.Unavailable}}
+	discovery := &staticDiscovery{}
+	var instances []*common.ServiceInstance
+	for _, service := range services {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		server := grpc.NewServer()
+		pb.RegisterUserServiceServer(server, service)
+		go server.Serve(listener)
+		defer server.Stop()
+		_, port, _ := net.SplitHostPort(listener.Addr().String())
+		grpcPort, _ := strconv.Atoi(port)
+		instances = append(instances, &common.ServiceInstance{Host: "127.0.0.1", GrpcPort: grpcPort, Weight: 1})
+	}
+	discovery.set(instances...)
+	manager := &DefaultClientManager{
+		serviceName:     "user",
+		logger:          log.New(os.Stderr, "", log.LstdFlags),
+		discoveryClient: discovery,
+		loadBalance:     loadbalance.NewConsistentHashLoadBalance(),
+		connPool:        NewConnPool(),
+		retryPolicy:     NewRetryPolicy(RetryConfig{MaxAttempts: 2, InitialBackoff: 1}),
+	}
+	defer manager.connPool.Close()
+
+	// 同一个 hash key 首次总是选择同一实例, 重试时应换到另一个实例
+	ctx := loadbalance.WithHashKey(context.Background(), "1001")
+	err := manager.DecoratorInvoke("/pb.UserService/Check", "user_check_retry", opentracing.NoopTracer{}, ctx,
+		&pb.UserRequest{Username: "xuan", Password: "xuan"}, new(pb.UserResponse))
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected UNAVAILABLE, got %v", err)
+	}
+	for i, service := range services {
+		if calls := atomic.LoadInt32(&service.calls); calls != 1 {
+			t.Errorf("instance %d: expected 1 call, got %d", i, calls)
+		}
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	budget := NewRetryBudget(10, 0.1)
+	allowed := 0
+	for i := 0; i < 10; i++ {
+		if budget.Withdraw() {
+			allowed++
+		}
+	}
+	if allowed != 4 {
+		t.Fatalf("expected retries to stop at half of the budget, allowed %d", allowed)
+	}
+	for i := 0; i < 100; i++ {
+		budget.Success()
+	}
+	if !budget.Withdraw() {
+		t.Error("successful calls should refill the budget")
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	policy := NewRetryPolicy(RetryConfig{InitialBackoff: 100, MaxBackoff: 300})
+	for attempt, max := range map[int]time.Duration{1: 100, 2: 200, 3: 300, 4: 300} {
+		max *= time.Millisecond
+		if backoff := policy.backoff(attempt); backoff < max/2 || backoff >= max {
+			t.Errorf("attempt %d: backoff %v out of [%v, %v)", attempt, backoff, max/2, max)
+		}
+	}
+}
+
+func TestParseCode(t *testing.T) {
+	for name, want := range map[string]codes.Code{"UNAVAILABLE": codes.Unavailable, "Unavailable": codes.Unavailable, "RESOURCE_EXHAUSTED": codes.ResourceExhausted} {
+		if code, ok := parseCode(name); !ok || code != want {
+			t.Errorf("%s: got %v", name, code)
+		}
+	}
+	if _, ok := parseCode("NOPE"); ok {
+		t.Error("unknown code should not parse")
+	}
+}
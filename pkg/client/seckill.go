@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/lixichongAAA/seckill/pb"
+	"github.com/lixichongAAA/seckill/pkg/discover"
+	"github.com/lixichongAAA/seckill/pkg/loadbalance"
+	"github.com/opentracing/opentracing-go"
+	"google.golang.org/grpc/metadata"
+)
+
+// SecKillClient 调用 sk-app 的秒杀接口
+// sk-app 以访问令牌所属的用户参与秒杀, 调用前需通过 metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token) 携带用户的令牌
+type SecKillClient interface {
+	SecKill(ctx context.Context, tracer opentracing.Tracer, request *pb.SecRequest) (*pb.SecResponse, error)
+}
+
+type SecKillClientImpl struct {
+	manager     ClientManager           // 客户端管理器
+	serviceName string                  // 服务名称
+	loadBalance loadbalance.LoadBalance // 负载均衡策略
+	tracer      opentracing.Tracer      // 链路追踪系统
+}
+
+func (impl *SecKillClientImpl) SecKill(ctx context.Context, tracer opentracing.Tracer, request *pb.SecRequest) (*pb.SecResponse, error) {
+	response := new(pb.SecResponse)
+	// 同一用户的请求按令牌中的用户 id 落到同一实例(负载均衡策略为一致性哈希时), 没有令牌时按常规策略选择
+	ctx = loadbalance.WithHashKey(ctx, secKillHashKey(ctx))
+	if err := impl.manager.DecoratorInvoke("/pb.SecKillService/secKill", "sec_kill", tracer, ctx, request, response); err == nil {
+		return response, nil
+	} else {
+		return nil, err
+	}
+}
+
+func NewSecKillClient(serviceName string, lb loadbalance.LoadBalance, tracer opentracing.Tracer) (SecKillClient, error) {
+	if serviceName == "" {
+		serviceName = "sk-app"
+	}
+	if lb == nil {
+		lb = configuredLoadBalance(serviceName)
+	}
+	discoveryClient, err := discover.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	return &SecKillClientImpl{
+		manager: &DefaultClientManager{
+			serviceName:     serviceName,
+			loadBalance:     lb,
+			discoveryClient: discoveryClient,
+			logger:          discover.Logger,
+			// 秒杀下单不是幂等操作, 返回 UNAVAILABLE 时服务端可能已处理请求, 重试会重复下单, 因此不设置重试策略
+		},
+		serviceName: serviceName,
+		loadBalance: lb,
+		tracer:      tracer,
+	}, nil
+
+}
+
+// secKillHashKey 返回一致性哈希使用的 key: sk-app 以令牌所属的用户参与秒杀, 因此取令牌声明中的用户 id,
+// 无法解析时使用令牌本身; 请求中的 UserId 会被 sk-app 忽略, 不能用于选择实例
+// 令牌声明只用于选择实例, 此处不校验签名, 令牌由 sk-app 校验
+func secKillHashKey(ctx context.Context) string {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return ""
+	}
+	token := strings.TrimSpace(strings.TrimPrefix(values[0], "Bearer "))
+	var claims struct {
+		UserDetails struct {
+			UserId int64
+		}
+		jwt.StandardClaims
+	}
+	if _, _, err := new(jwt.Parser).ParseUnverified(token, &claims); err == nil && claims.UserDetails.UserId != 0 {
+		return strconv.FormatInt(claims.UserDetails.UserId, 10)
+	}
+	return token
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestSecKillHashKey(t *testing.T) {
+	sign := func(userId int64) string {
+		claims := jwt.MapClaims{"UserDetails": map[string]interface{}{"UserId": userId}}
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	user7 := sign(7)
+	tests := []struct {
+		name  string
+		token string // 为空表示请求不携带令牌
+		want  string
+	}{
+		{"user in token", "Bearer " + user7, "7"},
+		{"without bearer prefix", user7, "7"},
+		{"zero user id", "Bearer " + sign(0), sign(0)},
+		{"not a jwt", "Bearer opaque", "opaque"},
+		{"no token", "", ""},
+	}
+	for _, test := range tests {
+		ctx := context.Background()
+		if test.token != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", test.token)
+		}
+		if got := secKillHashKey(ctx); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
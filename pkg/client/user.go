@@ -37,15 +37,20 @@ func NewUserClient(serviceName string, lb loadbalance.LoadBalance, tracer opentr
 		serviceName = "user"
 	}
 	if lb == nil {
-		lb = defaultLoadBalance
+		lb = configuredLoadBalance(serviceName)
+	}
+	discoveryClient, err := discover.Client()
+	if err != nil {
+		return nil, err
 	}
 
 	return &UserClientImpl{
 		manager: &DefaultClientManager{
 			serviceName:     serviceName,
 			loadBalance:     lb,
-			discoveryClient: discover.ConsulService,
+			discoveryClient: discoveryClient,
 			logger:          discover.Logger,
+			retryPolicy:     configuredRetryPolicy(serviceName),
 		},
 		serviceName: serviceName,
 		loadBalance: lb,
@@ -53,6 +53,7 @@ type RedisConf struct {
 	IpBlackListHash      string        //IP黑名单Hash表
 	IdBlackListQueue     string        //用户黑名单队列
 	IpBlackListQueue     string        //IP黑名单队列
+	SoldOutHash          string        //售罄活动hash表, 由 sk-core 写入, sk-admin 读取
 	Host                 string
 	Password             string
 	Db                   int
@@ -95,8 +96,25 @@ type SecKillConf struct {
 	TokenPassWd             string
 }
 
+const (
+	ProductStatusNormal       = 0 //商品状态正常
+	ProductStatusSaleOut      = 1 //商品售罄
+	ProductStatusForceSaleOut = 2 //商品强制售罄
+)
+
+const defaultSoldOutHash = "sec_kill_sold_out"
+
+// SoldOutHashName 返回售罄活动hash表的名称, 未配置时使用默认值
+func (p *RedisConf) SoldOutHashName() string {
+	if p.SoldOutHash == "" {
+		return defaultSoldOutHash
+	}
+	return p.SoldOutHash
+}
+
 // 商品信息配置
 type SecProductInfoConf struct {
+	ActivityId        int                 `json:"activity_id"`          //活动ID
 	ProductId         int                 `json:"product_id"`           //商品ID
 	StartTime         int64               `json:"start_time"`           //开始时间
 	EndTime           int64               `json:"end_time"`             //结束时间
@@ -109,6 +127,42 @@ type SecProductInfoConf struct {
 	SecLimit          *srv_limit.SecLimit `json:"sec_limit"` //限速控制
 }
 
+// NewSecProductInfoMap 按商品Id索引商品配置; 同一商品有多个活动时,
+// 优先选择 now 处于活动时间内的活动, 其次是最早开始的未开始活动, 都没有时选择最晚结束的活动
+func NewSecProductInfoMap(secProductInfo []*SecProductInfoConf, now int64) map[int]*SecProductInfoConf {
+	result := make(map[int]*SecProductInfoConf, len(secProductInfo))
+	for _, v := range secProductInfo {
+		if current, ok := result[v.ProductId]; !ok || preferSecProductInfo(v, current, now) {
+			result[v.ProductId] = v
+		}
+	}
+	return result
+}
+
+// preferSecProductInfo 判断同一商品的活动 a 是否比 b 更应该生效
+func preferSecProductInfo(a, b *SecProductInfoConf, now int64) bool {
+	rank := func(v *SecProductInfoConf) int {
+		switch {
+		case v.StartTime <= now && now <= v.EndTime:
+			return 0 //进行中
+		case v.StartTime > now:
+			return 1 //未开始
+		}
+		return 2 //已结束
+	}
+	rankA, rankB := rank(a), rank(b)
+	if rankA != rankB {
+		return rankA < rankB
+	}
+	switch rankA {
+	case 1:
+		return a.StartTime < b.StartTime
+	case 2:
+		return a.EndTime > b.EndTime
+	}
+	return a.ActivityId > b.ActivityId
+}
+
 // 访问限制
 type AccessLimitConf struct {
 	IPSecAccessLimit   int //IP每秒钟访问限制
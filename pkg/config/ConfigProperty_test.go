@@ -0,0 +1,34 @@
+package conf
+
+import "testing"
+
+func TestNewSecProductInfoMap(t *testing.T) {
+	running := &SecProductInfoConf{ActivityId: 1, ProductId: 1, StartTime: 1000, EndTime: 2000}
+	next := &SecProductInfoConf{ActivityId: 2, ProductId: 1, StartTime: 3000, EndTime: 4000}
+	later := &SecProductInfoConf{ActivityId: 3, ProductId: 1, StartTime: 5000, EndTime: 6000}
+	other := &SecProductInfoConf{ActivityId: 4, ProductId: 2, StartTime: 3000, EndTime: 4000}
+
+	tests := []struct {
+		name string
+		list []*SecProductInfoConf
+		now  int64
+		want *SecProductInfoConf //商品 1 生效的活动
+	}{
+		{"running before upcoming", []*SecProductInfoConf{running, next}, 1500, running},
+		{"upcoming listed first", []*SecProductInfoConf{next, running}, 1500, running},
+		{"earliest upcoming", []*SecProductInfoConf{later, next, running}, 2500, next},
+		{"next is running", []*SecProductInfoConf{running, next, later}, 3000, next},
+		{"before all", []*SecProductInfoConf{later, next}, 500, next},
+		{"all ended", []*SecProductInfoConf{running, next}, 4500, next},
+		{"single", []*SecProductInfoConf{later}, 1500, later},
+	}
+	for _, test := range tests {
+		got := NewSecProductInfoMap(append(test.list, other), test.now)
+		if got[1] != test.want {
+			t.Errorf("%s: got activity %d, want %d", test.name, got[1].ActivityId, test.want.ActivityId)
+		}
+		if got[2] != other {
+			t.Errorf("%s: other product should not be affected", test.name)
+		}
+	}
+}
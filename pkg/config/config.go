@@ -1,6 +1,7 @@
 package conf
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -28,6 +29,7 @@ func initDefault() {
 }
 
 func init() {
+	bootstrap.Load()
 	Logger = log.NewLogfmtLogger(os.Stderr)
 	Logger = log.With(Logger, "ts", log.DefaultTimestampUTC)
 	Logger = log.With(Logger, "caller", log.DefaultCaller)
@@ -68,7 +70,14 @@ func initTracer(zipkinURL string) {
 		Logger.Log("tracer", "Zipkin", "type", "Native", "URL", zipkinURL)
 	}
 }
+
+var errConfigServerMissing = errors.New("config server is not configured")
+
 func LoadRemoteConfig() (err error) {
+	// 未配置配置中心时(如本地运行和测试)只使用本地配置
+	if bootstrap.ConfigServerConfig.Id == "" {
+		return errConfigServerMissing
+	}
 	serviceInstance, err := discover.DiscoveryService(bootstrap.ConfigServerConfig.Id)
 	if err != nil {
 		return
@@ -95,6 +104,9 @@ func LoadRemoteConfig() (err error) {
 func Sub(key string, value interface{}) error {
 	Logger.Log("配置文件的前缀为：", key)
 	sub := viper.Sub(key)
+	if sub == nil {
+		return fmt.Errorf("config %s not found", key)
+	}
 	sub.AutomaticEnv()
 	sub.SetEnvPrefix(key)
 	return sub.Unmarshal(value)
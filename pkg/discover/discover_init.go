@@ -6,6 +6,8 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/lixichongAAA/seckill/pkg/bootstrap"
 	"github.com/lixichongAAA/seckill/pkg/common"
@@ -13,17 +15,57 @@ import (
 	uuid "github.com/satori/go.uuid"
 )
 
-var ConsulService DiscoveryClient
-var LoadBalance loadbalance.LoadBalance
-var Logger *log.Logger
+var LoadBalance loadbalance.LoadBalance = new(loadbalance.RandomLoadBalance)
+var Logger = log.New(os.Stderr, "", log.LstdFlags)
 var NoInstanceExistedErr = errors.New("no available client")
 
-func init() {
-	// 1.实例化一个 Consul 客户端，此处实例化了原生态实现版本
-	ConsulService = New(bootstrap.DiscoverConfig.Host, bootstrap.DiscoverConfig.Port)
-	LoadBalance = new(loadbalance.RandomLoadBalance)
-	Logger = log.New(os.Stderr, "", log.LstdFlags)
+var (
+	clientOnce      sync.Once
+	discoveryClient DiscoveryClient
+	clientErr       error
+)
+
+// Client 返回服务发现客户端, 首次调用时读取 bootstrap 配置并按 discover.type 创建
+// 创建失败(如静态实例列表有误或 discover.type 未知)时返回创建时的错误, 之后的调用返回同一错误
+func Client() (DiscoveryClient, error) {
+	clientOnce.Do(func() {
+		if discoveryClient == nil {
+			bootstrap.Load()
+			discoveryClient, clientErr = newClient(bootstrap.DiscoverConfig)
+			if clientErr != nil {
+				Logger.Println("Create discovery client error!", clientErr)
+			}
+		}
+	})
+	return discoveryClient, clientErr
+}
 
+// SetClient 替换服务发现客户端, 需在首次使用前调用, 用于测试或在同一进程中运行多个服务
+func SetClient(client DiscoveryClient) {
+	clientOnce.Do(func() {})
+	discoveryClient, clientErr = client, nil
+}
+
+func newClient(config bootstrap.DiscoverConf) (DiscoveryClient, error) {
+	switch config.Type {
+	case "", "consul":
+		// 此处实例化了原生态实现版本, 创建 Consul 客户端时不会连接 Consul
+		if client := New(config.Host, config.Port); client != nil {
+			return client, nil
+		}
+		return nil, fmt.Errorf("create consul client %s:%s failed", config.Host, config.Port)
+	case "static":
+		client, err := NewStaticDiscoveryClient(config.File, os.Getenv(staticEnv))
+		if err != nil {
+			return nil, fmt.Errorf("load static service instances: %v", err)
+		}
+		return client, nil
+	case "dns":
+		return NewDNSDiscoveryClient(config.Domain, time.Duration(config.Refresh)*time.Second), nil
+	case "memory":
+		return DefaultMemoryRegistry, nil
+	}
+	return nil, errors.New("unknown discover type " + config.Type)
 }
 
 func CheckHealth(writer http.ResponseWriter, reader *http.Request) {
@@ -35,7 +77,11 @@ func CheckHealth(writer http.ResponseWriter, reader *http.Request) {
 }
 
 func DiscoveryService(serviceName string) (*common.ServiceInstance, error) {
-	instances := ConsulService.DiscoverServices(serviceName, Logger)
+	client, err := Client()
+	if err != nil {
+		return nil, err
+	}
+	instances := client.DiscoverServices(serviceName, Logger)
 
 	if len(instances) < 1 {
 		Logger.Printf("no available client for %s.", serviceName)
@@ -46,9 +92,11 @@ func DiscoveryService(serviceName string) (*common.ServiceInstance, error) {
 }
 
 func Register() {
+	bootstrap.Load()
 	//// 实例失败，停止服务
-	if ConsulService == nil {
-		panic(0)
+	client, err := Client()
+	if err != nil {
+		panic(err)
 	}
 
 	//判空 instanceId,通过 go.uuid 获取一个服务实例ID
@@ -58,7 +106,7 @@ func Register() {
 		instanceId = bootstrap.DiscoverConfig.ServiceName + uuid.NewV4().String()
 	}
 
-	if !ConsulService.Register(instanceId, bootstrap.HttpConfig.Host, "/health",
+	if !client.Register(instanceId, bootstrap.HttpConfig.Host, "/health",
 		bootstrap.HttpConfig.Port, bootstrap.DiscoverConfig.ServiceName,
 		bootstrap.DiscoverConfig.Weight,
 		map[string]string{
@@ -73,9 +121,11 @@ func Register() {
 }
 
 func Deregister() {
+	bootstrap.Load()
 	//// 实例失败，停止服务
-	if ConsulService == nil {
-		panic(0)
+	client, err := Client()
+	if err != nil {
+		panic(err)
 	}
 	//判空 instanceId,通过 go.uuid 获取一个服务实例ID
 	instanceId := bootstrap.DiscoverConfig.InstanceId
@@ -83,7 +133,7 @@ func Deregister() {
 	if instanceId == "" {
 		instanceId = bootstrap.DiscoverConfig.ServiceName + "-" + uuid.NewV4().String()
 	}
-	if !ConsulService.DeRegister(instanceId, Logger) {
+	if !client.DeRegister(instanceId, Logger) {
 		Logger.Printf("deregister for service %s failed.", bootstrap.DiscoverConfig.ServiceName)
 		panic(0)
 	}
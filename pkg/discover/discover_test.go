@@ -0,0 +1,199 @@
+package discover
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lixichongAAA/seckill/pkg/bootstrap"
+	"github.com/lixichongAAA/seckill/pkg/common"
+)
+
+func TestStaticDiscoveryClient(t *testing.T) {
+	dir, err := ioutil.TempDir("", "discover")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "services.yaml")
+	content := `
+sk-app:
+  - host: 127.0.0.1
+    port: 9030
+    grpcPort: 9035
+    weight: 10
+user-service:
+  - host: 127.0.0.1
+    port: 9040
+`
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewStaticDiscoveryClient(file, "user-service=10.0.0.1:9040,10.0.0.2:9040:9041; oauth-service=10.0.0.3:9050")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]common.ServiceInstance{
+		"sk-app":        {{Host: "127.0.0.1", Port: 9030, GrpcPort: 9035, Weight: 10}},
+		"user-service":  {{Host: "10.0.0.1", Port: 9040, GrpcPort: 9039, Weight: 1}, {Host: "10.0.0.2", Port: 9040, GrpcPort: 9041, Weight: 1}},
+		"oauth-service": {{Host: "10.0.0.3", Port: 9050, GrpcPort: 9049, Weight: 1}},
+	}
+	for service, want := range expected {
+		got := client.DiscoverServices(service, nil)
+		if len(got) != len(want) {
+			t.Fatalf("%s: got %d instances, want %d", service, len(got), len(want))
+		}
+		for i := range want {
+			if *got[i] != want[i] {
+				t.Errorf("%s: instance %d is %+v, want %+v", service, i, *got[i], want[i])
+			}
+		}
+	}
+	if got := client.DiscoverServices("sk-admin", nil); len(got) != 0 {
+		t.Errorf("unknown service should have no instances: %v", got)
+	}
+
+	for _, env := range []string{"sk-app", "sk-app=127.0.0.1", "sk-app=127.0.0.1:port", "=127.0.0.1:9030"} {
+		if _, err := NewStaticDiscoveryClient("", env); err == nil {
+			t.Errorf("%q should be rejected", env)
+		}
+	}
+}
+
+func TestMemoryDiscoveryClient(t *testing.T) {
+	client := NewMemoryDiscoveryClient()
+	if !client.Register("sk-app-1", "127.0.0.1", "/health", "9030", "sk-app", 10, map[string]string{"rpcPort": "9035"}, nil, nil) ||
+		!client.Register("sk-app-2", "127.0.0.1", "/health", "9040", "sk-app", 10, nil, nil, nil) {
+		t.Fatal("register failed")
+	}
+	if client.Register("sk-app-3", "127.0.0.1", "/health", "port", "sk-app", 10, nil, nil, nil) {
+		t.Error("invalid port should be rejected")
+	}
+
+	first := client.DiscoverServices("sk-app", nil)
+	if len(first) != 2 || first[0].GrpcPort != 9035 || first[1].GrpcPort != 9039 {
+		t.Fatalf("unexpected instances %v", first)
+	}
+	if !client.DeRegister("sk-app-1", nil) {
+		t.Fatal("deregister failed")
+	}
+	if client.DeRegister("sk-app-1", nil) {
+		t.Error("deregister twice should fail")
+	}
+	second := client.DiscoverServices("sk-app", nil)
+	if len(second) != 1 || second[0].Port != 9040 {
+		t.Fatalf("unexpected instances %v", second)
+	}
+	if len(first) != 2 {
+		t.Error("returned list should not be modified")
+	}
+	client.DeRegister("sk-app-2", nil)
+	if got := client.DiscoverServices("sk-app", nil); len(got) != 0 {
+		t.Errorf("unexpected instances %v", got)
+	}
+}
+
+func TestDNSDiscoveryClient(t *testing.T) {
+	now := time.Now()
+	records := []*net.SRV{{Target: "b.node.consul.", Port: 9040, Weight: 1}, {Target: "a.node.consul.", Port: 9030}}
+	var lookupErr error
+	queried := make(chan string, 10)
+	client := NewDNSDiscoveryClient("service.consul.", time.Second)
+	client.nowFunc = func() time.Time { return now }
+	client.lookup = func(service, proto, name string) (string, []*net.SRV, error) {
+		queried <- name
+		return "", records, lookupErr
+	}
+
+	first := client.DiscoverServices("sk-app", nil)
+	if name := <-queried; name != "sk-app.service.consul" {
+		t.Errorf("queried %s", name)
+	}
+	if len(first) != 2 || first[0].Host != "a.node.consul" || first[0].GrpcPort != 9029 || first[0].Weight != 1 {
+		t.Fatalf("unexpected instances %v", first)
+	}
+
+	// 缓存未过期时不查询
+	if got := client.DiscoverServices("sk-app", nil); &got[0] != &first[0] {
+		t.Error("cached list should be returned")
+	}
+	if len(queried) != 0 {
+		t.Error("should not query before expiration")
+	}
+
+	// 过期后在后台刷新, 实例未变化(仅顺序不同)时保留原列表
+	refresh := func() []*common.ServiceInstance {
+		now = now.Add(2 * time.Second)
+		client.DiscoverServices("sk-app", nil)
+		<-queried
+		for i := 0; i < 100; i++ {
+			client.lock.Lock()
+			refreshing := client.entries["sk-app"].refreshing
+			client.lock.Unlock()
+			if !refreshing {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		return client.DiscoverServices("sk-app", nil)
+	}
+	records = []*net.SRV{records[1], records[0]}
+	if got := refresh(); &got[0] != &first[0] {
+		t.Error("unchanged instances should keep the list")
+	}
+
+	lookupErr = errors.New("lookup failed")
+	records = nil
+	if got := refresh(); &got[0] != &first[0] {
+		t.Error("lookup failure should keep the list")
+	}
+
+	lookupErr = nil
+	records = []*net.SRV{{Target: "c.node.consul.", Port: 9050}}
+	if got := refresh(); len(got) != 1 || got[0].Host != "c.node.consul" {
+		t.Errorf("unexpected instances %v", got)
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name   string
+		config bootstrap.DiscoverConf
+		check  func(DiscoveryClient) bool //为 nil 表示应创建失败
+	}{
+		{"consul by default", bootstrap.DiscoverConf{Host: "127.0.0.1", Port: "8500"}, func(c DiscoveryClient) bool {
+			_, ok := c.(*DiscoveryClientInstance)
+			return ok
+		}},
+		{"static", bootstrap.DiscoverConf{Type: "static"}, func(c DiscoveryClient) bool {
+			_, ok := c.(*StaticDiscoveryClient)
+			return ok
+		}},
+		{"dns", bootstrap.DiscoverConf{Type: "dns"}, func(c DiscoveryClient) bool {
+			_, ok := c.(*DNSDiscoveryClient)
+			return ok
+		}},
+		{"memory", bootstrap.DiscoverConf{Type: "memory"}, func(c DiscoveryClient) bool {
+			return c == DefaultMemoryRegistry
+		}},
+		{"unknown type", bootstrap.DiscoverConf{Type: "etcd"}, nil},
+		{"missing static file", bootstrap.DiscoverConf{Type: "static", File: "not-exist.yaml"}, nil},
+	}
+	for _, test := range tests {
+		client, err := newClient(test.config)
+		if test.check == nil {
+			if err == nil || client != nil {
+				t.Errorf("%s: expected an error, got %v", test.name, client)
+			}
+			continue
+		}
+		if err != nil || !test.check(client) {
+			t.Errorf("%s: unexpected client %T, err %v", test.name, client, err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package discover
+
+import (
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lixichongAAA/seckill/pkg/common"
+)
+
+const defaultDNSRefresh = 10 * time.Second
+
+// DNSDiscoveryClient 通过 DNS SRV 记录发现服务实例, 适用于 Consul DNS、Kubernetes Headless Service 等
+// 服务注册由 DNS 的提供方完成, 注册与注销不做任何操作
+type DNSDiscoveryClient struct {
+	domain  string
+	refresh time.Duration
+	lookup  func(service, proto, name string) (string, []*net.SRV, error)
+	nowFunc func() time.Time
+
+	lock    sync.Mutex
+	entries map[string]*dnsEntry
+}
+
+type dnsEntry struct {
+	instances  []*common.ServiceInstance
+	expires    time.Time
+	refreshing bool
+}
+
+// NewDNSDiscoveryClient 查询 <服务名>.<domain> 的 SRV 记录, domain 为空时直接查询服务名;
+// 实例列表缓存 refresh 时间, 为 0 时使用默认的 10 秒
+func NewDNSDiscoveryClient(domain string, refresh time.Duration) *DNSDiscoveryClient {
+	if refresh <= 0 {
+		refresh = defaultDNSRefresh
+	}
+	return &DNSDiscoveryClient{
+		domain:  strings.Trim(domain, "."),
+		refresh: refresh,
+		lookup:  net.LookupSRV,
+		nowFunc: time.Now,
+		entries: make(map[string]*dnsEntry),
+	}
+}
+
+func (dnsClient *DNSDiscoveryClient) Register(instanceId, svcHost, healthCheckUrl, svcPort string, svcName string, weight int, meta map[string]string, tags []string, logger *log.Logger) bool {
+	if logger != nil {
+		logger.Println("DNS discovery, register ignored.")
+	}
+	return true
+}
+
+func (dnsClient *DNSDiscoveryClient) DeRegister(instanceId string, logger *log.Logger) bool {
+	return true
+}
+
+// DiscoverServices 首次查询某个服务时同步查询 DNS, 之后返回缓存的实例列表, 缓存过期时在后台刷新
+// 与 Consul 实例相同, 列表只在实例变化时替换
+func (dnsClient *DNSDiscoveryClient) DiscoverServices(serviceName string, logger *log.Logger) []*common.ServiceInstance {
+	dnsClient.lock.Lock()
+	entry, ok := dnsClient.entries[serviceName]
+	if !ok {
+		dnsClient.lock.Unlock()
+		instances, _ := dnsClient.resolve(serviceName, logger)
+		dnsClient.lock.Lock()
+		defer dnsClient.lock.Unlock()
+		if entry, ok = dnsClient.entries[serviceName]; ok {
+			return entry.instances
+		}
+		dnsClient.entries[serviceName] = &dnsEntry{
+			instances: instances,
+			expires:   dnsClient.nowFunc().Add(dnsClient.refresh),
+		}
+		return instances
+	}
+	defer dnsClient.lock.Unlock()
+	if !entry.refreshing && !dnsClient.nowFunc().Before(entry.expires) {
+		entry.refreshing = true
+		go dnsClient.update(serviceName, entry, logger)
+	}
+	return entry.instances
+}
+
+// update 刷新服务的实例列表, 查询失败时保留原列表
+func (dnsClient *DNSDiscoveryClient) update(serviceName string, entry *dnsEntry, logger *log.Logger) {
+	instances, err := dnsClient.resolve(serviceName, logger)
+	dnsClient.lock.Lock()
+	defer dnsClient.lock.Unlock()
+	entry.refreshing = false
+	entry.expires = dnsClient.nowFunc().Add(dnsClient.refresh)
+	if err == nil && !equalInstances(entry.instances, instances) {
+		entry.instances = instances
+	}
+}
+
+func (dnsClient *DNSDiscoveryClient) resolve(serviceName string, logger *log.Logger) ([]*common.ServiceInstance, error) {
+	name := serviceName
+	if dnsClient.domain != "" {
+		name += "." + dnsClient.domain
+	}
+	_, records, err := dnsClient.lookup("", "", name)
+	if err != nil {
+		if logger != nil {
+			logger.Println("Discover Service Error!", err)
+		}
+		return []*common.ServiceInstance{}, err
+	}
+	instances := make([]*common.ServiceInstance, 0, len(records))
+	for _, record := range records {
+		weight := int(record.Weight)
+		if weight <= 0 {
+			weight = 1
+		}
+		// SRV 记录只有一个端口, 与 Consul 实例相同, RPC 端口为 port-1
+		instances = append(instances, &common.ServiceInstance{
+			Host:     strings.TrimSuffix(record.Target, "."),
+			Port:     int(record.Port),
+			GrpcPort: int(record.Port) - 1,
+			Weight:   weight,
+		})
+	}
+	// 同优先级的记录每次查询的顺序是随机的, 排序后再比较实例是否变化
+	sort.Slice(instances, func(i, j int) bool {
+		if instances[i].Host != instances[j].Host {
+			return instances[i].Host < instances[j].Host
+		}
+		return instances[i].Port < instances[j].Port
+	})
+	return instances, nil
+}
+
+// equalInstances 比较两个已排序的实例列表的内容
+func equalInstances(a, b []*common.ServiceInstance) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if *a[i] != *b[i] {
+			return false
+		}
+	}
+	return true
+}
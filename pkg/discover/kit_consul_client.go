@@ -115,7 +115,13 @@ func (consulClient *DiscoveryClientInstance) DiscoverServices(serviceName string
 			params := make(map[string]interface{})
 			params["type"] = "service"
 			params["service"] = serviceName
-			plan, _ := watch.Parse(params)
+			plan, err := watch.Parse(params)
+			if err != nil {
+				if logger != nil {
+					logger.Println("Watch Service Error!", err)
+				}
+				return
+			}
 			plan.Handler = func(u uint64, i interface{}) {
 				if i == nil {
 					return
@@ -0,0 +1,102 @@
+package discover
+
+import (
+	"log"
+	"strconv"
+	"sync"
+
+	"github.com/lixichongAAA/seckill/pkg/common"
+)
+
+// DefaultMemoryRegistry discover.type 为 memory 时使用的进程内注册中心, 同一进程中的服务共用
+var DefaultMemoryRegistry = NewMemoryDiscoveryClient()
+
+// MemoryDiscoveryClient 进程内的服务注册中心, 不做健康检查, 用于测试和在同一进程中运行多个服务
+type MemoryDiscoveryClient struct {
+	lock      sync.RWMutex
+	services  map[string][]*common.ServiceInstance //服务名 -> 实例列表
+	instances map[string]memoryInstance            //实例Id -> 实例
+}
+
+type memoryInstance struct {
+	service  string
+	instance *common.ServiceInstance
+}
+
+func NewMemoryDiscoveryClient() *MemoryDiscoveryClient {
+	return &MemoryDiscoveryClient{
+		services:  make(map[string][]*common.ServiceInstance),
+		instances: make(map[string]memoryInstance),
+	}
+}
+
+// Register 注册实例, 同一实例Id重复注册时替换原实例
+func (memoryClient *MemoryDiscoveryClient) Register(instanceId, svcHost, healthCheckUrl, svcPort string, svcName string, weight int, meta map[string]string, tags []string, logger *log.Logger) bool {
+	port, err := strconv.Atoi(svcPort)
+	if err != nil {
+		if logger != nil {
+			logger.Println("Register Service Error!", err)
+		}
+		return false
+	}
+	// 与 Consul 实例相同, 未指定 rpcPort 时使用 port-1
+	rpcPort := port - 1
+	if rpcPortString, ok := meta["rpcPort"]; ok {
+		rpcPort, _ = strconv.Atoi(rpcPortString)
+	}
+	instance := &common.ServiceInstance{
+		Host:     svcHost,
+		Port:     port,
+		GrpcPort: rpcPort,
+		Weight:   weight,
+	}
+
+	memoryClient.lock.Lock()
+	defer memoryClient.lock.Unlock()
+	memoryClient.remove(instanceId)
+	memoryClient.instances[instanceId] = memoryInstance{service: svcName, instance: instance}
+	// 实例变化时替换整个列表, 已返回给调用方的列表不受影响
+	instances := make([]*common.ServiceInstance, 0, len(memoryClient.services[svcName])+1)
+	instances = append(instances, memoryClient.services[svcName]...)
+	memoryClient.services[svcName] = append(instances, instance)
+	return true
+}
+
+func (memoryClient *MemoryDiscoveryClient) DeRegister(instanceId string, logger *log.Logger) bool {
+	memoryClient.lock.Lock()
+	defer memoryClient.lock.Unlock()
+	if !memoryClient.remove(instanceId) {
+		if logger != nil {
+			logger.Printf("instance %s not registered.", instanceId)
+		}
+		return false
+	}
+	return true
+}
+
+func (memoryClient *MemoryDiscoveryClient) DiscoverServices(serviceName string, logger *log.Logger) []*common.ServiceInstance {
+	memoryClient.lock.RLock()
+	defer memoryClient.lock.RUnlock()
+	return memoryClient.services[serviceName]
+}
+
+// remove 调用方需持有锁
+func (memoryClient *MemoryDiscoveryClient) remove(instanceId string) bool {
+	registered, ok := memoryClient.instances[instanceId]
+	if !ok {
+		return false
+	}
+	delete(memoryClient.instances, instanceId)
+	var instances []*common.ServiceInstance
+	for _, instance := range memoryClient.services[registered.service] {
+		if instance != registered.instance {
+			instances = append(instances, instance)
+		}
+	}
+	if len(instances) == 0 {
+		delete(memoryClient.services, registered.service)
+	} else {
+		memoryClient.services[registered.service] = instances
+	}
+	return true
+}
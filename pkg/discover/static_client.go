@@ -0,0 +1,126 @@
+package discover
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/lixichongAAA/seckill/pkg/common"
+	"github.com/spf13/viper"
+)
+
+// staticEnv 静态服务实例列表的环境变量, 格式为 服务名=host:port[:grpcPort],host:port;服务名=...
+// 未指定 grpcPort 时与 Consul 实例相同, 使用 port-1
+const staticEnv = "DISCOVER_STATIC"
+
+// StaticDiscoveryClient 从文件或环境变量读取固定的服务实例列表, 不依赖注册中心, 用于本地运行和测试
+// 服务注册与注销不做任何操作, 实例列表在创建后不再变化
+type StaticDiscoveryClient struct {
+	instances map[string][]*common.ServiceInstance
+}
+
+// NewStaticDiscoveryClient file 为 yaml 或 json 格式的实例列表文件, 如:
+//
+//	sk-app:
+//	  - host: 127.0.0.1
+//	    port: 9030
+//	    grpcPort: 9031
+//	    weight: 10
+//
+// env 为环境变量格式的实例列表, 其中的服务覆盖文件中的同名服务; file 和 env 均可为空
+func NewStaticDiscoveryClient(file, env string) (*StaticDiscoveryClient, error) {
+	client := &StaticDiscoveryClient{
+		instances: make(map[string][]*common.ServiceInstance),
+	}
+	if file != "" {
+		v := viper.New()
+		v.SetConfigFile(file)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, err
+		}
+		if err := v.Unmarshal(&client.instances); err != nil {
+			return nil, err
+		}
+	}
+	if env != "" {
+		services, err := parseStaticInstances(env)
+		if err != nil {
+			return nil, err
+		}
+		for name, instances := range services {
+			client.instances[name] = instances
+		}
+	}
+	for _, instances := range client.instances {
+		for _, instance := range instances {
+			if instance.GrpcPort == 0 {
+				instance.GrpcPort = instance.Port - 1
+			}
+			if instance.Weight <= 0 {
+				instance.Weight = 1
+			}
+		}
+	}
+	return client, nil
+}
+
+func (staticClient *StaticDiscoveryClient) Register(instanceId, svcHost, healthCheckUrl, svcPort string, svcName string, weight int, meta map[string]string, tags []string, logger *log.Logger) bool {
+	if logger != nil {
+		logger.Println("Static discovery, register ignored.")
+	}
+	return true
+}
+
+func (staticClient *StaticDiscoveryClient) DeRegister(instanceId string, logger *log.Logger) bool {
+	return true
+}
+
+// DiscoverServices 返回配置的实例列表, 每次返回同一个切片, 调用方不应修改
+func (staticClient *StaticDiscoveryClient) DiscoverServices(serviceName string, logger *log.Logger) []*common.ServiceInstance {
+	return staticClient.instances[serviceName]
+}
+
+// parseStaticInstances 解析环境变量格式的实例列表
+func parseStaticInstances(value string) (map[string][]*common.ServiceInstance, error) {
+	services := make(map[string][]*common.ServiceInstance)
+	for _, service := range strings.Split(value, ";") {
+		service = strings.TrimSpace(service)
+		if service == "" {
+			continue
+		}
+		parts := strings.SplitN(service, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid static service %q", service)
+		}
+		var instances []*common.ServiceInstance
+		for _, addr := range strings.Split(parts[1], ",") {
+			instance, err := parseStaticInstance(strings.TrimSpace(addr))
+			if err != nil {
+				return nil, err
+			}
+			instances = append(instances, instance)
+		}
+		services[strings.TrimSpace(parts[0])] = instances
+	}
+	return services, nil
+}
+
+// parseStaticInstance 解析 host:port[:grpcPort]
+func parseStaticInstance(addr string) (*common.ServiceInstance, error) {
+	fields := strings.Split(addr, ":")
+	if len(fields) < 2 || len(fields) > 3 || fields[0] == "" {
+		return nil, fmt.Errorf("invalid static instance %q", addr)
+	}
+	instance := &common.ServiceInstance{Host: fields[0]}
+	var err error
+	if instance.Port, err = strconv.Atoi(fields[1]); err != nil {
+		return nil, fmt.Errorf("invalid static instance %q: %v", addr, err)
+	}
+	if len(fields) == 3 {
+		if instance.GrpcPort, err = strconv.Atoi(fields[2]); err != nil {
+			return nil, fmt.Errorf("invalid static instance %q: %v", addr, err)
+		}
+	}
+	return instance, nil
+}
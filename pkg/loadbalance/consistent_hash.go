@@ -0,0 +1,203 @@
+package loadbalance
+
+import (
+	"errors"
+	"hash/crc32"
+	"hash/fnv"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lixichongAAA/seckill/pkg/common"
+)
+
+const (
+	virtualNodes       = 100              //每个实例在哈希环上的虚拟节点数, 乘以实例权重
+	maxHashWeight      = 10               //计算虚拟节点数时权重的上限
+	maxCachedRings     = 64               //缓存的哈希环数量上限
+	maxTrackedMembers  = 10000            //记录加入时间的实例数上限
+	DefaultHashWarmUp  = 60 * time.Second //新实例的默认预热时长
+	warmUpFractionBase = 10000
+)
+
+// ConsistentHashLoadBalance 一致性哈希负载均衡
+// 相同 key(如用户 id)的请求总是落到同一实例; 实例上下线时只有原本落在该实例上的 key 会改变归属,
+// 其余 key 保持不变; 没有 key 的请求随机选择实例
+// 新加入的实例有一段预热时间, 期间应归属新实例的 key 按时间比例逐步迁移过去, 其余仍留在原实例,
+// 避免实例扩容时大量用户同时切换实例, 丢失原实例上的限流等状态
+type ConsistentHashLoadBalance struct {
+	lock    sync.Mutex
+	rings   map[string]*hashRing //实例列表签名 -> 哈希环
+	joined  map[string]time.Time //实例地址 -> 加入时间, 首次出现的实例列表中的实例视为早已加入
+	warmUp  time.Duration
+	nowFunc func() time.Time
+}
+
+type hashRing struct {
+	hashes    []uint32
+	instances map[uint32]*common.ServiceInstance
+}
+
+func NewConsistentHashLoadBalance() *ConsistentHashLoadBalance {
+	return &ConsistentHashLoadBalance{
+		rings:   make(map[string]*hashRing),
+		joined:  make(map[string]time.Time),
+		warmUp:  DefaultHashWarmUp,
+		nowFunc: time.Now,
+	}
+}
+
+// SetWarmUp 设置新实例的预热时长, 为 0 时新实例加入后立即接管归属它的 key
+func (loadBalance *ConsistentHashLoadBalance) SetWarmUp(warmUp time.Duration) {
+	loadBalance.lock.Lock()
+	loadBalance.warmUp = warmUp
+	loadBalance.lock.Unlock()
+}
+
+func (loadBalance *ConsistentHashLoadBalance) SelectService(services []*common.ServiceInstance) (*common.ServiceInstance, error) {
+	if len(services) == 0 {
+		return nil, errors.New("service instances are not exist")
+	}
+	return services[rand.Intn(len(services))], nil
+}
+
+// SelectServiceByKey 在哈希环上顺时针查找 key 对应的第一个虚拟节点
+func (loadBalance *ConsistentHashLoadBalance) SelectServiceByKey(services []*common.ServiceInstance, key string) (*common.ServiceInstance, error) {
+	if len(services) == 0 {
+		return nil, errors.New("service instances are not exist")
+	}
+	hash := crc32.ChecksumIEEE([]byte(key))
+	owner := loadBalance.ring(services).lookup(hash)
+
+	// 归属预热中的实例时, 只有落在已预热比例内的 key 迁移过去, 其余 key 在不含预热实例的哈希环上查找原实例
+	warming := loadBalance.warming(services)
+	progress, ok := warming[instanceAddr(owner)]
+	if !ok || keyFraction(key) < progress {
+		return owner, nil
+	}
+	stable := make([]*common.ServiceInstance, 0, len(services))
+	for _, instance := range services {
+		if _, ok := warming[instanceAddr(instance)]; !ok {
+			stable = append(stable, instance)
+		}
+	}
+	if len(stable) == 0 {
+		return owner, nil
+	}
+	return loadBalance.ring(stable).lookup(hash), nil
+}
+
+// warming 记录实例的加入时间, 返回预热中的实例地址及其预热进度
+// 实例列表中有已知实例时, 其余实例视为新加入; 否则为首次看到该服务, 所有实例视为早已加入
+func (loadBalance *ConsistentHashLoadBalance) warming(services []*common.ServiceInstance) map[string]float64 {
+	loadBalance.lock.Lock()
+	defer loadBalance.lock.Unlock()
+	now := loadBalance.nowFunc()
+	if len(loadBalance.joined) >= maxTrackedMembers {
+		loadBalance.joined = make(map[string]time.Time)
+	}
+
+	known := false
+	for _, instance := range services {
+		if _, ok := loadBalance.joined[instanceAddr(instance)]; ok {
+			known = true
+			break
+		}
+	}
+	var warming map[string]float64
+	for _, instance := range services {
+		addr := instanceAddr(instance)
+		joinedAt, ok := loadBalance.joined[addr]
+		if !ok {
+			if !known {
+				joinedAt = time.Time{}
+			} else {
+				joinedAt = now
+			}
+			loadBalance.joined[addr] = joinedAt
+		}
+		if elapsed := now.Sub(joinedAt); loadBalance.warmUp > 0 && elapsed < loadBalance.warmUp {
+			if warming == nil {
+				warming = make(map[string]float64)
+			}
+			warming[addr] = float64(elapsed) / float64(loadBalance.warmUp)
+		}
+	}
+	return warming
+}
+
+// keyFraction 将 key 均匀映射到 [0, 1), 与 key 在哈希环上的位置无关
+func keyFraction(key string) float64 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return float64(h.Sum32()%warmUpFractionBase) / warmUpFractionBase
+}
+
+// lookup 在哈希环上顺时针查找第一个虚拟节点
+func (ring *hashRing) lookup(hash uint32) *common.ServiceInstance {
+	i := sort.Search(len(ring.hashes), func(i int) bool {
+		return ring.hashes[i] >= hash
+	})
+	if i == len(ring.hashes) {
+		i = 0
+	}
+	return ring.instances[ring.hashes[i]]
+}
+
+// ring 返回实例列表对应的哈希环, 实例列表不变时复用已构建的哈希环
+func (loadBalance *ConsistentHashLoadBalance) ring(services []*common.ServiceInstance) *hashRing {
+	addrs := make([]string, 0, len(services))
+	for _, instance := range services {
+		addrs = append(addrs, instanceAddr(instance)+"*"+strconv.Itoa(hashWeight(instance)))
+	}
+	sort.Strings(addrs)
+	signature := strings.Join(addrs, ",")
+
+	loadBalance.lock.Lock()
+	defer loadBalance.lock.Unlock()
+	if ring, ok := loadBalance.rings[signature]; ok {
+		return ring
+	}
+	if len(loadBalance.rings) >= maxCachedRings {
+		loadBalance.rings = make(map[string]*hashRing)
+	}
+	ring := newHashRing(services)
+	loadBalance.rings[signature] = ring
+	return ring
+}
+
+func newHashRing(services []*common.ServiceInstance) *hashRing {
+	ring := &hashRing{
+		instances: make(map[uint32]*common.ServiceInstance),
+	}
+	for _, instance := range services {
+		addr := instanceAddr(instance)
+		for i := 0; i < virtualNodes*hashWeight(instance); i++ {
+			hash := crc32.ChecksumIEEE([]byte(addr + "#" + strconv.Itoa(i)))
+			// 哈希冲突时保留地址较小的实例, 保证与实例列表的顺序无关
+			if existing, ok := ring.instances[hash]; ok && instanceAddr(existing) < addr {
+				continue
+			} else if !ok {
+				ring.hashes = append(ring.hashes, hash)
+			}
+			ring.instances[hash] = instance
+		}
+	}
+	sort.Slice(ring.hashes, func(i, j int) bool {
+		return ring.hashes[i] < ring.hashes[j]
+	})
+	return ring
+}
+
+func hashWeight(instance *common.ServiceInstance) int {
+	switch {
+	case instance.Weight <= 0:
+		return 1
+	case instance.Weight > maxHashWeight:
+		return maxHashWeight
+	}
+	return instance.Weight
+}
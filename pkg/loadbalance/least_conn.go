@@ -0,0 +1,68 @@
+package loadbalance
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+
+	"github.com/lixichongAAA/seckill/pkg/common"
+)
+
+// LeastConnLoadBalance 最少进行中请求负载均衡
+// 选择进行中请求数最少的实例, 数量相同时随机选择; 处理较慢的实例积压的请求多, 会自动分到更少的新请求
+// 调用方需在请求结束后调用 Release(或 loadbalance.Done)释放实例
+type LeastConnLoadBalance struct {
+	lock        sync.Mutex
+	outstanding map[string]int //实例地址 -> 进行中的请求数, 为 0 时删除
+}
+
+func NewLeastConnLoadBalance() *LeastConnLoadBalance {
+	return &LeastConnLoadBalance{
+		outstanding: make(map[string]int),
+	}
+}
+
+func (loadBalance *LeastConnLoadBalance) SelectService(services []*common.ServiceInstance) (*common.ServiceInstance, error) {
+	if len(services) == 0 {
+		return nil, errors.New("service instances are not exist")
+	}
+
+	loadBalance.lock.Lock()
+	defer loadBalance.lock.Unlock()
+
+	var best *common.ServiceInstance
+	least, ties := 0, 0
+	for _, instance := range services {
+		if instance == nil {
+			continue
+		}
+		count := loadBalance.outstanding[instanceAddr(instance)]
+		switch {
+		case best == nil || count < least:
+			best, least, ties = instance, count, 1
+		case count == least:
+			// 蓄水池抽样, 在进行中请求数相同的实例中等概率选择
+			ties++
+			if rand.Intn(ties) == 0 {
+				best = instance
+			}
+		}
+	}
+	if best == nil {
+		return nil, errors.New("service instances are not exist")
+	}
+	loadBalance.outstanding[instanceAddr(best)]++
+	return best, nil
+}
+
+// Release 请求结束后减少实例的进行中请求数
+func (loadBalance *LeastConnLoadBalance) Release(instance *common.ServiceInstance) {
+	addr := instanceAddr(instance)
+	loadBalance.lock.Lock()
+	defer loadBalance.lock.Unlock()
+	if loadBalance.outstanding[addr] <= 1 {
+		delete(loadBalance.outstanding, addr)
+		return
+	}
+	loadBalance.outstanding[addr]--
+}
@@ -1,17 +1,82 @@
 package loadbalance
 
 import (
+	"context"
 	"errors"
 	"math/rand"
+	"strconv"
+	"sync"
 
 	"github.com/lixichongAAA/seckill/pkg/common"
 )
 
+// 负载均衡策略名称, 用于在配置中选择策略
+const (
+	Random           = "random"
+	WeightRoundRobin = "weight_round_robin"
+	LeastConn        = "least_conn"
+	ConsistentHash   = "consistent_hash"
+)
+
 // 负载均衡器
 type LoadBalance interface {
 	SelectService(service []*common.ServiceInstance) (*common.ServiceInstance, error)
 }
 
+// KeyedLoadBalance 按请求的 key(如用户 id)选择实例的负载均衡器, 相同 key 的请求落到同一实例
+type KeyedLoadBalance interface {
+	LoadBalance
+	SelectServiceByKey(service []*common.ServiceInstance, key string) (*common.ServiceInstance, error)
+}
+
+// Releaser 统计实例进行中请求数的负载均衡器实现该接口, 选中的实例在请求结束后需要释放
+type Releaser interface {
+	Release(instance *common.ServiceInstance)
+}
+
+// New 按策略名称创建负载均衡器, 名称为空时使用随机策略
+func New(name string) (LoadBalance, error) {
+	switch name {
+	case "", Random:
+		return &RandomLoadBalance{}, nil
+	case WeightRoundRobin:
+		return &WeightRoundRobinLoadBalance{}, nil
+	case LeastConn:
+		return NewLeastConnLoadBalance(), nil
+	case ConsistentHash:
+		return NewConsistentHashLoadBalance(), nil
+	}
+	return nil, errors.New("unknown load balance " + name)
+}
+
+type hashKeyCtxKey struct{}
+
+// WithHashKey 在 context 中设置一致性哈希使用的 key
+func WithHashKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, hashKeyCtxKey{}, key)
+}
+
+// Select 选择一个实例, ctx 中设置了 hash key 且负载均衡器支持按 key 选择时按 key 选择
+func Select(ctx context.Context, loadBalance LoadBalance, services []*common.ServiceInstance) (*common.ServiceInstance, error) {
+	if keyed, ok := loadBalance.(KeyedLoadBalance); ok {
+		if key, _ := ctx.Value(hashKeyCtxKey{}).(string); key != "" {
+			return keyed.SelectServiceByKey(services, key)
+		}
+	}
+	return loadBalance.SelectService(services)
+}
+
+// Done 请求结束后通知负载均衡器释放实例, 不统计进行中请求数的负载均衡器忽略
+func Done(loadBalance LoadBalance, instance *common.ServiceInstance) {
+	if releaser, ok := loadBalance.(Releaser); ok && instance != nil {
+		releaser.Release(instance)
+	}
+}
+
+func instanceAddr(instance *common.ServiceInstance) string {
+	return instance.Host + ":" + strconv.Itoa(instance.Port)
+}
+
 type RandomLoadBalance struct {
 }
 
@@ -28,29 +93,47 @@ func (loadBalance *RandomLoadBalance) SelectService(services []*common.ServiceIn
 	return services[rand.Intn(len(services))], nil
 }
 
+// WeightRoundRobinLoadBalance 的当前权重保存在负载均衡器内部并加锁更新, 可以被多个协程同时使用;
+// 服务发现返回的实例列表被所有请求共享, 因此不再修改实例的 CurWeight
+// 不在列表中的实例会被当作已下线清理, 因此一个负载均衡器只能用于同一服务的实例
 type WeightRoundRobinLoadBalance struct {
+	lock    sync.Mutex
+	current map[string]int //实例地址 -> 当前权重
 }
 
 // 权重平滑负载均衡
-// 该策略会根据各个服务的权重比例，将请求平滑的分配到各个服务实例中
+// 该策略会根据各个服务的权重比例，将请求平滑的分配到各个服务实例中, 未配置权重的实例按权重 1 计算
 func (loadBalance *WeightRoundRobinLoadBalance) SelectService(services []*common.ServiceInstance) (best *common.ServiceInstance, err error) {
 
 	if services == nil || len(services) == 0 {
 		return nil, errors.New("service instances are not exist")
 	}
 
+	loadBalance.lock.Lock()
+	defer loadBalance.lock.Unlock()
+	if loadBalance.current == nil {
+		loadBalance.current = make(map[string]int)
+	}
+
 	total := 0
+	bestAddr := ""
 	for i := 0; i < len(services); i++ {
 		w := services[i]
 		if w == nil {
 			continue
 		}
 
-		w.CurWeight += w.Weight
+		weight := w.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		addr := instanceAddr(w)
+		loadBalance.current[addr] += weight
 
-		total += w.Weight
-		if best == nil || w.CurWeight > best.CurWeight {
+		total += weight
+		if best == nil || loadBalance.current[addr] > loadBalance.current[bestAddr] {
 			best = w
+			bestAddr = addr
 		}
 	}
 
@@ -58,7 +141,21 @@ func (loadBalance *WeightRoundRobinLoadBalance) SelectService(services []*common
 		return nil, nil
 	}
 
-	best.CurWeight -= total
+	loadBalance.current[bestAddr] -= total
+	// 清理已下线实例的当前权重
+	if len(loadBalance.current) > len(services) {
+		online := make(map[string]bool, len(services))
+		for _, w := range services {
+			if w != nil {
+				online[instanceAddr(w)] = true
+			}
+		}
+		for addr := range loadBalance.current {
+			if !online[addr] {
+				delete(loadBalance.current, addr)
+			}
+		}
+	}
 	return best, nil
 }
 
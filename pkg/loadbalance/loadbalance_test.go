@@ -0,0 +1,148 @@
+package loadbalance
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lixichongAAA/seckill/pkg/common"
+)
+
+func newInstances(weights ...int) []*common.ServiceInstance {
+	instances := make([]*common.ServiceInstance, 0, len(weights))
+	for i, weight := range weights {
+		instances = append(instances, &common.ServiceInstance{Host: "127.0.0.1", Port: 9000 + i, Weight: weight})
+	}
+	return instances
+}
+
+func TestWeightRoundRobin(t *testing.T) {
+	instances := newInstances(3, 2, 1)
+	loadBalance := &WeightRoundRobinLoadBalance{}
+	expected := []int{0, 1, 0, 2, 1, 0}
+	for i, want := range expected {
+		got, _ := loadBalance.SelectService(instances)
+		if got != instances[want] {
+			t.Fatalf("request %d: got port %d, want %d", i, got.Port, instances[want].Port)
+		}
+	}
+
+	var wg sync.WaitGroup
+	counts := make([]int, len(instances))
+	var lock sync.Mutex
+	for g := 0; g < 6; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				got, _ := loadBalance.SelectService(instances)
+				lock.Lock()
+				counts[got.Port-9000]++
+				lock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if counts[0] != 3000 || counts[1] != 2000 || counts[2] != 1000 {
+		t.Errorf("unexpected distribution under concurrency: %v", counts)
+	}
+}
+
+func TestLeastConn(t *testing.T) {
+	instances := newInstances(1, 1, 1)
+	loadBalance := NewLeastConnLoadBalance()
+	first, _ := loadBalance.SelectService(instances)
+	second, _ := loadBalance.SelectService(instances)
+	third, _ := loadBalance.SelectService(instances)
+	if first == second || second == third || first == third {
+		t.Fatal("each instance should get one request before any gets two")
+	}
+	Done(loadBalance, second)
+	if next, _ := loadBalance.SelectService(instances); next != second {
+		t.Errorf("released instance should be selected, got port %d", next.Port)
+	}
+}
+
+func TestConsistentHash(t *testing.T) {
+	instances := newInstances(1, 1, 1, 1)
+	loadBalance := NewConsistentHashLoadBalance()
+	ctx := context.Background()
+
+	owners := make(map[string]*common.ServiceInstance)
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		owner, _ := Select(WithHashKey(ctx, key), loadBalance, instances)
+		if again, _ := Select(WithHashKey(ctx, key), loadBalance, instances); again != owner {
+			t.Fatalf("key %s moved without membership change", key)
+		}
+		owners[key] = owner
+	}
+
+	// 下线一个实例后, 只有原本属于该实例的 key 改变归属
+	removed := instances[1]
+	remaining := []*common.ServiceInstance{instances[0], instances[2], instances[3]}
+	moved := 0
+	for key, owner := range owners {
+		now, _ := Select(WithHashKey(ctx, key), loadBalance, remaining)
+		if owner != removed && now != owner {
+			t.Fatalf("key %s moved from a remaining instance", key)
+		}
+		if owner == removed {
+			moved++
+		}
+	}
+	if moved == 0 || moved > 500 {
+		t.Errorf("unexpected number of keys on the removed instance: %d", moved)
+	}
+}
+
+func TestConsistentHashWarmUp(t *testing.T) {
+	instances := newInstances(1, 1, 1, 1)
+	loadBalance := NewConsistentHashLoadBalance()
+	now := time.Now()
+	loadBalance.nowFunc = func() time.Time { return now }
+
+	before := make(map[string]*common.ServiceInstance)
+	for i := 0; i < 2000; i++ {
+		key := strconv.Itoa(i)
+		before[key], _ = loadBalance.SelectServiceByKey(instances[:3], key)
+	}
+
+	// 扩容后, 归属新实例的 key 在预热期间按比例逐步迁移, 其余 key 不受影响
+	moved := func() int {
+		count := 0
+		for key, owner := range before {
+			got, _ := loadBalance.SelectServiceByKey(instances, key)
+			if got != owner && got != instances[3] {
+				t.Fatalf("key %s moved to an old instance", key)
+			}
+			if got == instances[3] {
+				count++
+			}
+		}
+		return count
+	}
+	if n := moved(); n != 0 {
+		t.Fatalf("%d keys moved right after the instance joined", n)
+	}
+	now = now.Add(DefaultHashWarmUp / 2)
+	half := moved()
+	now = now.Add(DefaultHashWarmUp / 2)
+	all := moved()
+	if all == 0 || half == 0 || half >= all {
+		t.Errorf("keys should move gradually, half way %d, finally %d", half, all)
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, name := range []string{"", Random, WeightRoundRobin, LeastConn, ConsistentHash} {
+		if _, err := New(name); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	if _, err := New("unknown"); err == nil {
+		t.Error("unknown strategy should fail")
+	}
+}
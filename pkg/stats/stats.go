@@ -0,0 +1,172 @@
+package stats
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis"
+	conf "github.com/lixichongAAA/seckill/pkg/config"
+)
+
+// 每个秒杀活动的计数保存在 Redis 的一个 hash 中, 由 sk-app 和 sk-core 写入, sk-admin 读取
+const (
+	FieldRequests = "requests" //sk-app 收到的秒杀请求数
+	FieldAdmitted = "admitted" //sk-app 送入 sk-core 的请求数
+	FieldHandled  = "handled"  //sk-core 处理的请求数
+	FieldWinners  = "winners"  //sk-core 抢购成功的请求数
+
+	rejectPrefix = "reject:" //按错误码统计的拒绝数, 如 reject:1004
+
+	keyPrefix     = "sec_kill_stats:"
+	keyExpiration = time.Hour * 24 * 7
+	flushInterval = time.Second
+)
+
+// Key 返回活动计数所在的 hash 名称
+func Key(activityId int) string {
+	return keyPrefix + strconv.Itoa(activityId)
+}
+
+func RejectField(code int) string {
+	return rejectPrefix + strconv.Itoa(code)
+}
+
+// ActivityStats 单个秒杀活动的计数
+type ActivityStats struct {
+	ActivityId int           `json:"activity_id"`
+	Requests   int64         `json:"requests"`
+	Admitted   int64         `json:"admitted"`
+	Handled    int64         `json:"handled"`
+	Winners    int64         `json:"winners"`
+	Rejections map[int]int64 `json:"rejections"` //错误码 -> 次数
+}
+
+// Load 从 Redis 读取活动计数, 活动尚无计数时各项为 0
+func Load(conn *redis.Client, activityId int) (*ActivityStats, error) {
+	data, err := conn.HGetAll(Key(activityId)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ActivityStats{
+		ActivityId: activityId,
+		Rejections: make(map[int]int64),
+	}
+	for field, value := range data {
+		count, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		switch field {
+		case FieldRequests:
+			result.Requests = count
+		case FieldAdmitted:
+			result.Admitted = count
+		case FieldHandled:
+			result.Handled = count
+		case FieldWinners:
+			result.Winners = count
+		default:
+			if !strings.HasPrefix(field, rejectPrefix) {
+				continue
+			}
+			code, err := strconv.Atoi(strings.TrimPrefix(field, rejectPrefix))
+			if err != nil {
+				continue
+			}
+			result.Rejections[code] = count
+		}
+	}
+	return result, nil
+}
+
+// DefaultRecorder 进程内的计数器, 由 sk-app 和 sk-core 在请求处理过程中调用
+var DefaultRecorder = NewRecorder()
+
+// Recorder 先在内存中累加计数, 再定时批量写入 Redis, 避免在秒杀请求的处理路径上访问 Redis
+type Recorder struct {
+	lock     sync.Mutex
+	counters map[int]map[string]int64
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{
+		counters: make(map[int]map[string]int64),
+	}
+}
+
+// Incr 活动的某项计数加一, activityId 无效时忽略
+func (r *Recorder) Incr(activityId int, field string) {
+	if activityId <= 0 {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	fields, ok := r.counters[activityId]
+	if !ok {
+		fields = make(map[string]int64)
+		r.counters[activityId] = fields
+	}
+	fields[field]++
+}
+
+// Reject 记录一次被拒绝的请求
+func (r *Recorder) Reject(activityId int, code int) {
+	r.Incr(activityId, RejectField(code))
+}
+
+// Run 定时将内存中的计数写入 Redis
+func (r *Recorder) Run() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		r.Flush()
+	}
+}
+
+// Flush 将内存中的计数写入 Redis, 写入失败的计数会在下次写入时合并
+func (r *Recorder) Flush() {
+	conn := conf.Redis.RedisConn
+	if conn == nil {
+		return
+	}
+
+	r.lock.Lock()
+	counters := r.counters
+	r.counters = make(map[int]map[string]int64)
+	r.lock.Unlock()
+	if len(counters) == 0 {
+		return
+	}
+
+	pipe := conn.Pipeline()
+	for activityId, fields := range counters {
+		key := Key(activityId)
+		for field, count := range fields {
+			pipe.HIncrBy(key, field, count)
+		}
+		pipe.Expire(key, keyExpiration)
+	}
+	if _, err := pipe.Exec(); err != nil {
+		log.Printf("flush seckill stats failed, err : %v", err)
+		r.merge(counters)
+	}
+}
+
+func (r *Recorder) merge(counters map[int]map[string]int64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for activityId, fields := range counters {
+		current, ok := r.counters[activityId]
+		if !ok {
+			r.counters[activityId] = fields
+			continue
+		}
+		for field, count := range fields {
+			current[field] += count
+		}
+	}
+}
@@ -41,6 +41,24 @@ INSERT INTO `activity` VALUES ('2', '苹果大甩卖', '2', '530871061', '530872
 INSERT INTO `activity` VALUES ('3', '桃子大甩卖', '3', '1530928052', '1530989052', '20', '0', '1', '1', '0.20');
 INSERT INTO `activity` VALUES ('4', '梨子大甩卖', '4', '1530928052', '1530989052', '20', '0', '1', '1', '0.20');
 
+-- ----------------------------
+-- Table structure for activity_outbox
+-- ----------------------------
+DROP TABLE IF EXISTS `activity_outbox`;
+CREATE TABLE `activity_outbox` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT '事件Id',
+  `activity_id` int(11) unsigned NOT NULL COMMENT '活动Id',
+  `action` varchar(16) NOT NULL DEFAULT '' COMMENT '事件类型 publish/remove',
+  `payload` text NOT NULL COMMENT '商品配置JSON',
+  `status` tinyint(1) unsigned NOT NULL DEFAULT '0' COMMENT '0待发布 1已发布 2失败',
+  `retries` int(11) unsigned NOT NULL DEFAULT '0' COMMENT '重试次数',
+  `last_error` varchar(255) NOT NULL DEFAULT '' COMMENT '最近一次失败原因',
+  `create_time` int(11) unsigned NOT NULL DEFAULT '0' COMMENT '创建时间',
+  `update_time` int(11) unsigned NOT NULL DEFAULT '0' COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_status` (`status`,`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='@活动发布事件表';
+
 -- ----------------------------
 -- Table structure for product
 -- ----------------------------
@@ -60,3 +78,57 @@ INSERT INTO `product` VALUES ('1', '香蕉', '100', '1');
 INSERT INTO `product` VALUES ('2', '苹果', '100', '1');
 INSERT INTO `product` VALUES ('3', '桃子', '100', '1');
 INSERT INTO `product` VALUES ('4', '梨子', '100', '1');
+
+-- ----------------------------
+-- Table structure for product_stock_log
+-- ----------------------------
+DROP TABLE IF EXISTS `product_stock_log`;
+CREATE TABLE `product_stock_log` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT '记录Id',
+  `product_id` int(11) unsigned NOT NULL COMMENT '商品Id',
+  `delta` int(11) NOT NULL DEFAULT '0' COMMENT '调整数量',
+  `before_total` int(11) unsigned NOT NULL DEFAULT '0' COMMENT '调整前库存',
+  `after_total` int(11) unsigned NOT NULL DEFAULT '0' COMMENT '调整后库存',
+  `reason` varchar(255) NOT NULL DEFAULT '' COMMENT '调整原因',
+  `operator` varchar(50) NOT NULL DEFAULT '' COMMENT '操作人',
+  `create_time` int(11) unsigned NOT NULL DEFAULT '0' COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_product_id` (`product_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='@商品库存调整记录表';
+
+-- ----------------------------
+-- Table structure for sec_result
+-- ----------------------------
+DROP TABLE IF EXISTS `sec_result`;
+CREATE TABLE `sec_result` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT '记录Id',
+  `activity_id` int(11) unsigned NOT NULL DEFAULT '0' COMMENT '活动Id',
+  `product_id` int(11) unsigned NOT NULL DEFAULT '0' COMMENT '商品Id',
+  `user_id` int(11) unsigned NOT NULL DEFAULT '0' COMMENT '用户Id',
+  `code` int(11) unsigned NOT NULL DEFAULT '0' COMMENT '处理结果状态码',
+  `token` varchar(64) NOT NULL DEFAULT '' COMMENT '抢购资格Token',
+  `token_time` int(11) unsigned NOT NULL DEFAULT '0' COMMENT 'Token生成时间',
+  `create_time` int(11) unsigned NOT NULL DEFAULT '0' COMMENT '处理时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_activity_code` (`activity_id`,`code`),
+  KEY `idx_activity_time` (`activity_id`,`create_time`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='@秒杀结果表';
+
+-- ----------------------------
+-- Table structure for admin_audit_log
+-- ----------------------------
+DROP TABLE IF EXISTS `admin_audit_log`;
+CREATE TABLE `admin_audit_log` (
+  `id` bigint(20) unsigned NOT NULL AUTO_INCREMENT COMMENT '记录Id',
+  `operator_id` bigint(20) NOT NULL DEFAULT '0' COMMENT '操作人用户Id',
+  `operator` varchar(50) NOT NULL DEFAULT '' COMMENT '操作人用户名',
+  `resource` varchar(32) NOT NULL DEFAULT '' COMMENT '对象类型',
+  `action` varchar(32) NOT NULL DEFAULT '' COMMENT '操作类型',
+  `target_id` varchar(64) NOT NULL DEFAULT '' COMMENT '对象Id',
+  `before_data` text NOT NULL COMMENT '修改前JSON',
+  `after_data` text NOT NULL COMMENT '修改后JSON',
+  `create_time` int(11) unsigned NOT NULL DEFAULT '0' COMMENT '操作时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_resource_target` (`resource`,`target_id`),
+  KEY `idx_operator` (`operator`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COMMENT='@管理端审计日志表';
@@ -5,7 +5,6 @@ import (
 
 	"github.com/go-kit/kit/log"
 	"github.com/lixichongAAA/seckill/pkg/bootstrap"
-	_ "github.com/lixichongAAA/seckill/pkg/bootstrap"
 	conf "github.com/lixichongAAA/seckill/pkg/config"
 	"github.com/openzipkin/zipkin-go"
 	zipkinhttp "github.com/openzipkin/zipkin-go/reporter/http"
@@ -21,6 +20,7 @@ var ZipkinTracer *zipkin.Tracer
 var Logger log.Logger
 
 func init() {
+	bootstrap.Load()
 	Logger = log.NewLogfmtLogger(os.Stderr)
 	Logger = log.With(Logger, "ts", log.DefaultTimestampUTC)
 	Logger = log.With(Logger, "caller", log.DefaultCaller)
@@ -37,6 +37,9 @@ func init() {
 	if err := conf.Sub("trace", &conf.TraceConfig); err != nil {
 		Logger.Log("Fail to parse trace", err)
 	}
+	if err := conf.Sub("redis", &conf.Redis); err != nil {
+		Logger.Log("Fail to parse redis", err)
+	}
 	zipkinUrl := "http://" + conf.TraceConfig.Host + ":" + conf.TraceConfig.Port + conf.TraceConfig.Url
 	Logger.Log("zipkin url", zipkinUrl)
 	initTracer(zipkinUrl)
@@ -0,0 +1,142 @@
+package endpoint
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/lixichongAAA/seckill/pb"
+	"github.com/lixichongAAA/seckill/sk-admin/model"
+	"github.com/lixichongAAA/seckill/sk-admin/service"
+)
+
+const anonymousOperator = "anonymous"
+
+// OperatorFromContext 返回请求上下文中的操作人, 未携带有效令牌时为 anonymous
+func OperatorFromContext(ctx context.Context) (int64, string) {
+	if details, ok := ctx.Value(OAuth2DetailsKey).(*pb.UserDetails); ok && details != nil {
+		return details.UserId, details.Username
+	}
+	return 0, anonymousOperator
+}
+
+// failer 由携带业务错误的响应实现, 审计中间件据此判断修改是否成功
+type failer interface {
+	Failed() error
+}
+
+func (r CreateResponse) Failed() error   { return r.Error }
+func (r ActivityResponse) Failed() error { return r.Error }
+func (r ProductResponse) Failed() error  { return r.Error }
+func (r StockLogResponse) Failed() error { return r.Error }
+
+// MakeAuditMiddleware 在修改类 Endpoint 前后记录对象的状态, 修改成功后写入审计日志
+// 新建的对象没有修改前状态, 修改后状态取自请求; 返回活动或商品的操作(新建、修改、克隆)取自响应
+func MakeAuditMiddleware(svc service.AuditService, resource string, action string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			targetId := auditTarget(request)
+			var before string
+			if targetId != "" {
+				if before, err = svc.Snapshot(resource, targetId); err != nil {
+					log.Printf("audit snapshot of %s [%s] failed, err : %v", resource, targetId, err)
+				}
+			}
+
+			response, err = next(ctx, request)
+			if err != nil {
+				return response, err
+			}
+			if f, ok := response.(failer); ok && f.Failed() != nil {
+				return response, err
+			}
+
+			var after string
+			if r, ok := response.(ActivityResponse); ok && r.Result != nil {
+				after = toJson(r.Result)
+				if targetId == "" {
+					targetId = strconv.Itoa(r.Result.ActivityId)
+				}
+			} else if r, ok := response.(ProductResponse); ok && r.Result != nil {
+				after = toJson(r.Result)
+				if targetId == "" {
+					targetId = strconv.Itoa(r.Result.ProductId)
+				}
+			} else if targetId != "" {
+				if after, err = svc.Snapshot(resource, targetId); err != nil {
+					log.Printf("audit snapshot of %s [%s] failed, err : %v", resource, targetId, err)
+				}
+			} else {
+				after = toJson(request)
+			}
+
+			operatorId, operator := OperatorFromContext(ctx)
+			if recordErr := svc.Record(&model.AuditLog{
+				OperatorId: operatorId,
+				Operator:   operator,
+				Resource:   resource,
+				Action:     action,
+				TargetId:   targetId,
+				Before:     before,
+				After:      after,
+			}); recordErr != nil {
+				log.Printf("record audit log failed, err : %v", recordErr)
+			}
+			return response, nil
+		}
+	}
+}
+
+// auditTarget 从请求中取出被修改对象的Id, 新建对象时为空
+func auditTarget(request interface{}) string {
+	var id int
+	switch req := request.(type) {
+	case model.Activity:
+		id = req.ActivityId
+	case ActivityIdRequest:
+		id = req.ActivityId
+	case model.Product:
+		id = req.ProductId
+	case ProductIdRequest:
+		id = req.ProductId
+	case model.StockLog:
+		id = req.ProductId
+	case BlacklistRequest:
+		return req.Type + ":" + req.Value
+	}
+	if id <= 0 {
+		return ""
+	}
+	return strconv.Itoa(id)
+}
+
+func toJson(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// AuditListResponse 审计日志分页列表
+type AuditListResponse struct {
+	Result   []*model.AuditLog `json:"result"`
+	Total    int64             `json:"total"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"page_size"`
+	Error    error             `json:"error"`
+}
+
+func MakeGetAuditLogEndpoint(svc service.AuditService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(model.AuditQuery)
+
+		auditLogList, total, calError := svc.GetAuditLogList(&req)
+		if calError != nil {
+			return AuditListResponse{Result: nil, Error: calError}, nil
+		}
+		return AuditListResponse{Result: auditLogList, Total: total, Page: req.Page, PageSize: req.PageSize, Error: calError}, nil
+	}
+}
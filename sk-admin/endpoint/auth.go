@@ -0,0 +1,78 @@
+package endpoint
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	"github.com/lixichongAAA/seckill/pb"
+)
+
+// transport 层校验请求携带的 OAuth 令牌后, 将令牌绑定的用户信息(*pb.UserDetails)和客户端信息(*pb.ClientDetails)
+// 放入请求上下文, 校验失败时放入错误信息
+const (
+	OAuth2DetailsKey       = "OAuth2Details"
+	OAuth2ClientDetailsKey = "OAuth2ClientDetails"
+	OAuth2ErrorKey         = "OAuth2Error"
+)
+
+// 管理端角色, 对应令牌中用户的 Authorities
+// ADMIN 拥有 OPERATOR 的全部权限
+const (
+	RoleAdmin    = "ADMIN"
+	RoleOperator = "OPERATOR"
+)
+
+var (
+	ErrInvalidUserRequest = errors.New("invalid user message")
+	ErrNotPermit          = errors.New("not permit")
+)
+
+// 鉴权, 用户需具备 authority 角色或 ADMIN 角色
+func MakeAuthorityAuthorizationdMiddleware(authority string, logger log.Logger) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+
+			if err, ok := ctx.Value(OAuth2ErrorKey).(error); ok {
+				return nil, err
+			}
+			// 获取 Context 中的用户信息
+			details, ok := ctx.Value(OAuth2DetailsKey).(*pb.UserDetails)
+			if !ok || details == nil {
+				return nil, ErrInvalidUserRequest
+			}
+			// 权限检查
+			for _, value := range details.Authorities {
+				if value == authority || value == RoleAdmin {
+					return next(ctx, request)
+				}
+			}
+			_ = logger.Log("user", details.Username, "authority", authority, "err", ErrNotPermit)
+			return nil, ErrNotPermit
+		}
+	}
+}
+
+// MakeServiceAuthorizationMiddleware 只读接口的鉴权, 令牌签发给 trustedClients 中的客户端(其他服务)时直接放行,
+// 否则与 MakeAuthorityAuthorizationdMiddleware 相同, 用户需具备 authority 角色或 ADMIN 角色
+func MakeServiceAuthorizationMiddleware(authority string, trustedClients []string, logger log.Logger) endpoint.Middleware {
+	trusted := make(map[string]bool, len(trustedClients))
+	for _, clientId := range trustedClients {
+		trusted[clientId] = true
+	}
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		byAuthority := MakeAuthorityAuthorizationdMiddleware(authority, logger)(next)
+
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			if err, ok := ctx.Value(OAuth2ErrorKey).(error); ok {
+				return nil, err
+			}
+			if client, ok := ctx.Value(OAuth2ClientDetailsKey).(*pb.ClientDetails); ok && client != nil && trusted[client.ClientId] {
+				return next(ctx, request)
+			}
+			return byAuthority(ctx, request)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package endpoint
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/lixichongAAA/seckill/sk-admin/service"
+)
+
+// BlacklistRequest 修改黑名单的请求结构, Type 为 id 或 ip
+type BlacklistRequest struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type BlacklistResponse struct {
+	Result *service.Blacklist `json:"result"`
+	Error  error              `json:"error"`
+}
+
+func MakeAddBlacklistEndpoint(svc service.BlacklistService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(BlacklistRequest)
+
+		calError := svc.AddBlacklist(req.Type, req.Value)
+		if calError == service.ErrInvalidBlacklist {
+			return nil, calError
+		}
+		return CreateResponse{Error: calError}, nil
+	}
+}
+
+func MakeRemoveBlacklistEndpoint(svc service.BlacklistService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(BlacklistRequest)
+
+		calError := svc.RemoveBlacklist(req.Type, req.Value)
+		if calError == service.ErrInvalidBlacklist {
+			return nil, calError
+		}
+		return CreateResponse{Error: calError}, nil
+	}
+}
+
+func MakeGetBlacklistEndpoint(svc service.BlacklistService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		blacklist, calError := svc.GetBlacklist()
+		return BlacklistResponse{Result: blacklist, Error: calError}, nil
+	}
+}
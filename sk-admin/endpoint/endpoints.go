@@ -13,11 +13,33 @@ import (
 
 // CalculateEndpoint define endpoint
 type SkAdminEndpoints struct {
-	GetActivityEndpoint    endpoint.Endpoint
-	CreateActivityEndpoint endpoint.Endpoint
-	CreateProductEndpoint  endpoint.Endpoint
-	GetProductEndpoint     endpoint.Endpoint
-	HealthCheckEndpoint    endpoint.Endpoint
+	GetActivityEndpoint     endpoint.Endpoint
+	GetActivityByIdEndpoint endpoint.Endpoint
+	ListActivityEndpoint    endpoint.Endpoint
+	GetProductConfEndpoint  endpoint.Endpoint
+	CreateActivityEndpoint  endpoint.Endpoint
+	UpdateActivityEndpoint  endpoint.Endpoint
+	DisableActivityEndpoint endpoint.Endpoint
+	EnableActivityEndpoint  endpoint.Endpoint
+	DeleteActivityEndpoint  endpoint.Endpoint
+	CloneActivityEndpoint   endpoint.Endpoint
+	CreateProductEndpoint   endpoint.Endpoint
+	GetProductEndpoint      endpoint.Endpoint
+	GetProductByIdEndpoint  endpoint.Endpoint
+	UpdateProductEndpoint   endpoint.Endpoint
+	DeleteProductEndpoint   endpoint.Endpoint
+	AdjustStockEndpoint     endpoint.Endpoint
+	GetStockLogEndpoint     endpoint.Endpoint
+	GetDashboardEndpoint    endpoint.Endpoint
+	DashboardTickEndpoint   endpoint.Endpoint //实时图表的定时推送, 不经过限流
+	WinnerReportEndpoint    endpoint.Endpoint
+	ThroughputEndpoint      endpoint.Endpoint
+	RejectionReportEndpoint endpoint.Endpoint
+	AddBlacklistEndpoint    endpoint.Endpoint
+	RemoveBlacklistEndpoint endpoint.Endpoint
+	GetBlacklistEndpoint    endpoint.Endpoint
+	GetAuditLogEndpoint     endpoint.Endpoint
+	HealthCheckEndpoint     endpoint.Endpoint
 }
 
 func (ue SkAdminEndpoints) HealthCheck() bool {
@@ -47,6 +69,31 @@ type CreateResponse struct {
 	Error error `json:"error"`
 }
 
+// ActivityIdRequest 按活动Id操作活动的请求结构
+type ActivityIdRequest struct {
+	ActivityId int `json:"activity_id"`
+}
+
+type ActivityResponse struct {
+	Result *model.Activity `json:"result"`
+	Error  error           `json:"error"`
+}
+
+// ActivityListRequest 按状态查询活动, Status 为空时查询全部活动
+type ActivityListRequest struct {
+	Status []int `json:"status"`
+}
+
+type ActivityListResponse struct {
+	Result []*model.Activity `json:"result"`
+	Error  error             `json:"error"`
+}
+
+type ProductConfListResponse struct {
+	Result []*model.SecProductInfoConf `json:"result"`
+	Error  error                       `json:"error"`
+}
+
 // make endpoint
 func MakeGetActivityEndpoint(svc service.ActivityService) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
@@ -64,18 +111,205 @@ func MakeCreateActivityEndpoint(svc service.ActivityService) endpoint.Endpoint {
 		req := request.(model.Activity)
 
 		calError := svc.CreateActivity(&req)
+		if verr, ok := calError.(*service.ValidationError); ok {
+			return nil, verr
+		}
+		if calError != nil {
+			return ActivityResponse{Result: nil, Error: calError}, nil
+		}
+		return ActivityResponse{Result: &req, Error: calError}, nil
+	}
+}
+
+func MakeUpdateActivityEndpoint(svc service.ActivityService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(model.Activity)
+
+		calError := svc.UpdateActivity(&req)
+		if verr, ok := calError.(*service.ValidationError); ok {
+			return nil, verr
+		}
+		if calError != nil {
+			return ActivityResponse{Result: nil, Error: calError}, nil
+		}
+		return ActivityResponse{Result: &req, Error: calError}, nil
+	}
+}
+
+func MakeGetActivityByIdEndpoint(svc service.ActivityService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(ActivityIdRequest)
+
+		activity, calError := svc.GetActivity(req.ActivityId)
+		if calError == model.ErrActivityNotFound {
+			return nil, calError
+		}
+		return ActivityResponse{Result: activity, Error: calError}, nil
+	}
+}
+
+func MakeListActivityEndpoint(svc service.ActivityService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(ActivityListRequest)
+
+		activityList, calError := svc.GetActivityListByStatus(req.Status...)
+		return ActivityListResponse{Result: activityList, Error: calError}, nil
+	}
+}
+
+func MakeGetProductConfEndpoint(svc service.ActivityService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		productConfList, calError := svc.GetProductConfList()
+		return ProductConfListResponse{Result: productConfList, Error: calError}, nil
+	}
+}
+
+func MakeDisableActivityEndpoint(svc service.ActivityService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(ActivityIdRequest)
+
+		calError := svc.DisableActivity(req.ActivityId)
 		return CreateResponse{Error: calError}, nil
 	}
 }
 
+func MakeEnableActivityEndpoint(svc service.ActivityService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(ActivityIdRequest)
+
+		calError := svc.EnableActivity(req.ActivityId)
+		if verr, ok := calError.(*service.ValidationError); ok {
+			return nil, verr
+		}
+		return CreateResponse{Error: calError}, nil
+	}
+}
+
+func MakeDeleteActivityEndpoint(svc service.ActivityService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(ActivityIdRequest)
+
+		calError := svc.DeleteActivity(req.ActivityId)
+		return CreateResponse{Error: calError}, nil
+	}
+}
+
+func MakeCloneActivityEndpoint(svc service.ActivityService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(ActivityIdRequest)
+
+		activity, calError := svc.CloneActivity(req.ActivityId)
+		return ActivityResponse{Result: activity, Error: calError}, nil
+	}
+}
+
+// ProductIdRequest 按商品Id操作商品的请求结构
+type ProductIdRequest struct {
+	ProductId int `json:"product_id"`
+}
+
+// ProductListResponse 商品分页列表
+type ProductListResponse struct {
+	Result   []gorose.Data `json:"result"`
+	Total    int64         `json:"total"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"page_size"`
+	Error    error         `json:"error"`
+}
+
+type ProductResponse struct {
+	Result *model.Product `json:"result"`
+	Error  error          `json:"error"`
+}
+
+type StockLogResponse struct {
+	Result *model.StockLog `json:"result"`
+	Error  error           `json:"error"`
+}
+
+type StockLogListResponse struct {
+	Result []*model.StockLog `json:"result"`
+	Error  error             `json:"error"`
+}
+
+// isProductRequestError 判断是否为调用方请求导致的错误，交由 transport 层返回对应的状态码
+func isProductRequestError(err error) bool {
+	return err == model.ErrProductNotFound || err == model.ErrInsufficientStock ||
+		err == model.ErrStockBelowActivity || err == service.ErrProductInUse
+}
+
 // make endpoint
 func MakeGetProductEndpoint(svc service.ProductService) endpoint.Endpoint {
 	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
-		getProductList, calError := svc.GetProductList()
+		req := request.(model.ProductQuery)
+
+		getProductList, total, calError := svc.GetProductList(&req)
 		if calError != nil {
-			return GetResponse{Result: nil, Error: calError}, nil
+			return ProductListResponse{Result: nil, Error: calError}, nil
 		}
-		return GetResponse{Result: getProductList, Error: calError}, nil
+		return ProductListResponse{Result: getProductList, Total: total, Page: req.Page, PageSize: req.PageSize, Error: calError}, nil
+	}
+}
+
+func MakeGetProductByIdEndpoint(svc service.ProductService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(ProductIdRequest)
+
+		product, calError := svc.GetProduct(req.ProductId)
+		if isProductRequestError(calError) {
+			return nil, calError
+		}
+		return ProductResponse{Result: product, Error: calError}, nil
+	}
+}
+
+func MakeUpdateProductEndpoint(svc service.ProductService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(model.Product)
+
+		calError := svc.UpdateProduct(&req)
+		if isProductRequestError(calError) {
+			return nil, calError
+		}
+		return CreateResponse{Error: calError}, nil
+	}
+}
+
+func MakeDeleteProductEndpoint(svc service.ProductService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(ProductIdRequest)
+
+		calError := svc.DeleteProduct(req.ProductId)
+		if isProductRequestError(calError) {
+			return nil, calError
+		}
+		return CreateResponse{Error: calError}, nil
+	}
+}
+
+func MakeAdjustStockEndpoint(svc service.ProductService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(model.StockLog)
+		// 操作人取自令牌, 忽略请求中的操作人
+		_, req.Operator = OperatorFromContext(ctx)
+
+		calError := svc.AdjustStock(&req)
+		if isProductRequestError(calError) {
+			return nil, calError
+		}
+		if calError != nil {
+			return StockLogResponse{Result: nil, Error: calError}, nil
+		}
+		return StockLogResponse{Result: &req, Error: calError}, nil
+	}
+}
+
+func MakeGetStockLogEndpoint(svc service.ProductService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(ProductIdRequest)
+
+		stockLogList, calError := svc.GetStockLogList(req.ProductId)
+		return StockLogListResponse{Result: stockLogList, Error: calError}, nil
 	}
 }
 
@@ -84,7 +318,96 @@ func MakeCreateProductEndpoint(svc service.ProductService) endpoint.Endpoint {
 		req := request.(model.Product)
 
 		calError := svc.CreateProduct(&req)
-		return CreateResponse{Error: calError}, nil
+		if calError != nil {
+			return ProductResponse{Result: nil, Error: calError}, nil
+		}
+		return ProductResponse{Result: &req, Error: calError}, nil
+	}
+}
+
+// DashboardRequest 查询活动实时数据的请求结构, ActivityId 为 0 时返回全部活动
+type DashboardRequest struct {
+	ActivityId int `json:"activity_id"`
+}
+
+type DashboardResponse struct {
+	Result []*service.ActivityDashboard `json:"result"`
+	Error  error                        `json:"error"`
+}
+
+func MakeGetDashboardEndpoint(svc service.DashboardService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(DashboardRequest)
+
+		if req.ActivityId > 0 {
+			dashboard, calError := svc.GetActivityDashboard(req.ActivityId)
+			if calError == model.ErrActivityNotFound {
+				return nil, calError
+			}
+			if calError != nil {
+				return DashboardResponse{Result: nil, Error: calError}, nil
+			}
+			return DashboardResponse{Result: []*service.ActivityDashboard{dashboard}, Error: calError}, nil
+		}
+
+		dashboardList, calError := svc.GetDashboard()
+		return DashboardResponse{Result: dashboardList, Error: calError}, nil
+	}
+}
+
+// ReportRequest 查询活动报表的请求结构
+type ReportRequest struct {
+	ActivityId int `json:"activity_id"`
+}
+
+type WinnerReportResponse struct {
+	Result []*model.SecResult `json:"result"`
+	Error  error              `json:"error"`
+}
+
+type ThroughputReportResponse struct {
+	Result []*model.ThroughputPoint `json:"result"`
+	Error  error                    `json:"error"`
+}
+
+type RejectionReportResponse struct {
+	Result []*model.RejectionCount `json:"result"`
+	Error  error                   `json:"error"`
+}
+
+func MakeWinnerReportEndpoint(svc service.ReportService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(ReportRequest)
+
+		winnerList, calError := svc.GetWinnerReport(req.ActivityId)
+		if calError != nil {
+			return nil, calError
+		}
+		return WinnerReportResponse{Result: winnerList, Error: calError}, nil
+	}
+}
+
+func MakeThroughputReportEndpoint(svc service.ReportService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(ReportRequest)
+
+		points, calError := svc.GetThroughputReport(req.ActivityId)
+		if calError != nil {
+			return nil, calError
+		}
+		return ThroughputReportResponse{Result: points, Error: calError}, nil
+	}
+}
+
+func MakeRejectionReportEndpoint(svc service.ReportService) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+		req := request.(ReportRequest)
+
+		rejections, calError := svc.GetRejectionReport(req.ActivityId)
+		if calError != nil {
+			return nil, calError
+		}
+		return RejectionReportResponse{Result: rejections, Error: calError}, nil
 	}
 }
 
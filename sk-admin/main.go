@@ -1,18 +1,38 @@
 package main
 
 import (
+	"flag"
+	"log"
+	"os"
+
 	"github.com/lixichongAAA/seckill/pkg/bootstrap"
 	conf "github.com/lixichongAAA/seckill/pkg/config"
 	"github.com/lixichongAAA/seckill/pkg/mysql"
+	"github.com/lixichongAAA/seckill/sk-admin/service"
 	"github.com/lixichongAAA/seckill/sk-admin/setup"
 )
 
+var reconcile = flag.Bool("reconcile", false, "rebuild product config in zookeeper from mysql and exit")
+
 // 秒杀管理系统和秒杀业务系统层次类似，都是通过Go-kit的 transport 层来提供HTTP服务接口
 // 并通过 endpoint 层将HTTP请求转发给 service 层对应的方法
 func main() {
+	flag.Parse()
 	mysql.InitMysql(conf.MysqlConfig.Host, conf.MysqlConfig.Port, conf.MysqlConfig.User, conf.MysqlConfig.Pwd, conf.MysqlConfig.Db) // conf.MysqlConfig.Db
 	//setup.InitEtcd()
 	setup.InitZk()
+	setup.InitRedis()
+	if *reconcile {
+		if err := service.ReconcileProductConf(); err != nil {
+			log.Printf("reconcile product config failed, err : %v", err)
+			os.Exit(1)
+		}
+		log.Printf("reconcile product config success")
+		return
+	}
+	setup.InitPublisher()
+	setup.InitProductConfHub()
+	setup.InitScheduler()
 	setup.InitServer(bootstrap.HttpConfig.Host, bootstrap.HttpConfig.Port)
 
 }
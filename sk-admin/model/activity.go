@@ -1,16 +1,21 @@
 package model
 
 import (
+	"errors"
+	"fmt"
 	"log"
 
 	"github.com/gohouse/gorose/v2"
 	"github.com/lixichongAAA/seckill/pkg/mysql"
+	"github.com/unknwon/com"
 )
 
 const (
 	ActivityStatusNormal  = 0
 	ActivityStatusDisable = 1
 	ActivityStatusExpire  = 2
+	ActivityStatusRunning = 3 //已开始
+	ActivityStatusSoldOut = 4 //已售罄
 )
 
 type Activity struct {
@@ -30,7 +35,10 @@ type Activity struct {
 	BuyRate      float64 `json:"buy_rate"`
 }
 
+var ErrActivityNotFound = errors.New("activity not found")
+
 type SecProductInfoConf struct {
+	ActivityId        int     `json:"activity_id"`          //活动Id
 	ProductId         int     `json:"product_id"`           //商品Id
 	StartTime         int64   `json:"start_time"`           //开始时间
 	EndTime           int64   `json:"end_time"`             //结束时间
@@ -43,18 +51,31 @@ type SecProductInfoConf struct {
 }
 
 type ActivityModel struct {
+	conn gorose.IOrm
 }
 
 func NewActivityModel() *ActivityModel {
 	return &ActivityModel{}
 }
 
+// NewActivityModelWithTx 返回使用指定连接的 ActivityModel, 用于在事务中读写活动数据
+func NewActivityModelWithTx(db gorose.IOrm) *ActivityModel {
+	return &ActivityModel{conn: db}
+}
+
+func (p *ActivityModel) db() gorose.IOrm {
+	if p.conn != nil {
+		return p.conn
+	}
+	return mysql.DB()
+}
+
 func (p *ActivityModel) getTableName() string {
 	return "activity"
 }
 
 func (p *ActivityModel) GetActivityList() ([]gorose.Data, error) {
-	conn := mysql.DB()
+	conn := p.db()
 	list, err := conn.Table(p.getTableName()).Order("activity_id desc").Get()
 	if err != nil {
 		log.Printf("Error : %v", err)
@@ -63,8 +84,93 @@ func (p *ActivityModel) GetActivityList() ([]gorose.Data, error) {
 	return list, nil
 }
 
+// GetActivityById 根据活动Id查询活动，活动不存在时返回 ErrActivityNotFound
+func (p *ActivityModel) GetActivityById(activityId int) (*Activity, error) {
+	conn := p.db()
+	data, err := conn.Table(p.getTableName()).Where("activity_id", activityId).First()
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, ErrActivityNotFound
+	}
+	return ToActivity(data), nil
+}
+
+// GetActivityByIdForUpdate 在事务中查询并锁定活动行, 直到事务结束
+func (p *ActivityModel) GetActivityByIdForUpdate(activityId int) (*Activity, error) {
+	conn := p.db()
+	data, err := conn.Table(p.getTableName()).Where("activity_id", activityId).LockForUpdate().First()
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, ErrActivityNotFound
+	}
+	return ToActivity(data), nil
+}
+
+// GetActivityListByStatus 查询处于给定状态之一的全部活动
+func (p *ActivityModel) GetActivityListByStatus(status ...int) ([]*Activity, error) {
+	conn := p.db()
+	statusList := make([]interface{}, 0, len(status))
+	for _, v := range status {
+		statusList = append(statusList, v)
+	}
+	list, err := conn.Table(p.getTableName()).WhereIn("status", statusList).Order("activity_id asc").Get()
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return nil, err
+	}
+	activityList := make([]*Activity, 0, len(list))
+	for _, v := range list {
+		activityList = append(activityList, ToActivity(v))
+	}
+	return activityList, nil
+}
+
+// GetActivityListByProductId 查询某个商品下的全部活动
+func (p *ActivityModel) GetActivityListByProductId(productId int) ([]*Activity, error) {
+	conn := p.db()
+	list, err := conn.Table(p.getTableName()).Where("product_id", productId).Get()
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return nil, err
+	}
+	activityList := make([]*Activity, 0, len(list))
+	for _, v := range list {
+		activityList = append(activityList, ToActivity(v))
+	}
+	return activityList, nil
+}
+
 func (p *ActivityModel) CreateActivity(activity *Activity) error {
-	conn := mysql.DB()
+	conn := p.db()
+	id, err := conn.Table(p.getTableName()).Data(
+		map[string]interface{}{
+			"activity_name": activity.ActivityName,
+			"product_id":    activity.ProductId,
+			"start_time":    activity.StartTime,
+			"end_time":      activity.EndTime,
+			"total":         activity.Total,
+			"status":        activity.Status,
+			"sec_speed":     activity.Speed,
+			"buy_limit":     activity.BuyLimit,
+			"buy_rate":      activity.BuyRate,
+		},
+	).InsertGetId()
+	if err != nil {
+		return err
+	}
+	activity.ActivityId = int(id)
+	return nil
+}
+
+// UpdateActivity 按活动Id更新活动的全部可编辑字段, 不修改活动状态
+func (p *ActivityModel) UpdateActivity(activity *Activity) error {
+	conn := p.db()
 	_, err := conn.Table(p.getTableName()).Data(
 		map[string]interface{}{
 			"activity_name": activity.ActivityName,
@@ -76,9 +182,67 @@ func (p *ActivityModel) CreateActivity(activity *Activity) error {
 			"buy_limit":     activity.BuyLimit,
 			"buy_rate":      activity.BuyRate,
 		},
-	).Insert()
+	).Where("activity_id", activity.ActivityId).Update()
 	if err != nil {
+		log.Printf("Error : %v", err)
 		return err
 	}
 	return nil
 }
+
+// UpdateActivityStatus 只修改活动状态
+func (p *ActivityModel) UpdateActivityStatus(activityId int, status int) error {
+	conn := p.db()
+	_, err := conn.Table(p.getTableName()).Data(
+		map[string]interface{}{
+			"status": status,
+		},
+	).Where("activity_id", activityId).Update()
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return err
+	}
+	return nil
+}
+
+// TransitActivityStatus 仅当活动仍处于 from 状态时将其修改为 to 状态
+// 返回 false 表示活动状态已被其他操作修改，本次未做变更
+func (p *ActivityModel) TransitActivityStatus(activityId int, from int, to int) (bool, error) {
+	conn := p.db()
+	affected, err := conn.Table(p.getTableName()).Data(
+		map[string]interface{}{
+			"status": to,
+		},
+	).Where("activity_id", activityId).Where("status", from).Update()
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (p *ActivityModel) DeleteActivity(activityId int) error {
+	conn := p.db()
+	_, err := conn.Table(p.getTableName()).Where("activity_id", activityId).Delete()
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return err
+	}
+	return nil
+}
+
+// ToActivity 将数据库中查询到的一行活动数据转换为 Activity
+func ToActivity(data gorose.Data) *Activity {
+	activity := &Activity{}
+	activity.ActivityId, _ = com.StrTo(fmt.Sprint(data["activity_id"])).Int()
+	activity.ActivityName = fmt.Sprint(data["activity_name"])
+	activity.ProductId, _ = com.StrTo(fmt.Sprint(data["product_id"])).Int()
+	activity.StartTime, _ = com.StrTo(fmt.Sprint(data["start_time"])).Int64()
+	activity.EndTime, _ = com.StrTo(fmt.Sprint(data["end_time"])).Int64()
+	activity.Total, _ = com.StrTo(fmt.Sprint(data["total"])).Int()
+	activity.Status, _ = com.StrTo(fmt.Sprint(data["status"])).Int()
+	activity.Speed, _ = com.StrTo(fmt.Sprint(data["sec_speed"])).Int()
+	activity.BuyLimit, _ = com.StrTo(fmt.Sprint(data["buy_limit"])).Int()
+	activity.BuyRate, _ = com.StrTo(fmt.Sprint(data["buy_rate"])).Float64()
+	return activity
+}
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gohouse/gorose/v2"
+	"github.com/lixichongAAA/seckill/pkg/mysql"
+	"github.com/unknwon/com"
+)
+
+// AuditLog 管理端的一次修改操作
+type AuditLog struct {
+	Id         int64  `json:"id"`
+	OperatorId int64  `json:"operator_id"` //操作人用户Id, 取自 OAuth 令牌
+	Operator   string `json:"operator"`    //操作人用户名
+	Resource   string `json:"resource"`    //被修改的对象类型, 如 activity、product、blacklist
+	Action     string `json:"action"`      //操作类型, 如 create、update、delete
+	TargetId   string `json:"target_id"`   //被修改对象的Id
+	Before     string `json:"before"`      //修改前的 JSON
+	After      string `json:"after"`       //修改后的 JSON
+	CreateTime int64  `json:"create_time"`
+}
+
+// AuditQuery 审计日志的分页及过滤条件, 字段为空表示不过滤
+type AuditQuery struct {
+	Page     int    `json:"page"`
+	PageSize int    `json:"page_size"`
+	Resource string `json:"resource"`
+	TargetId string `json:"target_id"`
+	Operator string `json:"operator"`
+}
+
+type AuditModel struct {
+}
+
+func NewAuditModel() *AuditModel {
+	return &AuditModel{}
+}
+
+func (p *AuditModel) getTableName() string {
+	return "admin_audit_log"
+}
+
+func (p *AuditModel) CreateAuditLog(auditLog *AuditLog) error {
+	conn := mysql.DB()
+	auditLog.CreateTime = time.Now().Unix()
+	id, err := conn.Table(p.getTableName()).Data(map[string]interface{}{
+		"operator_id": auditLog.OperatorId,
+		"operator":    auditLog.Operator,
+		"resource":    auditLog.Resource,
+		"action":      auditLog.Action,
+		"target_id":   auditLog.TargetId,
+		"before_data": auditLog.Before,
+		"after_data":  auditLog.After,
+		"create_time": auditLog.CreateTime,
+	}).InsertGetId()
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return err
+	}
+	auditLog.Id = id
+	return nil
+}
+
+// GetAuditLogPage 按条件分页查询审计日志，同时返回满足条件的总数
+func (p *AuditModel) GetAuditLogPage(query *AuditQuery) ([]*AuditLog, int64, error) {
+	total, err := p.filter(mysql.DB().Table(p.getTableName()), query).Count()
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return nil, 0, err
+	}
+
+	list, err := p.filter(mysql.DB().Table(p.getTableName()), query).
+		Order("id desc").Limit(query.PageSize).Page(query.Page).Get()
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return nil, 0, err
+	}
+	auditLogList := make([]*AuditLog, 0, len(list))
+	for _, v := range list {
+		auditLogList = append(auditLogList, toAuditLog(v))
+	}
+	return auditLogList, total, nil
+}
+
+func (p *AuditModel) filter(conn gorose.IOrm, query *AuditQuery) gorose.IOrm {
+	if query.Resource != "" {
+		conn = conn.Where("resource", query.Resource)
+	}
+	if query.TargetId != "" {
+		conn = conn.Where("target_id", query.TargetId)
+	}
+	if query.Operator != "" {
+		conn = conn.Where("operator", query.Operator)
+	}
+	return conn
+}
+
+func toAuditLog(data gorose.Data) *AuditLog {
+	auditLog := &AuditLog{}
+	auditLog.Id, _ = com.StrTo(fmt.Sprint(data["id"])).Int64()
+	auditLog.OperatorId, _ = com.StrTo(fmt.Sprint(data["operator_id"])).Int64()
+	auditLog.Operator = fmt.Sprint(data["operator"])
+	auditLog.Resource = fmt.Sprint(data["resource"])
+	auditLog.Action = fmt.Sprint(data["action"])
+	auditLog.TargetId = fmt.Sprint(data["target_id"])
+	auditLog.Before = fmt.Sprint(data["before_data"])
+	auditLog.After = fmt.Sprint(data["after_data"])
+	auditLog.CreateTime, _ = com.StrTo(fmt.Sprint(data["create_time"])).Int64()
+	return auditLog
+}
@@ -0,0 +1,147 @@
+package model
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gohouse/gorose/v2"
+	"github.com/lixichongAAA/seckill/pkg/mysql"
+	"github.com/unknwon/com"
+)
+
+const (
+	OutboxActionPublish = "publish" //发布(新增或替换)活动对应的商品配置
+	OutboxActionRemove  = "remove"  //移除活动对应的商品配置
+)
+
+const (
+	OutboxStatusPending = 0 //等待发布
+	OutboxStatusDone    = 1 //已同步到 Zookeeper
+	OutboxStatusFailed  = 2 //超过最大重试次数
+)
+
+// OutboxEvent 活动变更事件，与活动数据在同一个 Mysql 事务中写入
+// 再由发布器异步同步到 Zookeeper，保证两者最终一致
+type OutboxEvent struct {
+	Id         int64  `json:"id"`
+	ActivityId int    `json:"activity_id"`
+	Action     string `json:"action"`
+	Payload    string `json:"payload"` //SecProductInfoConf 的 JSON
+	Status     int    `json:"status"`
+	Retries    int    `json:"retries"`
+	LastError  string `json:"last_error"`
+	CreateTime int64  `json:"create_time"`
+	UpdateTime int64  `json:"update_time"`
+}
+
+type OutboxModel struct {
+	conn gorose.IOrm
+}
+
+func NewOutboxModel() *OutboxModel {
+	return &OutboxModel{}
+}
+
+// NewOutboxModelWithTx 返回使用指定连接的 OutboxModel, 用于与活动数据在同一事务中写入事件
+func NewOutboxModelWithTx(db gorose.IOrm) *OutboxModel {
+	return &OutboxModel{conn: db}
+}
+
+func (p *OutboxModel) db() gorose.IOrm {
+	if p.conn != nil {
+		return p.conn
+	}
+	return mysql.DB()
+}
+
+func (p *OutboxModel) getTableName() string {
+	return "activity_outbox"
+}
+
+func (p *OutboxModel) CreateEvent(event *OutboxEvent) error {
+	conn := p.db()
+	now := time.Now().Unix()
+	id, err := conn.Table(p.getTableName()).Data(map[string]interface{}{
+		"activity_id": event.ActivityId,
+		"action":      event.Action,
+		"payload":     event.Payload,
+		"status":      OutboxStatusPending,
+		"retries":     0,
+		"last_error":  "",
+		"create_time": now,
+		"update_time": now,
+	}).InsertGetId()
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return err
+	}
+	event.Id = id
+	event.CreateTime = now
+	event.UpdateTime = now
+	return nil
+}
+
+// GetPendingEvents 按写入顺序获取待发布的事件
+func (p *OutboxModel) GetPendingEvents(limit int) ([]*OutboxEvent, error) {
+	conn := p.db()
+	list, err := conn.Table(p.getTableName()).Where("status", OutboxStatusPending).
+		Order("id asc").Limit(limit).Get()
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return nil, err
+	}
+	events := make([]*OutboxEvent, 0, len(list))
+	for _, v := range list {
+		events = append(events, toOutboxEvent(v))
+	}
+	return events, nil
+}
+
+func (p *OutboxModel) MarkDone(id int64) error {
+	return p.updateStatus(id, map[string]interface{}{
+		"status":      OutboxStatusDone,
+		"update_time": time.Now().Unix(),
+	})
+}
+
+// MarkRetry 记录一次发布失败，failed 为 true 时事件不再重试
+func (p *OutboxModel) MarkRetry(id int64, retries int, lastError string, failed bool) error {
+	if len(lastError) > 255 {
+		lastError = lastError[:255]
+	}
+	status := OutboxStatusPending
+	if failed {
+		status = OutboxStatusFailed
+	}
+	return p.updateStatus(id, map[string]interface{}{
+		"status":      status,
+		"retries":     retries,
+		"last_error":  lastError,
+		"update_time": time.Now().Unix(),
+	})
+}
+
+func (p *OutboxModel) updateStatus(id int64, data map[string]interface{}) error {
+	conn := p.db()
+	_, err := conn.Table(p.getTableName()).Data(data).Where("id", id).Update()
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return err
+	}
+	return nil
+}
+
+func toOutboxEvent(data gorose.Data) *OutboxEvent {
+	event := &OutboxEvent{}
+	event.Id, _ = com.StrTo(fmt.Sprint(data["id"])).Int64()
+	event.ActivityId, _ = com.StrTo(fmt.Sprint(data["activity_id"])).Int()
+	event.Action = fmt.Sprint(data["action"])
+	event.Payload = fmt.Sprint(data["payload"])
+	event.Status, _ = com.StrTo(fmt.Sprint(data["status"])).Int()
+	event.Retries, _ = com.StrTo(fmt.Sprint(data["retries"])).Int()
+	event.LastError = fmt.Sprint(data["last_error"])
+	event.CreateTime, _ = com.StrTo(fmt.Sprint(data["create_time"])).Int64()
+	event.UpdateTime, _ = com.StrTo(fmt.Sprint(data["update_time"])).Int64()
+	return event
+}
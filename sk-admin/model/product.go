@@ -1,10 +1,19 @@
 package model
 
 import (
+	"errors"
+	"fmt"
 	"log"
 
 	"github.com/gohouse/gorose/v2"
 	"github.com/lixichongAAA/seckill/pkg/mysql"
+	"github.com/unknwon/com"
+)
+
+var (
+	ErrProductNotFound    = errors.New("product not found")
+	ErrInsufficientStock  = errors.New("product stock can not be negative")
+	ErrStockBelowActivity = errors.New("product stock can not be less than the total of its activities")
 )
 
 type Product struct {
@@ -14,19 +23,40 @@ type Product struct {
 	Status      int    `json:"status"`       //商品状态
 }
 
+// ProductQuery 商品列表的分页及过滤条件
+type ProductQuery struct {
+	Page        int    `json:"page"`         //页码, 从1开始
+	PageSize    int    `json:"page_size"`    //每页数量
+	ProductName string `json:"product_name"` //按名称模糊匹配
+	Status      int    `json:"status"`       //按状态过滤, 小于0表示不过滤
+}
+
 type ProductModel struct {
+	conn gorose.IOrm
 }
 
 func NewProductModel() *ProductModel {
 	return &ProductModel{}
 }
 
+// NewProductModelWithTx 返回使用指定连接的 ProductModel, 用于在事务中读写商品数据
+func NewProductModelWithTx(db gorose.IOrm) *ProductModel {
+	return &ProductModel{conn: db}
+}
+
+func (p *ProductModel) db() gorose.IOrm {
+	if p.conn != nil {
+		return p.conn
+	}
+	return mysql.DB()
+}
+
 func (p *ProductModel) getTableName() string {
 	return "product"
 }
 
 func (p *ProductModel) GetProductList() ([]gorose.Data, error) {
-	conn := mysql.DB()
+	conn := p.db()
 	list, err := conn.Table(p.getTableName()).Get()
 	if err != nil {
 		log.Printf("Error : %v", err)
@@ -35,16 +65,139 @@ func (p *ProductModel) GetProductList() ([]gorose.Data, error) {
 	return list, nil
 }
 
+// GetProductPage 按条件分页查询商品，同时返回满足条件的商品总数
+func (p *ProductModel) GetProductPage(query *ProductQuery) ([]gorose.Data, int64, error) {
+	total, err := p.filter(p.db().Table(p.getTableName()), query).Count()
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return nil, 0, err
+	}
+
+	list, err := p.filter(p.db().Table(p.getTableName()), query).
+		Order("product_id desc").Limit(query.PageSize).Page(query.Page).Get()
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return nil, 0, err
+	}
+	return list, total, nil
+}
+
+func (p *ProductModel) filter(conn gorose.IOrm, query *ProductQuery) gorose.IOrm {
+	if query.ProductName != "" {
+		conn = conn.Where("product_name", "like", "%"+query.ProductName+"%")
+	}
+	if query.Status >= 0 {
+		conn = conn.Where("status", query.Status)
+	}
+	return conn
+}
+
+// GetProductById 根据商品Id查询商品，商品不存在时返回 ErrProductNotFound
+func (p *ProductModel) GetProductById(productId int) (*Product, error) {
+	conn := p.db()
+	data, err := conn.Table(p.getTableName()).Where("product_id", productId).First()
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, ErrProductNotFound
+	}
+	return ToProduct(data), nil
+}
+
+// CreateProduct 写入商品, 并将生成的商品Id设置到 product.ProductId
 func (p *ProductModel) CreateProduct(product *Product) error {
-	conn := mysql.DB()
-	_, err := conn.Table(p.getTableName()).Data(map[string]interface{}{
+	conn := p.db()
+	id, err := conn.Table(p.getTableName()).Data(map[string]interface{}{
 		"product_name": product.ProductName,
 		"total":        product.Total,
 		"status":       product.Status,
-	}).Insert()
+	}).InsertGetId()
 	if err != nil {
 		log.Printf("Error : %v", err)
 		return err
 	}
+	product.ProductId = int(id)
 	return nil
 }
+
+// UpdateProduct 修改商品名称和状态，库存只能通过 AdjustStock 修改
+func (p *ProductModel) UpdateProduct(product *Product) error {
+	conn := p.db()
+	_, err := conn.Table(p.getTableName()).Data(map[string]interface{}{
+		"product_name": product.ProductName,
+		"status":       product.Status,
+	}).Where("product_id", product.ProductId).Update()
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return err
+	}
+	return nil
+}
+
+func (p *ProductModel) DeleteProduct(productId int) error {
+	conn := p.db()
+	_, err := conn.Table(p.getTableName()).Where("product_id", productId).Delete()
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return err
+	}
+	return nil
+}
+
+// GetProductByIdForUpdate 在事务中查询并锁定商品行, 直到事务结束
+func (p *ProductModel) GetProductByIdForUpdate(productId int) (*Product, error) {
+	conn := p.db()
+	data, err := conn.Table(p.getTableName()).Where("product_id", productId).LockForUpdate().First()
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, ErrProductNotFound
+	}
+	return ToProduct(data), nil
+}
+
+// AdjustStock 在事务中锁定商品行并按 delta 调整库存，返回调整前后的库存
+// 调整后库存为负时返回 ErrInsufficientStock, 低于 minTotal 时返回 ErrStockBelowActivity
+func (p *ProductModel) AdjustStock(productId int, delta int, minTotal int) (before int, after int, err error) {
+	conn := p.db()
+	data, err := conn.Table(p.getTableName()).Where("product_id", productId).LockForUpdate().First()
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return 0, 0, err
+	}
+	if len(data) == 0 {
+		return 0, 0, ErrProductNotFound
+	}
+
+	before = ToProduct(data).Total
+	after = before + delta
+	if after < 0 {
+		return before, before, ErrInsufficientStock
+	}
+	if delta < 0 && after < minTotal {
+		return before, before, ErrStockBelowActivity
+	}
+
+	_, err = conn.Table(p.getTableName()).Data(map[string]interface{}{
+		"total": after,
+	}).Where("product_id", productId).Update()
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return 0, 0, err
+	}
+	return before, after, nil
+}
+
+// ToProduct 将数据库中查询到的一行商品数据转换为 Product
+func ToProduct(data gorose.Data) *Product {
+	product := &Product{}
+	product.ProductId, _ = com.StrTo(fmt.Sprint(data["product_id"])).Int()
+	product.ProductName = fmt.Sprint(data["product_name"])
+	product.Total, _ = com.StrTo(fmt.Sprint(data["total"])).Int()
+	product.Status, _ = com.StrTo(fmt.Sprint(data["status"])).Int()
+	return product
+}
@@ -0,0 +1,118 @@
+package model
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/gohouse/gorose/v2"
+	"github.com/lixichongAAA/seckill/pkg/mysql"
+	"github.com/unknwon/com"
+)
+
+// ResultCodeSecKillSucc 与 sk-core 中抢购成功的状态码一致
+const ResultCodeSecKillSucc = 1002
+
+// SecResult sk-core 写入的一次秒杀请求的处理结果
+type SecResult struct {
+	Id         int64  `json:"id"`
+	ActivityId int    `json:"activity_id"`
+	ProductId  int    `json:"product_id"`
+	UserId     int    `json:"user_id"`
+	Code       int    `json:"code"`
+	Token      string `json:"token"`
+	TokenTime  int64  `json:"token_time"`
+	CreateTime int64  `json:"create_time"`
+}
+
+// ThroughputPoint 每分钟处理的请求数及其中的抢购成功数
+type ThroughputPoint struct {
+	Minute  int64 `json:"minute"` //该分钟开始的时间戳
+	Total   int64 `json:"total"`
+	Winners int64 `json:"winners"`
+}
+
+// RejectionCount 按错误码统计的拒绝数
+type RejectionCount struct {
+	Code  int   `json:"code"`
+	Count int64 `json:"count"`
+}
+
+type ResultModel struct {
+}
+
+func NewResultModel() *ResultModel {
+	return &ResultModel{}
+}
+
+func (p *ResultModel) getTableName() string {
+	return "sec_result"
+}
+
+// GetWinnerList 按抢购时间顺序查询活动的中奖记录
+func (p *ResultModel) GetWinnerList(activityId int) ([]*SecResult, error) {
+	conn := mysql.DB()
+	list, err := conn.Table(p.getTableName()).Where("activity_id", activityId).
+		Where("code", ResultCodeSecKillSucc).Order("id asc").Get()
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return nil, err
+	}
+	resultList := make([]*SecResult, 0, len(list))
+	for _, v := range list {
+		resultList = append(resultList, toSecResult(v))
+	}
+	return resultList, nil
+}
+
+// GetThroughput 按分钟统计活动处理的请求数
+func (p *ResultModel) GetThroughput(activityId int) ([]*ThroughputPoint, error) {
+	conn := mysql.DB()
+	list, err := conn.Query("SELECT create_time - create_time % 60 AS minute, COUNT(*) AS total, "+
+		"SUM(CASE WHEN code = ? THEN 1 ELSE 0 END) AS winners FROM "+p.getTableName()+
+		" WHERE activity_id = ? GROUP BY minute ORDER BY minute ASC", ResultCodeSecKillSucc, activityId)
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return nil, err
+	}
+	points := make([]*ThroughputPoint, 0, len(list))
+	for _, v := range list {
+		point := &ThroughputPoint{}
+		point.Minute, _ = com.StrTo(fmt.Sprint(v["minute"])).Int64()
+		point.Total, _ = com.StrTo(fmt.Sprint(v["total"])).Int64()
+		point.Winners, _ = com.StrTo(fmt.Sprint(v["winners"])).Int64()
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// GetRejections 按错误码统计活动中未抢购成功的请求数
+func (p *ResultModel) GetRejections(activityId int) ([]*RejectionCount, error) {
+	conn := mysql.DB()
+	list, err := conn.Query("SELECT code, COUNT(*) AS count FROM "+p.getTableName()+
+		" WHERE activity_id = ? AND code <> ? GROUP BY code ORDER BY count DESC", activityId, ResultCodeSecKillSucc)
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return nil, err
+	}
+	rejections := make([]*RejectionCount, 0, len(list))
+	for _, v := range list {
+		rejection := &RejectionCount{}
+		rejection.Code, _ = com.StrTo(fmt.Sprint(v["code"])).Int()
+		rejection.Count, _ = com.StrTo(fmt.Sprint(v["count"])).Int64()
+		rejections = append(rejections, rejection)
+	}
+	return rejections, nil
+}
+
+func toSecResult(data gorose.Data) *SecResult {
+	result := &SecResult{}
+	result.Id, _ = com.StrTo(fmt.Sprint(data["id"])).Int64()
+	result.ActivityId, _ = com.StrTo(fmt.Sprint(data["activity_id"])).Int()
+	result.ProductId, _ = com.StrTo(fmt.Sprint(data["product_id"])).Int()
+	result.UserId, _ = com.StrTo(fmt.Sprint(data["user_id"])).Int()
+	result.Code, _ = com.StrTo(fmt.Sprint(data["code"])).Int()
+	result.Token = fmt.Sprint(data["token"])
+	result.TokenTime, _ = com.StrTo(fmt.Sprint(data["token_time"])).Int64()
+	result.CreateTime, _ = com.StrTo(fmt.Sprint(data["create_time"])).Int64()
+	return result
+}
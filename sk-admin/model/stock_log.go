@@ -0,0 +1,95 @@
+package model
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gohouse/gorose/v2"
+	"github.com/lixichongAAA/seckill/pkg/mysql"
+	"github.com/unknwon/com"
+)
+
+// StockLog 商品库存调整记录
+type StockLog struct {
+	Id          int64  `json:"id"`
+	ProductId   int    `json:"product_id"`   //商品Id
+	Delta       int    `json:"delta"`        //调整数量, 负数表示减少
+	BeforeTotal int    `json:"before_total"` //调整前库存
+	AfterTotal  int    `json:"after_total"`  //调整后库存
+	Reason      string `json:"reason"`       //调整原因
+	Operator    string `json:"operator"`     //操作人
+	CreateTime  int64  `json:"create_time"`
+}
+
+type StockLogModel struct {
+	conn gorose.IOrm
+}
+
+func NewStockLogModel() *StockLogModel {
+	return &StockLogModel{}
+}
+
+// NewStockLogModelWithTx 返回使用指定连接的 StockLogModel, 用于与库存调整在同一事务中写入记录
+func NewStockLogModelWithTx(db gorose.IOrm) *StockLogModel {
+	return &StockLogModel{conn: db}
+}
+
+func (p *StockLogModel) db() gorose.IOrm {
+	if p.conn != nil {
+		return p.conn
+	}
+	return mysql.DB()
+}
+
+func (p *StockLogModel) getTableName() string {
+	return "product_stock_log"
+}
+
+func (p *StockLogModel) CreateStockLog(stockLog *StockLog) error {
+	conn := p.db()
+	stockLog.CreateTime = time.Now().Unix()
+	id, err := conn.Table(p.getTableName()).Data(map[string]interface{}{
+		"product_id":   stockLog.ProductId,
+		"delta":        stockLog.Delta,
+		"before_total": stockLog.BeforeTotal,
+		"after_total":  stockLog.AfterTotal,
+		"reason":       stockLog.Reason,
+		"operator":     stockLog.Operator,
+		"create_time":  stockLog.CreateTime,
+	}).InsertGetId()
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return err
+	}
+	stockLog.Id = id
+	return nil
+}
+
+// GetStockLogList 按时间倒序查询商品的库存调整记录
+func (p *StockLogModel) GetStockLogList(productId int) ([]*StockLog, error) {
+	conn := p.db()
+	list, err := conn.Table(p.getTableName()).Where("product_id", productId).Order("id desc").Get()
+	if err != nil {
+		log.Printf("Error : %v", err)
+		return nil, err
+	}
+	stockLogList := make([]*StockLog, 0, len(list))
+	for _, v := range list {
+		stockLogList = append(stockLogList, toStockLog(v))
+	}
+	return stockLogList, nil
+}
+
+func toStockLog(data gorose.Data) *StockLog {
+	stockLog := &StockLog{}
+	stockLog.Id, _ = com.StrTo(fmt.Sprint(data["id"])).Int64()
+	stockLog.ProductId, _ = com.StrTo(fmt.Sprint(data["product_id"])).Int()
+	stockLog.Delta, _ = com.StrTo(fmt.Sprint(data["delta"])).Int()
+	stockLog.BeforeTotal, _ = com.StrTo(fmt.Sprint(data["before_total"])).Int()
+	stockLog.AfterTotal, _ = com.StrTo(fmt.Sprint(data["after_total"])).Int()
+	stockLog.Reason = fmt.Sprint(data["reason"])
+	stockLog.Operator = fmt.Sprint(data["operator"])
+	stockLog.CreateTime, _ = com.StrTo(fmt.Sprint(data["create_time"])).Int64()
+	return stockLog
+}
@@ -60,6 +60,30 @@ type productMetricMiddleware struct {
 	requestLatency metrics.Histogram
 }
 
+type dashboardMetricMiddleware struct {
+	service.DashboardService
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+}
+
+type reportMetricMiddleware struct {
+	service.ReportService
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+}
+
+type blacklistMetricMiddleware struct {
+	service.BlacklistService
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+}
+
+type auditMetricMiddleware struct {
+	service.AuditService
+	requestCount   metrics.Counter
+	requestLatency metrics.Histogram
+}
+
 // Metrics 封装监控方法
 func SkAdminMetrics(requestCount metrics.Counter, requestLatency metrics.Histogram) service.ServiceMiddleware {
 	return func(next service.Service) service.Service {
@@ -90,6 +114,26 @@ func ActivityMetrics(requestCount metrics.Counter, requestLatency metrics.Histog
 	}
 }
 
+// Metrics 封装监控方法
+func DashboardMetrics(requestCount metrics.Counter, requestLatency metrics.Histogram) service.DashboardServiceMiddleware {
+	return func(next service.DashboardService) service.DashboardService {
+		return dashboardMetricMiddleware{
+			next,
+			requestCount,
+			requestLatency}
+	}
+}
+
+// Metrics 封装监控方法
+func ReportMetrics(requestCount metrics.Counter, requestLatency metrics.Histogram) service.ReportServiceMiddleware {
+	return func(next service.ReportService) service.ReportService {
+		return reportMetricMiddleware{
+			next,
+			requestCount,
+			requestLatency}
+	}
+}
+
 func (mw skAdminMetricMiddleware) HealthCheck() (result bool) {
 
 	defer func(begin time.Time) {
@@ -114,7 +158,7 @@ func (mw productMetricMiddleware) CreateProduct(product *model.Product) error {
 	return error
 }
 
-func (mw productMetricMiddleware) GetProductList() ([]gorose.Data, error) {
+func (mw productMetricMiddleware) GetProductList(query *model.ProductQuery) ([]gorose.Data, int64, error) {
 
 	defer func(begin time.Time) {
 		lvs := []string{"method", "HealthCheck"}
@@ -122,8 +166,68 @@ func (mw productMetricMiddleware) GetProductList() ([]gorose.Data, error) {
 		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
 	}(time.Now())
 
-	data, error := mw.ProductService.GetProductList()
-	return data, error
+	data, total, error := mw.ProductService.GetProductList(query)
+	return data, total, error
+}
+
+func (mw productMetricMiddleware) GetProduct(productId int) (*model.Product, error) {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "GetProduct"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	result, error := mw.ProductService.GetProduct(productId)
+	return result, error
+}
+
+func (mw productMetricMiddleware) UpdateProduct(product *model.Product) error {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "UpdateProduct"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	error := mw.ProductService.UpdateProduct(product)
+	return error
+}
+
+func (mw productMetricMiddleware) DeleteProduct(productId int) error {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "DeleteProduct"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	error := mw.ProductService.DeleteProduct(productId)
+	return error
+}
+
+func (mw productMetricMiddleware) AdjustStock(stockLog *model.StockLog) error {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "AdjustStock"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	error := mw.ProductService.AdjustStock(stockLog)
+	return error
+}
+
+func (mw productMetricMiddleware) GetStockLogList(productId int) ([]*model.StockLog, error) {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "GetStockLogList"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	result, error := mw.ProductService.GetStockLogList(productId)
+	return result, error
 }
 
 func (mw activityMetricMiddleware) GetActivityList() ([]gorose.Data, error) {
@@ -149,3 +253,263 @@ func (mw activityMetricMiddleware) CreateActivity(activity *model.Activity) erro
 	error := mw.ActivityService.CreateActivity(activity)
 	return error
 }
+
+func (mw activityMetricMiddleware) UpdateActivity(activity *model.Activity) error {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "UpdateActivity"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	error := mw.ActivityService.UpdateActivity(activity)
+	return error
+}
+
+func (mw activityMetricMiddleware) DisableActivity(activityId int) error {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "DisableActivity"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	error := mw.ActivityService.DisableActivity(activityId)
+	return error
+}
+
+func (mw activityMetricMiddleware) EnableActivity(activityId int) error {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "EnableActivity"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	error := mw.ActivityService.EnableActivity(activityId)
+	return error
+}
+
+func (mw activityMetricMiddleware) DeleteActivity(activityId int) error {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "DeleteActivity"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	error := mw.ActivityService.DeleteActivity(activityId)
+	return error
+}
+
+func (mw activityMetricMiddleware) CloneActivity(activityId int) (*model.Activity, error) {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "CloneActivity"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	result, error := mw.ActivityService.CloneActivity(activityId)
+	return result, error
+}
+
+func (mw dashboardMetricMiddleware) GetDashboard() ([]*service.ActivityDashboard, error) {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "GetDashboard"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	result, error := mw.DashboardService.GetDashboard()
+	return result, error
+}
+
+func (mw dashboardMetricMiddleware) GetActivityDashboard(activityId int) (*service.ActivityDashboard, error) {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "GetActivityDashboard"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	result, error := mw.DashboardService.GetActivityDashboard(activityId)
+	return result, error
+}
+
+func (mw reportMetricMiddleware) GetWinnerReport(activityId int) ([]*model.SecResult, error) {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "GetWinnerReport"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	result, error := mw.ReportService.GetWinnerReport(activityId)
+	return result, error
+}
+
+func (mw reportMetricMiddleware) GetThroughputReport(activityId int) ([]*model.ThroughputPoint, error) {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "GetThroughputReport"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	result, error := mw.ReportService.GetThroughputReport(activityId)
+	return result, error
+}
+
+func (mw reportMetricMiddleware) GetRejectionReport(activityId int) ([]*model.RejectionCount, error) {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "GetRejectionReport"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	result, error := mw.ReportService.GetRejectionReport(activityId)
+	return result, error
+}
+
+// Metrics 封装监控方法
+func BlacklistMetrics(requestCount metrics.Counter, requestLatency metrics.Histogram) service.BlacklistServiceMiddleware {
+	return func(next service.BlacklistService) service.BlacklistService {
+		return blacklistMetricMiddleware{
+			next,
+			requestCount,
+			requestLatency}
+	}
+}
+
+// Metrics 封装监控方法
+func AuditMetrics(requestCount metrics.Counter, requestLatency metrics.Histogram) service.AuditServiceMiddleware {
+	return func(next service.AuditService) service.AuditService {
+		return auditMetricMiddleware{
+			next,
+			requestCount,
+			requestLatency}
+	}
+}
+
+func (mw blacklistMetricMiddleware) AddBlacklist(blacklistType string, value string) error {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "AddBlacklist"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	error := mw.BlacklistService.AddBlacklist(blacklistType, value)
+	return error
+}
+
+func (mw blacklistMetricMiddleware) RemoveBlacklist(blacklistType string, value string) error {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "RemoveBlacklist"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	error := mw.BlacklistService.RemoveBlacklist(blacklistType, value)
+	return error
+}
+
+func (mw blacklistMetricMiddleware) IsBlacklisted(blacklistType string, value string) (bool, error) {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "IsBlacklisted"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	result, error := mw.BlacklistService.IsBlacklisted(blacklistType, value)
+	return result, error
+}
+
+func (mw blacklistMetricMiddleware) GetBlacklist() (*service.Blacklist, error) {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "GetBlacklist"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	result, error := mw.BlacklistService.GetBlacklist()
+	return result, error
+}
+
+func (mw auditMetricMiddleware) Record(auditLog *model.AuditLog) error {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "Record"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	error := mw.AuditService.Record(auditLog)
+	return error
+}
+
+func (mw auditMetricMiddleware) GetAuditLogList(query *model.AuditQuery) ([]*model.AuditLog, int64, error) {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "GetAuditLogList"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	result, total, error := mw.AuditService.GetAuditLogList(query)
+	return result, total, error
+}
+
+func (mw auditMetricMiddleware) Snapshot(resource string, targetId string) (string, error) {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "Snapshot"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	result, error := mw.AuditService.Snapshot(resource, targetId)
+	return result, error
+}
+
+func (mw activityMetricMiddleware) GetActivity(activityId int) (*model.Activity, error) {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "GetActivity"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	result, error := mw.ActivityService.GetActivity(activityId)
+	return result, error
+}
+
+func (mw activityMetricMiddleware) GetActivityListByStatus(status ...int) ([]*model.Activity, error) {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "GetActivityListByStatus"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	result, error := mw.ActivityService.GetActivityListByStatus(status...)
+	return result, error
+}
+
+func (mw activityMetricMiddleware) GetProductConfList() ([]*model.SecProductInfoConf, error) {
+
+	defer func(begin time.Time) {
+		lvs := []string{"method", "GetProductConfList"}
+		mw.requestCount.With(lvs...).Add(1)
+		mw.requestLatency.With(lvs...).Observe(time.Since(begin).Seconds())
+	}(time.Now())
+
+	result, error := mw.ActivityService.GetProductConfList()
+	return result, error
+}
@@ -26,6 +26,26 @@ type productLoggingMiddleware struct {
 	logger log.Logger
 }
 
+type dashboardLoggingMiddleware struct {
+	service.DashboardService
+	logger log.Logger
+}
+
+type reportLoggingMiddleware struct {
+	service.ReportService
+	logger log.Logger
+}
+
+type blacklistLoggingMiddleware struct {
+	service.BlacklistService
+	logger log.Logger
+}
+
+type auditLoggingMiddleware struct {
+	service.AuditService
+	logger log.Logger
+}
+
 // LoggingMiddleware make logging middleware
 func SkAdminLoggingMiddleware(logger log.Logger) service.ServiceMiddleware {
 	return func(next service.Service) service.Service {
@@ -45,6 +65,30 @@ func ProductLoggingMiddleware(logger log.Logger) service.ProductServiceMiddlewar
 	}
 }
 
+func DashboardLoggingMiddleware(logger log.Logger) service.DashboardServiceMiddleware {
+	return func(next service.DashboardService) service.DashboardService {
+		return dashboardLoggingMiddleware{next, logger}
+	}
+}
+
+func ReportLoggingMiddleware(logger log.Logger) service.ReportServiceMiddleware {
+	return func(next service.ReportService) service.ReportService {
+		return reportLoggingMiddleware{next, logger}
+	}
+}
+
+func BlacklistLoggingMiddleware(logger log.Logger) service.BlacklistServiceMiddleware {
+	return func(next service.BlacklistService) service.BlacklistService {
+		return blacklistLoggingMiddleware{next, logger}
+	}
+}
+
+func AuditLoggingMiddleware(logger log.Logger) service.AuditServiceMiddleware {
+	return func(next service.AuditService) service.AuditService {
+		return auditLoggingMiddleware{next, logger}
+	}
+}
+
 func (mw productLoggingMiddleware) CreateProduct(product *model.Product) (err error) {
 
 	defer func(begin time.Time) {
@@ -59,17 +103,88 @@ func (mw productLoggingMiddleware) CreateProduct(product *model.Product) (err er
 	return err
 }
 
-func (mw productLoggingMiddleware) GetProductList() ([]gorose.Data, error) {
+func (mw productLoggingMiddleware) GetProductList(query *model.ProductQuery) ([]gorose.Data, int64, error) {
 
 	defer func(begin time.Time) {
 		_ = mw.logger.Log(
 			"function", "Check",
+			"query", query,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	data, total, err := mw.ProductService.GetProductList(query)
+	return data, total, err
+}
+
+func (mw productLoggingMiddleware) GetProduct(productId int) (*model.Product, error) {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "GetProduct",
+			"productId", productId,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	ret, err := mw.ProductService.GetProduct(productId)
+	return ret, err
+}
+
+func (mw productLoggingMiddleware) UpdateProduct(product *model.Product) error {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "UpdateProduct",
+			"product", product,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err := mw.ProductService.UpdateProduct(product)
+	return err
+}
+
+func (mw productLoggingMiddleware) DeleteProduct(productId int) error {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "DeleteProduct",
+			"productId", productId,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err := mw.ProductService.DeleteProduct(productId)
+	return err
+}
+
+func (mw productLoggingMiddleware) AdjustStock(stockLog *model.StockLog) error {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "AdjustStock",
+			"stockLog", stockLog,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err := mw.ProductService.AdjustStock(stockLog)
+	return err
+}
+
+func (mw productLoggingMiddleware) GetStockLogList(productId int) ([]*model.StockLog, error) {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "GetStockLogList",
+			"productId", productId,
 			"took", time.Since(begin),
 		)
 	}(time.Now())
 
-	data, err := mw.ProductService.GetProductList()
-	return data, err
+	ret, err := mw.ProductService.GetStockLogList(productId)
+	return ret, err
 }
 
 func (mw activityLoggingMiddleware) GetActivityList() ([]gorose.Data, error) {
@@ -110,3 +225,284 @@ func (mw skAdminLoggingMiddleware) HealthCheck() (result bool) {
 	result = mw.Service.HealthCheck()
 	return
 }
+
+func (mw activityLoggingMiddleware) UpdateActivity(activity *model.Activity) error {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "UpdateActivity",
+			"activity", activity,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err := mw.ActivityService.UpdateActivity(activity)
+	return err
+}
+
+func (mw activityLoggingMiddleware) DisableActivity(activityId int) error {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "DisableActivity",
+			"activityId", activityId,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err := mw.ActivityService.DisableActivity(activityId)
+	return err
+}
+
+func (mw activityLoggingMiddleware) EnableActivity(activityId int) error {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "EnableActivity",
+			"activityId", activityId,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err := mw.ActivityService.EnableActivity(activityId)
+	return err
+}
+
+func (mw activityLoggingMiddleware) DeleteActivity(activityId int) error {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "DeleteActivity",
+			"activityId", activityId,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err := mw.ActivityService.DeleteActivity(activityId)
+	return err
+}
+
+func (mw activityLoggingMiddleware) CloneActivity(activityId int) (*model.Activity, error) {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "CloneActivity",
+			"activityId", activityId,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	ret, err := mw.ActivityService.CloneActivity(activityId)
+	return ret, err
+}
+
+func (mw dashboardLoggingMiddleware) GetDashboard() ([]*service.ActivityDashboard, error) {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "GetDashboard",
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	ret, err := mw.DashboardService.GetDashboard()
+	return ret, err
+}
+
+func (mw dashboardLoggingMiddleware) GetActivityDashboard(activityId int) (*service.ActivityDashboard, error) {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "GetActivityDashboard",
+			"activityId", activityId,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	ret, err := mw.DashboardService.GetActivityDashboard(activityId)
+	return ret, err
+}
+
+func (mw reportLoggingMiddleware) GetWinnerReport(activityId int) ([]*model.SecResult, error) {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "GetWinnerReport",
+			"activityId", activityId,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	ret, err := mw.ReportService.GetWinnerReport(activityId)
+	return ret, err
+}
+
+func (mw reportLoggingMiddleware) GetThroughputReport(activityId int) ([]*model.ThroughputPoint, error) {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "GetThroughputReport",
+			"activityId", activityId,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	ret, err := mw.ReportService.GetThroughputReport(activityId)
+	return ret, err
+}
+
+func (mw reportLoggingMiddleware) GetRejectionReport(activityId int) ([]*model.RejectionCount, error) {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "GetRejectionReport",
+			"activityId", activityId,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	ret, err := mw.ReportService.GetRejectionReport(activityId)
+	return ret, err
+}
+
+func (mw blacklistLoggingMiddleware) AddBlacklist(blacklistType string, value string) error {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "AddBlacklist",
+			"blacklistType", blacklistType,
+			"value", value,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err := mw.BlacklistService.AddBlacklist(blacklistType, value)
+	return err
+}
+
+func (mw blacklistLoggingMiddleware) RemoveBlacklist(blacklistType string, value string) error {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "RemoveBlacklist",
+			"blacklistType", blacklistType,
+			"value", value,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err := mw.BlacklistService.RemoveBlacklist(blacklistType, value)
+	return err
+}
+
+func (mw blacklistLoggingMiddleware) IsBlacklisted(blacklistType string, value string) (bool, error) {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "IsBlacklisted",
+			"blacklistType", blacklistType,
+			"value", value,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	ret, err := mw.BlacklistService.IsBlacklisted(blacklistType, value)
+	return ret, err
+}
+
+func (mw blacklistLoggingMiddleware) GetBlacklist() (*service.Blacklist, error) {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "GetBlacklist",
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	ret, err := mw.BlacklistService.GetBlacklist()
+	return ret, err
+}
+
+func (mw auditLoggingMiddleware) Record(auditLog *model.AuditLog) error {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "Record",
+			"auditLog", auditLog,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	err := mw.AuditService.Record(auditLog)
+	return err
+}
+
+func (mw auditLoggingMiddleware) GetAuditLogList(query *model.AuditQuery) ([]*model.AuditLog, int64, error) {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "GetAuditLogList",
+			"query", query,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	ret, total, err := mw.AuditService.GetAuditLogList(query)
+	return ret, total, err
+}
+
+func (mw auditLoggingMiddleware) Snapshot(resource string, targetId string) (string, error) {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "Snapshot",
+			"resource", resource,
+			"targetId", targetId,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	ret, err := mw.AuditService.Snapshot(resource, targetId)
+	return ret, err
+}
+
+func (mw activityLoggingMiddleware) GetActivity(activityId int) (*model.Activity, error) {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "GetActivity",
+			"activityId", activityId,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	ret, err := mw.ActivityService.GetActivity(activityId)
+	return ret, err
+}
+
+func (mw activityLoggingMiddleware) GetActivityListByStatus(status ...int) ([]*model.Activity, error) {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "GetActivityListByStatus",
+			"status", status,
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	ret, err := mw.ActivityService.GetActivityListByStatus(status...)
+	return ret, err
+}
+
+func (mw activityLoggingMiddleware) GetProductConfList() ([]*model.SecProductInfoConf, error) {
+
+	defer func(begin time.Time) {
+		_ = mw.logger.Log(
+			"function", "GetProductConfList",
+			"took", time.Since(begin),
+		)
+	}(time.Now())
+
+	ret, err := mw.ActivityService.GetProductConfList()
+	return ret, err
+}
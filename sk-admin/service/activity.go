@@ -1,22 +1,27 @@
 package service
 
 import (
-	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/gohouse/gorose/v2"
-	conf "github.com/lixichongAAA/seckill/pkg/config"
+	"github.com/lixichongAAA/seckill/pkg/mysql"
 	"github.com/lixichongAAA/seckill/sk-admin/model"
-	"github.com/samuel/go-zookeeper/zk"
 	"github.com/unknwon/com"
 )
 
 type ActivityService interface {
 	GetActivityList() ([]gorose.Data, error)
 	CreateActivity(activity *model.Activity) error
+	UpdateActivity(activity *model.Activity) error
+	DisableActivity(activityId int) error
+	EnableActivity(activityId int) error
+	DeleteActivity(activityId int) error
+	CloneActivity(activityId int) (*model.Activity, error)
+	GetActivity(activityId int) (*model.Activity, error)
+	GetActivityListByStatus(status ...int) ([]*model.Activity, error)
+	GetProductConfList() ([]*model.SecProductInfoConf, error)
 }
 
 type ActivityServiceMiddleware func(ActivityService) ActivityService
@@ -35,22 +40,14 @@ func (p ActivityServiceImpl) GetActivityList() ([]gorose.Data, error) {
 
 	for _, v := range activityList {
 		startTime, _ := com.StrTo(fmt.Sprint(v["start_time"])).Int64()
-		v["start_time_str"] = time.Unix(startTime, 0).Format("2006-01-02 15:04:05")
+		v["start_time_str"] = formatTime(startTime)
 
 		endTime, _ := com.StrTo(fmt.Sprint(v["end_time"])).Int64()
-		v["end_time_str"] = time.Unix(endTime, 0).Format("2006-01-02 15:04:05")
-
-		nowTime := time.Now().Unix()
-		if nowTime > endTime {
-			v["status_str"] = "已结束"
-			continue
-		}
+		v["end_time_str"] = formatTime(endTime)
 
 		status, _ := com.StrTo(fmt.Sprint(v["status"])).Int()
-		if status == model.ActivityStatusNormal {
-			v["status_str"] = "正常"
-		} else if status == model.ActivityStatusDisable {
-			v["status_str"] = "已禁用"
+		if statusStr := activityStatusStr(status, endTime); statusStr != "" {
+			v["status_str"] = statusStr
 		}
 	}
 
@@ -58,99 +55,264 @@ func (p ActivityServiceImpl) GetActivityList() ([]gorose.Data, error) {
 	return activityList, nil
 }
 
-// CreateActivity 创建秒杀活动，将秒杀活动信息保存到Mysql数据中，并调用 SyncToZk 方法同步到 Zookeeper
+// CreateActivity 创建秒杀活动，将秒杀活动信息与发布事件在同一事务中保存到Mysql，
+// 再由发布器异步同步到 Zookeeper
 func (p ActivityServiceImpl) CreateActivity(activity *model.Activity) error {
 	log.Printf("CreateActivity")
+	// 新活动总是处于正常状态, 忽略请求中的状态
+	activity.Status = model.ActivityStatusNormal
+	//校验活动定义
+	if err := ValidateActivity(activity); err != nil {
+		log.Printf("ValidateActivity, err : %v", err)
+		return err
+	}
 	//写入到数据库
+	err := mysql.DB().Transaction(func(db gorose.IOrm) error {
+		if err := model.NewActivityModelWithTx(db).CreateActivity(activity); err != nil {
+			log.Printf("ActivityModel.CreateActivity, err : %v", err)
+			return err
+		}
+		return p.enqueue(db, activity)
+	})
+	if err != nil {
+		return err
+	}
+
+	DefaultPublisher.Notify()
+	return nil
+}
+
+// UpdateActivity 修改秒杀活动，活动处于正常状态时用新的配置替换 Zookeeper 中的同一活动
+func (p ActivityServiceImpl) UpdateActivity(activity *model.Activity) error {
 	activityEntity := model.NewActivityModel()
-	err := activityEntity.CreateActivity(activity)
+	stored, err := activityEntity.GetActivityById(activity.ActivityId)
 	if err != nil {
-		log.Printf("ActivityModel.CreateActivity, err : %v", err)
+		log.Printf("ActivityModel.GetActivityById, err : %v", err)
+		return err
+	}
+	// 状态只能通过启用/禁用接口和状态调度修改, 忽略请求中的状态
+	activity.Status = stored.Status
+	if err := ValidateActivity(activity); err != nil {
+		log.Printf("ValidateActivity, err : %v", err)
 		return err
 	}
 
-	log.Printf("syncToZk")
-	//写入到Zk
-	err = p.syncToZk(activity)
+	err = mysql.DB().Transaction(func(db gorose.IOrm) error {
+		activityModel := model.NewActivityModelWithTx(db)
+		// 锁定活动行后再读取状态, 按调度器最新修改的状态决定发布或移除
+		locked, err := activityModel.GetActivityByIdForUpdate(activity.ActivityId)
+		if err != nil {
+			log.Printf("ActivityModel.GetActivityByIdForUpdate, err : %v", err)
+			return err
+		}
+		activity.Status = locked.Status
+		if err := activityModel.UpdateActivity(activity); err != nil {
+			log.Printf("ActivityModel.UpdateActivity, err : %v", err)
+			return err
+		}
+		return p.enqueue(db, activity)
+	})
 	if err != nil {
-		log.Printf("activity product info sync to etcd failed, err : %v", err)
 		return err
 	}
+
+	// 活动数量或时间可能已修改, 由调度器按新的配置重新判断是否售罄
+	clearSoldOut(activity.ActivityId)
+	DefaultPublisher.Notify()
 	return nil
 }
 
-// 该方法会将新创建的 Activity 数据同步到 Zookeeper 中
-// 首先从 Zookeeper 上拉取存取的数据，如果数据不为空，则将其转换为 secProductInfoList
-// 然后将新创建的 Activity 添加到该表中，再更新到 Zookeeper上
-func (p ActivityServiceImpl) syncToZk(activity *model.Activity) error {
+// DisableActivity 禁用秒杀活动，并将其从 Zookeeper 的商品配置中移除
+func (p ActivityServiceImpl) DisableActivity(activityId int) error {
+	activityEntity := model.NewActivityModel()
+	if _, err := activityEntity.GetActivityById(activityId); err != nil {
+		log.Printf("ActivityModel.GetActivityById, err : %v", err)
+		return err
+	}
 
-	zkPath := conf.Zk.SecProductKey
-	secProductInfoList, err := p.loadProductFromZk(zkPath)
+	err := mysql.DB().Transaction(func(db gorose.IOrm) error {
+		err := model.NewActivityModelWithTx(db).UpdateActivityStatus(activityId, model.ActivityStatusDisable)
+		if err != nil {
+			log.Printf("ActivityModel.UpdateActivityStatus, err : %v", err)
+			return err
+		}
+		return enqueueRemove(db, activityId)
+	})
 	if err != nil {
-		secProductInfoList = []*model.SecProductInfoConf{}
+		return err
 	}
 
-	var secProductInfo = &model.SecProductInfoConf{}
-	secProductInfo.EndTime = activity.EndTime
-	secProductInfo.OnePersonBuyLimit = activity.BuyLimit
-	secProductInfo.ProductId = activity.ProductId
-	secProductInfo.SoldMaxLimit = activity.Speed
-	secProductInfo.StartTime = activity.StartTime
-	secProductInfo.Status = activity.Status
-	secProductInfo.Total = activity.Total
-	secProductInfo.BuyRate = activity.BuyRate
-	secProductInfoList = append(secProductInfoList, secProductInfo)
+	DefaultPublisher.Notify()
+	return nil
+}
 
-	data, err := json.Marshal(secProductInfoList)
+// EnableActivity 重新启用秒杀活动，并将其发布到 Zookeeper
+func (p ActivityServiceImpl) EnableActivity(activityId int) error {
+	activityEntity := model.NewActivityModel()
+	activity, err := activityEntity.GetActivityById(activityId)
 	if err != nil {
-		log.Printf("json marshal failed, err : %v", err)
+		log.Printf("ActivityModel.GetActivityById, err : %v", err)
+		return err
+	}
+	activity.Status = model.ActivityStatusNormal
+	if err = ValidateActivity(activity); err != nil {
+		log.Printf("ValidateActivity, err : %v", err)
 		return err
 	}
 
-	conn := conf.Zk.ZkConn
+	err = mysql.DB().Transaction(func(db gorose.IOrm) error {
+		err := model.NewActivityModelWithTx(db).UpdateActivityStatus(activityId, model.ActivityStatusNormal)
+		if err != nil {
+			log.Printf("ActivityModel.UpdateActivityStatus, err : %v", err)
+			return err
+		}
+		return enqueuePublish(db, activity)
+	})
+	if err != nil {
+		return err
+	}
 
-	var byteData = []byte(string(data))
-	var flags int32 = 0
-	// permission
-	var acls = zk.WorldACL(zk.PermAll)
+	clearSoldOut(activityId)
+	DefaultPublisher.Notify()
+	return nil
+}
 
-	// create or update
-	exisits, _, _ := conn.Exists(zkPath)
-	if exisits {
-		_, err_set := conn.Set(zkPath, byteData, flags)
-		if err_set != nil {
-			fmt.Println(err_set)
-		}
-	} else {
-		_, err_create := conn.Create(zkPath, byteData, flags, acls)
-		if err_create != nil {
-			fmt.Println(err_create)
+// DeleteActivity 删除秒杀活动，并将其从 Zookeeper 的商品配置中移除
+func (p ActivityServiceImpl) DeleteActivity(activityId int) error {
+	activityEntity := model.NewActivityModel()
+	if _, err := activityEntity.GetActivityById(activityId); err != nil {
+		log.Printf("ActivityModel.GetActivityById, err : %v", err)
+		return err
+	}
+
+	err := mysql.DB().Transaction(func(db gorose.IOrm) error {
+		if err := model.NewActivityModelWithTx(db).DeleteActivity(activityId); err != nil {
+			log.Printf("ActivityModel.DeleteActivity, err : %v", err)
+			return err
 		}
+		return enqueueRemove(db, activityId)
+	})
+	if err != nil {
+		return err
 	}
 
-	log.Printf("put to zk success, data = [%v]", string(data))
+	DefaultPublisher.Notify()
 	return nil
 }
 
-func (p ActivityServiceImpl) loadProductFromZk(key string) ([]*model.SecProductInfoConf, error) {
-	_, cancel := context.WithTimeout(context.Background(), time.Second*10)
-	defer cancel()
-	v, s, err := conf.Zk.ZkConn.Get(key)
+// CloneActivity 以已有活动为模板复制出一个新活动
+// 新活动处于禁用状态，不会同步到 Zookeeper，需要修改时间等信息后再启用
+func (p ActivityServiceImpl) CloneActivity(activityId int) (*model.Activity, error) {
+	activityEntity := model.NewActivityModel()
+	activity, err := activityEntity.GetActivityById(activityId)
+	if err != nil {
+		log.Printf("ActivityModel.GetActivityById, err : %v", err)
+		return nil, err
+	}
+
+	activity.ActivityId = 0
+	activity.ActivityName = activity.ActivityName + "(副本)"
+	activity.Status = model.ActivityStatusDisable
+	if err = ValidateActivity(activity); err != nil {
+		log.Printf("ValidateActivity, err : %v", err)
+		return nil, err
+	}
+
+	err = mysql.DB().Transaction(func(db gorose.IOrm) error {
+		if err := model.NewActivityModelWithTx(db).CreateActivity(activity); err != nil {
+			log.Printf("ActivityModel.CreateActivity, err : %v", err)
+			return err
+		}
+		return p.enqueue(db, activity)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	DefaultPublisher.Notify()
+	return activity, nil
+}
+
+func (p ActivityServiceImpl) GetActivity(activityId int) (*model.Activity, error) {
+	activity, err := model.NewActivityModel().GetActivityById(activityId)
 	if err != nil {
-		log.Printf("get [%s] from zk failed, err : %v", key, err)
+		log.Printf("ActivityModel.GetActivityById, err : %v", err)
 		return nil, err
 	}
-	log.Printf("get from zk success, rsp : %v", s)
+	fillActivityStr(activity)
+	return activity, nil
+}
 
-	var secProductInfo []*model.SecProductInfoConf
-	fmt.Printf("value of path[%s]=[%s].\n", key, v)
+// GetActivityListByStatus 查询处于给定状态之一的活动，不指定状态时返回全部活动
+func (p ActivityServiceImpl) GetActivityListByStatus(status ...int) ([]*model.Activity, error) {
+	activityEntity := model.NewActivityModel()
+	var activityList []*model.Activity
+	if len(status) == 0 {
+		list, err := activityEntity.GetActivityList()
+		if err != nil {
+			log.Printf("ActivityModel.GetActivityList, err : %v", err)
+			return nil, err
+		}
+		activityList = make([]*model.Activity, 0, len(list))
+		for _, v := range list {
+			activityList = append(activityList, model.ToActivity(v))
+		}
+	} else {
+		var err error
+		activityList, err = activityEntity.GetActivityListByStatus(status...)
+		if err != nil {
+			log.Printf("ActivityModel.GetActivityListByStatus, err : %v", err)
+			return nil, err
+		}
+	}
+
+	for _, v := range activityList {
+		fillActivityStr(v)
+	}
+	return activityList, nil
+}
+
+// GetProductConfList 查询当前需要发布的商品配置，即 Zookeeper 中商品配置应有的内容
+func (p ActivityServiceImpl) GetProductConfList() ([]*model.SecProductInfoConf, error) {
+	return loadProductConfList()
+}
+
+func formatTime(t int64) string {
+	return time.Unix(t, 0).Format("2006-01-02 15:04:05")
+}
+
+// activityStatusStr 返回活动状态的展示文字，已过结束时间的活动均显示为已结束
+func activityStatusStr(status int, endTime int64) string {
+	if time.Now().Unix() > endTime {
+		return "已结束"
+	}
+	switch status {
+	case model.ActivityStatusNormal:
+		return "正常"
+	case model.ActivityStatusDisable:
+		return "已禁用"
+	case model.ActivityStatusRunning:
+		return "进行中"
+	case model.ActivityStatusSoldOut:
+		return "已售罄"
+	case model.ActivityStatusExpire:
+		return "已结束"
+	}
+	return ""
+}
+
+func fillActivityStr(activity *model.Activity) {
+	activity.StartTimeStr = formatTime(activity.StartTime)
+	activity.EndTimeStr = formatTime(activity.EndTime)
+	activity.StatusStr = activityStatusStr(activity.Status, activity.EndTime)
+}
 
-	err1 := json.Unmarshal(v, &secProductInfo)
-	if err1 != nil {
-		log.Printf("Unmsharl second product info failed, err : %v", err)
-		return nil, err1
+// enqueue 根据活动状态写入发布或移除事件，禁用或已结束的活动不出现在 Zookeeper 中
+func (p ActivityServiceImpl) enqueue(db gorose.IOrm, activity *model.Activity) error {
+	if !isPublished(activity.Status) {
+		return enqueueRemove(db, activity.ActivityId)
 	}
-	return secProductInfo, nil
+	return enqueuePublish(db, activity)
 }
 
 //将商品活动数据同步到Etcd
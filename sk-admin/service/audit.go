@@ -0,0 +1,109 @@
+package service
+
+import (
+	"encoding/json"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/lixichongAAA/seckill/sk-admin/model"
+)
+
+const (
+	AuditResourceActivity  = "activity"
+	AuditResourceProduct   = "product"
+	AuditResourceBlacklist = "blacklist"
+)
+
+// AuditService 记录及查询管理端的修改操作
+type AuditService interface {
+	Record(auditLog *model.AuditLog) error
+	GetAuditLogList(query *model.AuditQuery) ([]*model.AuditLog, int64, error)
+	// Snapshot 返回对象当前状态的 JSON, 对象不存在时返回空字符串
+	Snapshot(resource string, targetId string) (string, error)
+}
+
+type AuditServiceMiddleware func(AuditService) AuditService
+
+type AuditServiceImpl struct {
+}
+
+func (p AuditServiceImpl) Record(auditLog *model.AuditLog) error {
+	err := model.NewAuditModel().CreateAuditLog(auditLog)
+	if err != nil {
+		log.Printf("AuditModel.CreateAuditLog, err : %v", err)
+		return err
+	}
+	return nil
+}
+
+func (p AuditServiceImpl) GetAuditLogList(query *model.AuditQuery) ([]*model.AuditLog, int64, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = defaultPageSize
+	} else if query.PageSize > maxPageSize {
+		query.PageSize = maxPageSize
+	}
+
+	auditLogList, total, err := model.NewAuditModel().GetAuditLogPage(query)
+	if err != nil {
+		log.Printf("AuditModel.GetAuditLogPage, err : %v", err)
+		return nil, 0, err
+	}
+	return auditLogList, total, nil
+}
+
+// Snapshot 活动和商品按Id读取 Mysql 中的数据, 黑名单的 targetId 为 "类型:值", 读取其是否在黑名单中
+func (p AuditServiceImpl) Snapshot(resource string, targetId string) (string, error) {
+	var snapshot interface{}
+	switch resource {
+	case AuditResourceActivity:
+		activityId, err := strconv.Atoi(targetId)
+		if err != nil {
+			return "", nil
+		}
+		activity, err := model.NewActivityModel().GetActivityById(activityId)
+		if err == model.ErrActivityNotFound {
+			return "", nil
+		} else if err != nil {
+			return "", err
+		}
+		snapshot = activity
+	case AuditResourceProduct:
+		productId, err := strconv.Atoi(targetId)
+		if err != nil {
+			return "", nil
+		}
+		product, err := model.NewProductModel().GetProductById(productId)
+		if err == model.ErrProductNotFound {
+			return "", nil
+		} else if err != nil {
+			return "", err
+		}
+		snapshot = product
+	case AuditResourceBlacklist:
+		parts := strings.SplitN(targetId, ":", 2)
+		if len(parts) != 2 {
+			return "", nil
+		}
+		blacklisted, err := BlacklistServiceImpl{}.IsBlacklisted(parts[0], parts[1])
+		if err != nil {
+			return "", err
+		}
+		snapshot = map[string]interface{}{
+			"type":        parts[0],
+			"value":       parts[1],
+			"blacklisted": blacklisted,
+		}
+	default:
+		return "", nil
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"net"
+	"sort"
+	"strconv"
+
+	conf "github.com/lixichongAAA/seckill/pkg/config"
+)
+
+const (
+	BlacklistTypeId = "id" //用户Id黑名单
+	BlacklistTypeIp = "ip" //用户IP黑名单
+)
+
+var ErrInvalidBlacklist = errors.New("invalid blacklist type or value")
+
+// Blacklist 黑名单列表
+type Blacklist struct {
+	Ids []int    `json:"ids"`
+	Ips []string `json:"ips"`
+}
+
+// BlacklistService 维护 Redis 中的用户Id和IP黑名单
+// 新增时同时写入黑名单 hash 表和同步队列, sk-app 从队列中实时同步;
+// 删除只修改 hash 表, sk-app 定时从 hash 表重新加载黑名单后生效
+type BlacklistService interface {
+	AddBlacklist(blacklistType string, value string) error
+	RemoveBlacklist(blacklistType string, value string) error
+	IsBlacklisted(blacklistType string, value string) (bool, error)
+	GetBlacklist() (*Blacklist, error)
+}
+
+type BlacklistServiceMiddleware func(BlacklistService) BlacklistService
+
+type BlacklistServiceImpl struct {
+}
+
+func (p BlacklistServiceImpl) AddBlacklist(blacklistType string, value string) error {
+	hash, queue, err := p.keys(blacklistType, value)
+	if err != nil {
+		return err
+	}
+	conn := conf.Redis.RedisConn
+	if conn == nil {
+		return ErrRedisNotConnected
+	}
+
+	pipe := conn.TxPipeline()
+	pipe.HSet(hash, value, value)
+	pipe.LPush(queue, value)
+	if _, err = pipe.Exec(); err != nil {
+		log.Printf("add %s blacklist [%s] failed, err : %v", blacklistType, value, err)
+		return err
+	}
+	return nil
+}
+
+func (p BlacklistServiceImpl) RemoveBlacklist(blacklistType string, value string) error {
+	hash, _, err := p.keys(blacklistType, value)
+	if err != nil {
+		return err
+	}
+	conn := conf.Redis.RedisConn
+	if conn == nil {
+		return ErrRedisNotConnected
+	}
+
+	if err = conn.HDel(hash, value).Err(); err != nil {
+		log.Printf("remove %s blacklist [%s] failed, err : %v", blacklistType, value, err)
+		return err
+	}
+	return nil
+}
+
+func (p BlacklistServiceImpl) IsBlacklisted(blacklistType string, value string) (bool, error) {
+	hash, _, err := p.keys(blacklistType, value)
+	if err != nil {
+		return false, err
+	}
+	conn := conf.Redis.RedisConn
+	if conn == nil {
+		return false, ErrRedisNotConnected
+	}
+	return conn.HExists(hash, value).Result()
+}
+
+func (p BlacklistServiceImpl) GetBlacklist() (*Blacklist, error) {
+	conn := conf.Redis.RedisConn
+	if conn == nil {
+		return nil, ErrRedisNotConnected
+	}
+
+	result := &Blacklist{Ids: []int{}, Ips: []string{}}
+	idList, err := conn.HKeys(conf.Redis.IdBlackListHash).Result()
+	if err != nil {
+		log.Printf("hkeys id blacklist failed, err : %v", err)
+		return nil, err
+	}
+	for _, v := range idList {
+		if id, err := strconv.Atoi(v); err == nil {
+			result.Ids = append(result.Ids, id)
+		}
+	}
+	sort.Ints(result.Ids)
+
+	ipList, err := conn.HKeys(conf.Redis.IpBlackListHash).Result()
+	if err != nil {
+		log.Printf("hkeys ip blacklist failed, err : %v", err)
+		return nil, err
+	}
+	result.Ips = append(result.Ips, ipList...)
+	sort.Strings(result.Ips)
+	return result, nil
+}
+
+// keys 校验黑名单取值并返回对应的 hash 表和同步队列
+func (p BlacklistServiceImpl) keys(blacklistType string, value string) (string, string, error) {
+	switch blacklistType {
+	case BlacklistTypeId:
+		if id, err := strconv.Atoi(value); err != nil || id <= 0 {
+			return "", "", ErrInvalidBlacklist
+		}
+		return conf.Redis.IdBlackListHash, conf.Redis.IdBlackListQueue, nil
+	case BlacklistTypeIp:
+		if net.ParseIP(value) == nil {
+			return "", "", ErrInvalidBlacklist
+		}
+		return conf.Redis.IpBlackListHash, conf.Redis.IpBlackListQueue, nil
+	default:
+		return "", "", ErrInvalidBlacklist
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"log"
+
+	conf "github.com/lixichongAAA/seckill/pkg/config"
+	"github.com/lixichongAAA/seckill/pkg/stats"
+	"github.com/lixichongAAA/seckill/sk-admin/model"
+)
+
+var ErrRedisNotConnected = errors.New("redis is not connected")
+
+// ActivityDashboard 秒杀活动的实时数据, 计数由 sk-app 和 sk-core 写入 Redis
+type ActivityDashboard struct {
+	ActivityId   int                  `json:"activity_id"`
+	ActivityName string               `json:"activity_name"`
+	ProductId    int                  `json:"product_id"`
+	Status       int                  `json:"status"`
+	StartTime    int64                `json:"start_time"`
+	EndTime      int64                `json:"end_time"`
+	Total        int                  `json:"total"`     //活动商品总数
+	Remaining    int64                `json:"remaining"` //剩余商品数
+	Stats        *stats.ActivityStats `json:"stats"`
+}
+
+type DashboardService interface {
+	// GetDashboard 返回所有未禁用且未结束的活动的实时数据
+	GetDashboard() ([]*ActivityDashboard, error)
+	// GetActivityDashboard 返回单个活动的实时数据
+	GetActivityDashboard(activityId int) (*ActivityDashboard, error)
+}
+
+type DashboardServiceMiddleware func(DashboardService) DashboardService
+
+type DashboardServiceImpl struct {
+}
+
+func (p DashboardServiceImpl) GetDashboard() ([]*ActivityDashboard, error) {
+	activityList, err := model.NewActivityModel().GetActivityListByStatus(publishedStatus...)
+	if err != nil {
+		log.Printf("ActivityModel.GetActivityListByStatus, err : %v", err)
+		return nil, err
+	}
+
+	result := make([]*ActivityDashboard, 0, len(activityList))
+	for _, activity := range activityList {
+		dashboard, err := p.load(activity)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, dashboard)
+	}
+	return result, nil
+}
+
+func (p DashboardServiceImpl) GetActivityDashboard(activityId int) (*ActivityDashboard, error) {
+	activity, err := model.NewActivityModel().GetActivityById(activityId)
+	if err != nil {
+		log.Printf("ActivityModel.GetActivityById, err : %v", err)
+		return nil, err
+	}
+	return p.load(activity)
+}
+
+func (p DashboardServiceImpl) load(activity *model.Activity) (*ActivityDashboard, error) {
+	conn := conf.Redis.RedisConn
+	if conn == nil {
+		return nil, ErrRedisNotConnected
+	}
+	activityStats, err := stats.Load(conn, activity.ActivityId)
+	if err != nil {
+		log.Printf("load stats of activity %d failed, err : %v", activity.ActivityId, err)
+		return nil, err
+	}
+
+	remaining := int64(activity.Total) - activityStats.Winners
+	if remaining < 0 {
+		remaining = 0
+	}
+	return &ActivityDashboard{
+		ActivityId:   activity.ActivityId,
+		ActivityName: activity.ActivityName,
+		ProductId:    activity.ProductId,
+		Status:       activity.Status,
+		StartTime:    activity.StartTime,
+		EndTime:      activity.EndTime,
+		Total:        activity.Total,
+		Remaining:    remaining,
+		Stats:        activityStats,
+	}, nil
+}
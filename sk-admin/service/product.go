@@ -1,15 +1,29 @@
 package service
 
 import (
+	"errors"
 	"log"
 
 	"github.com/gohouse/gorose/v2"
+	"github.com/lixichongAAA/seckill/pkg/mysql"
 	"github.com/lixichongAAA/seckill/sk-admin/model"
 )
 
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+var ErrProductInUse = errors.New("product is referenced by activity")
+
 type ProductService interface {
 	CreateProduct(product *model.Product) error
-	GetProductList() ([]gorose.Data, error)
+	GetProductList(query *model.ProductQuery) ([]gorose.Data, int64, error)
+	GetProduct(productId int) (*model.Product, error)
+	UpdateProduct(product *model.Product) error
+	DeleteProduct(productId int) error
+	AdjustStock(stockLog *model.StockLog) error
+	GetStockLogList(productId int) ([]*model.StockLog, error)
 }
 
 type ProductServiceMiddleware func(ProductService) ProductService
@@ -27,12 +41,140 @@ func (p ProductServiceImpl) CreateProduct(product *model.Product) error {
 	return nil
 }
 
-func (p ProductServiceImpl) GetProductList() ([]gorose.Data, error) {
+// GetProductList 分页查询商品列表，返回当前页数据和满足条件的总数
+func (p ProductServiceImpl) GetProductList(query *model.ProductQuery) ([]gorose.Data, int64, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PageSize <= 0 {
+		query.PageSize = defaultPageSize
+	} else if query.PageSize > maxPageSize {
+		query.PageSize = maxPageSize
+	}
+
 	productEntity := model.NewProductModel()
-	productList, err := productEntity.GetProductList()
+	productList, total, err := productEntity.GetProductPage(query)
 	if err != nil {
-		log.Printf("ProductEntity.CreateProduct, err : %v", err)
+		log.Printf("ProductEntity.GetProductPage, err : %v", err)
+		return nil, 0, err
+	}
+	return productList, total, nil
+}
+
+func (p ProductServiceImpl) GetProduct(productId int) (*model.Product, error) {
+	productEntity := model.NewProductModel()
+	product, err := productEntity.GetProductById(productId)
+	if err != nil {
+		log.Printf("ProductEntity.GetProductById, err : %v", err)
+		return nil, err
+	}
+	return product, nil
+}
+
+// UpdateProduct 修改商品名称和状态，库存需通过 AdjustStock 调整
+func (p ProductServiceImpl) UpdateProduct(product *model.Product) error {
+	productEntity := model.NewProductModel()
+	if _, err := productEntity.GetProductById(product.ProductId); err != nil {
+		log.Printf("ProductEntity.GetProductById, err : %v", err)
+		return err
+	}
+
+	err := productEntity.UpdateProduct(product)
+	if err != nil {
+		log.Printf("ProductEntity.UpdateProduct, err : %v", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteProduct 删除商品，商品仍被活动引用时返回 ErrProductInUse
+// 检查引用与删除在同一事务中进行, 并锁定商品行, 避免与库存调整等操作并发
+func (p ProductServiceImpl) DeleteProduct(productId int) error {
+	return mysql.DB().Transaction(func(db gorose.IOrm) error {
+		productEntity := model.NewProductModelWithTx(db)
+		if _, err := productEntity.GetProductByIdForUpdate(productId); err != nil {
+			log.Printf("ProductEntity.GetProductByIdForUpdate, err : %v", err)
+			return err
+		}
+
+		activityList, err := model.NewActivityModelWithTx(db).GetActivityListByProductId(productId)
+		if err != nil {
+			log.Printf("ActivityModel.GetActivityListByProductId, err : %v", err)
+			return err
+		}
+		if len(activityList) > 0 {
+			return ErrProductInUse
+		}
+
+		if err = productEntity.DeleteProduct(productId); err != nil {
+			log.Printf("ProductEntity.DeleteProduct, err : %v", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// AdjustStock 按 Delta 调整商品库存，并在同一事务中记录调整前后的库存、原因和操作人
+// 减少库存时, 调整后的库存不能低于该商品下未结束活动的数量, 活动在锁定商品行之后读取
+func (p ProductServiceImpl) AdjustStock(stockLog *model.StockLog) error {
+	err := mysql.DB().Transaction(func(db gorose.IOrm) error {
+		productEntity := model.NewProductModelWithTx(db)
+		if _, err := productEntity.GetProductByIdForUpdate(stockLog.ProductId); err != nil {
+			log.Printf("ProductEntity.GetProductByIdForUpdate, err : %v", err)
+			return err
+		}
+		activityList, err := model.NewActivityModelWithTx(db).GetActivityListByProductId(stockLog.ProductId)
+		if err != nil {
+			log.Printf("ActivityModel.GetActivityListByProductId, err : %v", err)
+			return err
+		}
+
+		before, after, err := productEntity.AdjustStock(stockLog.ProductId, stockLog.Delta, activityTotal(activityList))
+		if err != nil {
+			log.Printf("ProductEntity.AdjustStock, err : %v", err)
+			return err
+		}
+		stockLog.BeforeTotal = before
+		stockLog.AfterTotal = after
+		return model.NewStockLogModelWithTx(db).CreateStockLog(stockLog)
+	})
+	if err != nil {
+		return err
+	}
+
+	// 补充库存后清除该商品下活动的售罄记录, 由调度器重新判断活动状态
+	if stockLog.Delta > 0 {
+		activityList, err := model.NewActivityModel().GetActivityListByProductId(stockLog.ProductId)
+		if err != nil {
+			log.Printf("ActivityModel.GetActivityListByProductId, err : %v", err)
+			return nil
+		}
+		activityIds := make([]int, 0, len(activityList))
+		for _, activity := range activityList {
+			activityIds = append(activityIds, activity.ActivityId)
+		}
+		clearSoldOut(activityIds...)
+	}
+	return nil
+}
+
+// activityTotal 返回未结束活动中最大的活动数量; 同一商品的活动时间不重叠, 库存只需满足其中最大的一个
+func activityTotal(activityList []*model.Activity) int {
+	total := 0
+	for _, activity := range activityList {
+		if activity.Status != model.ActivityStatusExpire && activity.Total > total {
+			total = activity.Total
+		}
+	}
+	return total
+}
+
+func (p ProductServiceImpl) GetStockLogList(productId int) ([]*model.StockLog, error) {
+	stockLogEntity := model.NewStockLogModel()
+	stockLogList, err := stockLogEntity.GetStockLogList(productId)
+	if err != nil {
+		log.Printf("StockLogModel.GetStockLogList, err : %v", err)
 		return nil, err
 	}
-	return productList, nil
+	return stockLogList, nil
 }
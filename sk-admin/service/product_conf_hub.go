@@ -0,0 +1,178 @@
+package service
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lixichongAAA/seckill/sk-admin/model"
+)
+
+const (
+	hubRefreshInterval = time.Second * 5 //定时以 Mysql 为准刷新商品配置的间隔
+	subscriberBuffer   = 64              //每个订阅者可积压的事件数
+)
+
+// DefaultProductConfHub sk-admin 进程内唯一的商品配置分发中心
+var DefaultProductConfHub = NewProductConfHub()
+
+// ProductConfEvent 商品配置变更事件
+// Full 为 true 时 Configs 为全量配置, 否则为相对上一版本新增或修改的配置, Removed 为被移除配置的活动Id
+type ProductConfEvent struct {
+	Version int64
+	Full    bool
+	Configs []*model.SecProductInfoConf
+	Removed []int
+}
+
+// ProductConfSubscriber 商品配置订阅者
+// 订阅者积压的事件超过 subscriberBuffer 时会被移除并关闭事件通道, 需重新订阅获取全量配置
+type ProductConfSubscriber struct {
+	events chan *ProductConfEvent
+}
+
+func (s *ProductConfSubscriber) Events() <-chan *ProductConfEvent {
+	return s.events
+}
+
+// ProductConfHub 在内存中维护当前应发布的商品配置, 定时或在配置变更后以 Mysql 为准刷新,
+// 与上一版本比较后将差异推送给订阅者, 使 sk-app/sk-core 不依赖 Zookeeper 也能获取商品配置
+type ProductConfHub struct {
+	lock        sync.Mutex
+	loaded      bool
+	version     int64
+	confMap     map[int]*model.SecProductInfoConf //活动Id -> 商品配置
+	subscribers map[*ProductConfSubscriber]struct{}
+	notify      chan struct{}
+}
+
+func NewProductConfHub() *ProductConfHub {
+	return &ProductConfHub{
+		confMap:     make(map[int]*model.SecProductInfoConf),
+		subscribers: make(map[*ProductConfSubscriber]struct{}),
+		notify:      make(chan struct{}, 1),
+	}
+}
+
+// Notify 唤醒分发中心立即刷新商品配置，不会阻塞调用方
+func (h *ProductConfHub) Notify() {
+	select {
+	case h.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Run 循环刷新商品配置，由 setup 在独立协程中启动
+func (h *ProductConfHub) Run() {
+	ticker := time.NewTicker(hubRefreshInterval)
+	defer ticker.Stop()
+	for {
+		if err := h.Refresh(); err != nil {
+			log.Printf("ProductConfHub.Refresh, err : %v", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-h.notify:
+		}
+	}
+}
+
+// Refresh 从 Mysql 重新加载商品配置, 有变化时版本号加一并将差异推送给订阅者
+func (h *ProductConfHub) Refresh() error {
+	secProductInfoList, err := loadProductConfList()
+	if err != nil {
+		return err
+	}
+
+	confMap := make(map[int]*model.SecProductInfoConf, len(secProductInfoList))
+	for _, v := range secProductInfoList {
+		confMap[v.ActivityId] = v
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	event := &ProductConfEvent{}
+	for activityId, v := range confMap {
+		if old, ok := h.confMap[activityId]; !ok || *old != *v {
+			event.Configs = append(event.Configs, v)
+		}
+	}
+	for activityId := range h.confMap {
+		if _, ok := confMap[activityId]; !ok {
+			event.Removed = append(event.Removed, activityId)
+		}
+	}
+	h.confMap = confMap
+	h.loaded = true
+	if len(event.Configs) == 0 && len(event.Removed) == 0 {
+		return nil
+	}
+
+	sortProductConf(event.Configs)
+	sort.Ints(event.Removed)
+	h.version++
+	event.Version = h.version
+	for sub := range h.subscribers {
+		select {
+		case sub.events <- event:
+		default:
+			log.Printf("product config subscriber lagged behind, version : %d", h.version)
+			h.removeSubscriber(sub)
+		}
+	}
+	return nil
+}
+
+// Subscribe 注册订阅者, 返回当前版本的全量配置, 之后的变更通过订阅者的事件通道推送
+func (h *ProductConfHub) Subscribe() (*ProductConfSubscriber, *ProductConfEvent, error) {
+	h.lock.Lock()
+	loaded := h.loaded
+	h.lock.Unlock()
+	if !loaded {
+		if err := h.Refresh(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	sub := &ProductConfSubscriber{
+		events: make(chan *ProductConfEvent, subscriberBuffer),
+	}
+	h.subscribers[sub] = struct{}{}
+
+	configs := make([]*model.SecProductInfoConf, 0, len(h.confMap))
+	for _, v := range h.confMap {
+		configs = append(configs, v)
+	}
+	sortProductConf(configs)
+	return sub, &ProductConfEvent{
+		Version: h.version,
+		Full:    true,
+		Configs: configs,
+	}, nil
+}
+
+// Unsubscribe 移除订阅者并关闭其事件通道
+func (h *ProductConfHub) Unsubscribe(sub *ProductConfSubscriber) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.removeSubscriber(sub)
+}
+
+func (h *ProductConfHub) removeSubscriber(sub *ProductConfSubscriber) {
+	if _, ok := h.subscribers[sub]; !ok {
+		return
+	}
+	delete(h.subscribers, sub)
+	close(sub.events)
+}
+
+func sortProductConf(secProductInfoList []*model.SecProductInfoConf) {
+	sort.Slice(secProductInfoList, func(i, j int) bool {
+		return secProductInfoList[i].ActivityId < secProductInfoList[j].ActivityId
+	})
+}
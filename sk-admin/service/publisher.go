@@ -0,0 +1,264 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gohouse/gorose/v2"
+	conf "github.com/lixichongAAA/seckill/pkg/config"
+	"github.com/lixichongAAA/seckill/sk-admin/model"
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+const (
+	publishInterval   = time.Second * 5 //定时扫描 outbox 的间隔
+	publishBatchSize  = 100             //每次最多处理的事件数
+	publishMaxRetries = 10              //单个事件的最大重试次数
+	casMaxAttempts    = 5               //Zookeeper 版本冲突时的最大重试次数
+)
+
+var ErrZkVersionConflict = errors.New("zookeeper product config version conflict")
+
+// DefaultPublisher sk-admin 进程内唯一的商品配置发布器
+var DefaultPublisher = NewProductPublisher()
+
+// ProductPublisher 将 outbox 中的活动变更事件按写入顺序同步到 Zookeeper 的商品配置节点
+// Mysql 中的活动数据与事件在同一事务中提交，发布失败的事件会在下一轮继续重试，
+// 因此即使 Zookeeper 暂时不可用，两者最终也会一致
+type ProductPublisher struct {
+	notify chan struct{}
+}
+
+func NewProductPublisher() *ProductPublisher {
+	return &ProductPublisher{
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// Notify 唤醒发布器立即处理待发布事件，不会阻塞调用方
+func (p *ProductPublisher) Notify() {
+	select {
+	case p.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Run 循环处理待发布事件，由 setup 在独立协程中启动
+func (p *ProductPublisher) Run() {
+	ticker := time.NewTicker(publishInterval)
+	defer ticker.Stop()
+	for {
+		p.publishPending()
+		select {
+		case <-ticker.C:
+		case <-p.notify:
+		}
+	}
+}
+
+func (p *ProductPublisher) publishPending() {
+	outboxEntity := model.NewOutboxModel()
+	events, err := outboxEntity.GetPendingEvents(publishBatchSize)
+	if err != nil {
+		log.Printf("OutboxModel.GetPendingEvents, err : %v", err)
+		return
+	}
+
+	if len(events) == 0 {
+		return
+	}
+	// 无论本轮是否全部发布成功, 已提交到 Mysql 的变更都需要推送给订阅者
+	defer DefaultProductConfHub.Notify()
+
+	for _, event := range events {
+		err = p.apply(event)
+		if err == nil {
+			if err = outboxEntity.MarkDone(event.Id); err != nil {
+				log.Printf("OutboxModel.MarkDone, err : %v", err)
+				return
+			}
+			continue
+		}
+
+		retries := event.Retries + 1
+		failed := retries >= publishMaxRetries
+		log.Printf("publish outbox event [%d] failed, retries : %d, err : %v", event.Id, retries, err)
+		if markErr := outboxEntity.MarkRetry(event.Id, retries, err.Error(), failed); markErr != nil {
+			log.Printf("OutboxModel.MarkRetry, err : %v", markErr)
+		}
+		if !failed {
+			// 保证事件按顺序生效，后面的事件留到下一轮处理
+			return
+		}
+	}
+}
+
+func (p *ProductPublisher) apply(event *model.OutboxEvent) error {
+	switch event.Action {
+	case model.OutboxActionPublish:
+		var secProductInfo model.SecProductInfoConf
+		if err := json.Unmarshal([]byte(event.Payload), &secProductInfo); err != nil {
+			return err
+		}
+		return updateProductConf(func(list []*model.SecProductInfoConf) []*model.SecProductInfoConf {
+			list = removeSecProductInfo(list, event.ActivityId)
+			return append(list, &secProductInfo)
+		})
+	case model.OutboxActionRemove:
+		return updateProductConf(func(list []*model.SecProductInfoConf) []*model.SecProductInfoConf {
+			return removeSecProductInfo(list, event.ActivityId)
+		})
+	default:
+		return errors.New("unknown outbox action " + event.Action)
+	}
+}
+
+// enqueuePublish 在事务中写入发布事件，活动的最新配置作为事件内容
+func enqueuePublish(db gorose.IOrm, activity *model.Activity) error {
+	data, err := json.Marshal(toSecProductInfo(activity))
+	if err != nil {
+		return err
+	}
+	return model.NewOutboxModelWithTx(db).CreateEvent(&model.OutboxEvent{
+		ActivityId: activity.ActivityId,
+		Action:     model.OutboxActionPublish,
+		Payload:    string(data),
+	})
+}
+
+// enqueueRemove 在事务中写入移除事件
+func enqueueRemove(db gorose.IOrm, activityId int) error {
+	return model.NewOutboxModelWithTx(db).CreateEvent(&model.OutboxEvent{
+		ActivityId: activityId,
+		Action:     model.OutboxActionRemove,
+	})
+}
+
+// ReconcileProductConf 以 Mysql 为准重建 Zookeeper 中的商品配置
+// 用于修复两者不一致的情况，只发布未禁用且未结束的活动
+func ReconcileProductConf() error {
+	secProductInfoList, err := loadProductConfList()
+	if err != nil {
+		return err
+	}
+	return updateProductConf(func([]*model.SecProductInfoConf) []*model.SecProductInfoConf {
+		return secProductInfoList
+	})
+}
+
+// loadProductConfList 以 Mysql 中的活动生成应发布的商品配置
+func loadProductConfList() ([]*model.SecProductInfoConf, error) {
+	activityList, err := model.NewActivityModel().GetActivityListByStatus(publishedStatus...)
+	if err != nil {
+		log.Printf("ActivityModel.GetActivityListByStatus, err : %v", err)
+		return nil, err
+	}
+
+	secProductInfoList := make([]*model.SecProductInfoConf, 0, len(activityList))
+	for _, v := range activityList {
+		secProductInfoList = append(secProductInfoList, toSecProductInfo(v))
+	}
+	return secProductInfoList, nil
+}
+
+// updateProductConf 以乐观锁的方式修改 Zookeeper 中的商品配置
+// 读取节点数据及其版本号，修改后带版本号写回；若期间被其他管理端修改则重新读取再试，
+// 避免并发的"读-改-写"互相覆盖
+func updateProductConf(modify func([]*model.SecProductInfoConf) []*model.SecProductInfoConf) error {
+	conn := conf.Zk.ZkConn
+	zkPath := conf.Zk.SecProductKey
+	if conn == nil {
+		return errors.New("zookeeper is not connected")
+	}
+
+	for i := 0; i < casMaxAttempts; i++ {
+		v, stat, err := conn.Get(zkPath)
+		if err != nil && err != zk.ErrNoNode {
+			log.Printf("get [%s] from zk failed, err : %v", zkPath, err)
+			return err
+		}
+
+		var secProductInfoList []*model.SecProductInfoConf
+		if err == nil && len(v) > 0 {
+			if err = json.Unmarshal(v, &secProductInfoList); err != nil {
+				log.Printf("Unmsharl second product info failed, err : %v", err)
+				return err
+			}
+		}
+
+		data, err := json.Marshal(modify(secProductInfoList))
+		if err != nil {
+			log.Printf("json marshal failed, err : %v", err)
+			return err
+		}
+
+		if stat == nil {
+			_, err = conn.Create(zkPath, data, 0, zk.WorldACL(zk.PermAll))
+			if err == zk.ErrNodeExists {
+				continue
+			}
+		} else {
+			_, err = conn.Set(zkPath, data, stat.Version)
+			if err == zk.ErrBadVersion {
+				continue
+			}
+		}
+		if err != nil {
+			log.Printf("put to zk failed, err : %v", err)
+			return err
+		}
+
+		log.Printf("put to zk success, data = [%v]", string(data))
+		return nil
+	}
+	return ErrZkVersionConflict
+}
+
+func toSecProductInfo(activity *model.Activity) *model.SecProductInfoConf {
+	var secProductInfo = &model.SecProductInfoConf{}
+	secProductInfo.ActivityId = activity.ActivityId
+	secProductInfo.EndTime = activity.EndTime
+	secProductInfo.OnePersonBuyLimit = activity.BuyLimit
+	secProductInfo.ProductId = activity.ProductId
+	secProductInfo.SoldMaxLimit = activity.Speed
+	secProductInfo.StartTime = activity.StartTime
+	secProductInfo.Status = productStatus(activity.Status)
+	secProductInfo.Total = activity.Total
+	secProductInfo.BuyRate = activity.BuyRate
+	return secProductInfo
+}
+
+// publishedStatus 需要出现在 Zookeeper 商品配置中的活动状态
+// 同一商品可能同时发布多个活动, sk-app/sk-core 按时间选择其中生效的一个, 见 conf.NewSecProductInfoMap
+var publishedStatus = []int{model.ActivityStatusNormal, model.ActivityStatusRunning, model.ActivityStatusSoldOut}
+
+func isPublished(status int) bool {
+	for _, v := range publishedStatus {
+		if v == status {
+			return true
+		}
+	}
+	return false
+}
+
+// productStatus 将活动状态转换为 sk-app/sk-core 识别的商品状态
+func productStatus(activityStatus int) int {
+	if activityStatus == model.ActivityStatusSoldOut {
+		return conf.ProductStatusSaleOut
+	}
+	return conf.ProductStatusNormal
+}
+
+// removeSecProductInfo 去掉列表中属于该活动的配置
+func removeSecProductInfo(secProductInfoList []*model.SecProductInfoConf, activityId int) []*model.SecProductInfoConf {
+	result := make([]*model.SecProductInfoConf, 0, len(secProductInfoList))
+	for _, v := range secProductInfoList {
+		if v.ActivityId == activityId {
+			continue
+		}
+		result = append(result, v)
+	}
+	return result
+}
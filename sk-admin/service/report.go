@@ -0,0 +1,66 @@
+package service
+
+import (
+	"log"
+
+	"github.com/lixichongAAA/seckill/sk-admin/model"
+)
+
+// ReportService 活动结束后的统计报表, 数据来自 sk-core 写入的秒杀结果表
+type ReportService interface {
+	// GetWinnerReport 活动的中奖记录
+	GetWinnerReport(activityId int) ([]*model.SecResult, error)
+	// GetThroughputReport 活动每分钟处理的请求数
+	GetThroughputReport(activityId int) ([]*model.ThroughputPoint, error)
+	// GetRejectionReport 活动按错误码统计的拒绝数
+	GetRejectionReport(activityId int) ([]*model.RejectionCount, error)
+}
+
+type ReportServiceMiddleware func(ReportService) ReportService
+
+type ReportServiceImpl struct {
+}
+
+func (p ReportServiceImpl) GetWinnerReport(activityId int) ([]*model.SecResult, error) {
+	if err := p.checkActivity(activityId); err != nil {
+		return nil, err
+	}
+	winnerList, err := model.NewResultModel().GetWinnerList(activityId)
+	if err != nil {
+		log.Printf("ResultModel.GetWinnerList, err : %v", err)
+		return nil, err
+	}
+	return winnerList, nil
+}
+
+func (p ReportServiceImpl) GetThroughputReport(activityId int) ([]*model.ThroughputPoint, error) {
+	if err := p.checkActivity(activityId); err != nil {
+		return nil, err
+	}
+	points, err := model.NewResultModel().GetThroughput(activityId)
+	if err != nil {
+		log.Printf("ResultModel.GetThroughput, err : %v", err)
+		return nil, err
+	}
+	return points, nil
+}
+
+func (p ReportServiceImpl) GetRejectionReport(activityId int) ([]*model.RejectionCount, error) {
+	if err := p.checkActivity(activityId); err != nil {
+		return nil, err
+	}
+	rejections, err := model.NewResultModel().GetRejections(activityId)
+	if err != nil {
+		log.Printf("ResultModel.GetRejections, err : %v", err)
+		return nil, err
+	}
+	return rejections, nil
+}
+
+func (p ReportServiceImpl) checkActivity(activityId int) error {
+	if _, err := model.NewActivityModel().GetActivityById(activityId); err != nil {
+		log.Printf("ActivityModel.GetActivityById, err : %v", err)
+		return err
+	}
+	return nil
+}
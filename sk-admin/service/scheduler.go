@@ -0,0 +1,149 @@
+package service
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/gohouse/gorose/v2"
+	conf "github.com/lixichongAAA/seckill/pkg/config"
+	"github.com/lixichongAAA/seckill/pkg/mysql"
+	"github.com/lixichongAAA/seckill/sk-admin/model"
+)
+
+const scheduleInterval = time.Second //活动状态检查间隔
+
+// DefaultScheduler sk-admin 进程内唯一的活动调度器
+var DefaultScheduler = NewActivityScheduler()
+
+// ActivityScheduler 根据活动时间和售罄情况推进活动状态
+// 正常 -> 进行中(到达开始时间) -> 已售罄(sk-core 上报) / 已结束(到达结束时间)
+// 状态变更与发布事件在同一事务中写入 Mysql, 再由 DefaultPublisher 同步到 Zookeeper
+type ActivityScheduler struct {
+	interval time.Duration
+}
+
+func NewActivityScheduler() *ActivityScheduler {
+	return &ActivityScheduler{
+		interval: scheduleInterval,
+	}
+}
+
+// Run 定时检查活动状态，由 setup 在独立协程中启动
+func (s *ActivityScheduler) Run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.schedule(time.Now().Unix())
+		<-ticker.C
+	}
+}
+
+func (s *ActivityScheduler) schedule(now int64) {
+	activityList, err := model.NewActivityModel().GetActivityListByStatus(publishedStatus...)
+	if err != nil {
+		log.Printf("ActivityModel.GetActivityListByStatus, err : %v", err)
+		return
+	}
+	soldOut := s.loadSoldOut()
+
+	changed := false
+	for _, activity := range activityList {
+		prev := activity.Status
+		next := nextActivityStatus(activity, now, soldOut[activity.ActivityId])
+		if next == prev {
+			continue
+		}
+		ok, err := s.transit(activity, next)
+		if err != nil {
+			log.Printf("transit activity %d from %d to %d failed, err : %v", activity.ActivityId, prev, next, err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		log.Printf("activity %d status changed from %d to %d", activity.ActivityId, prev, next)
+		changed = true
+		if next == model.ActivityStatusExpire && soldOut[activity.ActivityId] {
+			clearSoldOut(activity.ActivityId)
+		}
+	}
+
+	if changed {
+		DefaultPublisher.Notify()
+	}
+}
+
+// transit 修改活动状态并写入发布事件，活动已被管理员修改过状态时返回 false
+func (s *ActivityScheduler) transit(activity *model.Activity, next int) (bool, error) {
+	var ok bool
+	err := mysql.DB().Transaction(func(db gorose.IOrm) error {
+		var err error
+		ok, err = model.NewActivityModelWithTx(db).TransitActivityStatus(activity.ActivityId, activity.Status, next)
+		if err != nil || !ok {
+			return err
+		}
+		activity.Status = next
+		if !isPublished(next) {
+			return enqueueRemove(db, activity.ActivityId)
+		}
+		return enqueuePublish(db, activity)
+	})
+	return ok, err
+}
+
+// loadSoldOut 读取 sk-core 上报的售罄活动
+func (s *ActivityScheduler) loadSoldOut() map[int]bool {
+	result := make(map[int]bool)
+	conn := conf.Redis.RedisConn
+	if conn == nil {
+		return result
+	}
+	data, err := conn.HKeys(conf.Redis.SoldOutHashName()).Result()
+	if err != nil {
+		log.Printf("load sold out activities failed, err : %v", err)
+		return result
+	}
+	for _, v := range data {
+		activityId, err := strconv.Atoi(v)
+		if err != nil {
+			continue
+		}
+		result[activityId] = true
+	}
+	return result
+}
+
+// clearSoldOut 删除 sk-core 上报的售罄记录, 活动修改、重新启用或商品补充库存后调用,
+// 否则下次调度时活动会被重新标记为已售罄
+func clearSoldOut(activityIds ...int) {
+	conn := conf.Redis.RedisConn
+	if conn == nil || len(activityIds) == 0 {
+		return
+	}
+	fields := make([]string, 0, len(activityIds))
+	for _, activityId := range activityIds {
+		fields = append(fields, strconv.Itoa(activityId))
+	}
+	if err := conn.HDel(conf.Redis.SoldOutHashName(), fields...).Err(); err != nil {
+		log.Printf("clear sold out of activity %v failed, err : %v", activityIds, err)
+	}
+}
+
+// nextActivityStatus 计算活动在 now 时刻应处的状态
+// 售罄记录被清除(活动修改、重新启用或补充库存)后, 已售罄的活动恢复为进行中
+func nextActivityStatus(activity *model.Activity, now int64, soldOut bool) int {
+	if !isPublished(activity.Status) {
+		return activity.Status
+	}
+	if now > activity.EndTime {
+		return model.ActivityStatusExpire
+	}
+	if now < activity.StartTime {
+		return activity.Status
+	}
+	if soldOut {
+		return model.ActivityStatusSoldOut
+	}
+	return model.ActivityStatusRunning
+}
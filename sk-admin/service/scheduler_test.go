@@ -0,0 +1,41 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/lixichongAAA/seckill/sk-admin/model"
+)
+
+func TestNextActivityStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		now     int64
+		soldOut bool
+		want    int
+	}{
+		{"before start", model.ActivityStatusNormal, 500, false, model.ActivityStatusNormal},
+		{"before start sold out", model.ActivityStatusNormal, 500, true, model.ActivityStatusNormal},
+		{"at start", model.ActivityStatusNormal, 1000, false, model.ActivityStatusRunning},
+		{"running", model.ActivityStatusRunning, 1500, false, model.ActivityStatusRunning},
+		{"at end", model.ActivityStatusRunning, 2000, false, model.ActivityStatusRunning},
+		{"sold out", model.ActivityStatusRunning, 1500, true, model.ActivityStatusSoldOut},
+		{"started sold out", model.ActivityStatusNormal, 1500, true, model.ActivityStatusSoldOut},
+		{"still sold out", model.ActivityStatusSoldOut, 1500, true, model.ActivityStatusSoldOut},
+		{"sold out cleared", model.ActivityStatusSoldOut, 1500, false, model.ActivityStatusRunning},
+		{"expired", model.ActivityStatusRunning, 2001, false, model.ActivityStatusExpire},
+		{"expired before running", model.ActivityStatusNormal, 2001, false, model.ActivityStatusExpire},
+		{"expired sold out", model.ActivityStatusSoldOut, 2001, true, model.ActivityStatusExpire},
+		{"already expired", model.ActivityStatusExpire, 1500, false, model.ActivityStatusExpire},
+		{"disabled", model.ActivityStatusDisable, 1500, false, model.ActivityStatusDisable},
+		{"disabled sold out", model.ActivityStatusDisable, 1500, true, model.ActivityStatusDisable},
+		{"disabled after end", model.ActivityStatusDisable, 2001, false, model.ActivityStatusDisable},
+	}
+	for _, test := range tests {
+		activity := newActivity(1, 1000, 2000, 10)
+		activity.Status = test.status
+		if got := nextActivityStatus(activity, test.now, test.soldOut); got != test.want {
+			t.Errorf("%s: got %d, want %d", test.name, got, test.want)
+		}
+	}
+}
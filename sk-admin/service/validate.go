@@ -0,0 +1,117 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lixichongAAA/seckill/sk-admin/model"
+)
+
+// FieldError 描述某个字段未通过校验的原因
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError 活动定义校验失败时返回，包含全部未通过校验的字段
+// transport 层据此返回 400 以及字段级别的错误信息
+type ValidationError struct {
+	Errors []FieldError `json:"errors"`
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, v := range e.Errors {
+		msgs = append(msgs, v.Field+": "+v.Message)
+	}
+	return "invalid activity, " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) add(field, format string, args ...interface{}) {
+	e.Errors = append(e.Errors, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// ValidateActivity 在活动写入 Mysql 和 Zookeeper 之前校验活动定义
+// 依次检查字段取值范围、商品是否存在、活动数量是否超过商品总数，以及同一商品下是否存在时间重叠的活动
+func ValidateActivity(activity *model.Activity) error {
+	var product *model.Product
+	var activityList []*model.Activity
+	if activity.ProductId > 0 {
+		var err error
+		product, err = model.NewProductModel().GetProductById(activity.ProductId)
+		if err == model.ErrProductNotFound {
+			product = nil
+		} else if err != nil {
+			return err
+		}
+		if product != nil {
+			if activityList, err = model.NewActivityModel().GetActivityListByProductId(activity.ProductId); err != nil {
+				return err
+			}
+		}
+	}
+
+	if verr := validateActivity(activity, product, activityList); verr != nil {
+		return verr
+	}
+	return nil
+}
+
+// validateActivity 校验规则, product 为活动对应的商品, 不存在时为 nil; activityList 为同一商品下的活动
+func validateActivity(activity *model.Activity, product *model.Product, activityList []*model.Activity) *ValidationError {
+	verr := &ValidationError{}
+
+	if strings.TrimSpace(activity.ActivityName) == "" {
+		verr.add("activity_name", "must not be empty")
+	}
+	if activity.StartTime <= 0 {
+		verr.add("start_time", "must be a positive unix timestamp")
+	}
+	if activity.EndTime <= activity.StartTime {
+		verr.add("end_time", "must be later than start_time")
+	}
+	if activity.Total <= 0 {
+		verr.add("total", "must be greater than 0")
+	}
+	if activity.BuyRate < 0 || activity.BuyRate > 1 {
+		verr.add("buy_rate", "must be between 0 and 1")
+	}
+	if activity.BuyLimit <= 0 {
+		verr.add("buy_limit", "must be greater than 0")
+	}
+	if activity.Speed < 0 {
+		verr.add("speed", "must not be negative")
+	}
+
+	if activity.ProductId <= 0 {
+		verr.add("product_id", "must be specified")
+		return verr
+	}
+	if product == nil {
+		verr.add("product_id", "product %d does not exist", activity.ProductId)
+		return verr
+	}
+	if activity.Total > product.Total {
+		verr.add("total", "must not exceed product total %d", product.Total)
+	}
+
+	// 禁用的活动不会发布, 启用时再检查时间是否重叠
+	if !isPublished(activity.Status) {
+		activityList = nil
+	}
+	for _, v := range activityList {
+		if v.ActivityId == activity.ActivityId || !isPublished(v.Status) {
+			continue
+		}
+		// 两个时间段 [start, end] 相交
+		if activity.StartTime <= v.EndTime && v.StartTime <= activity.EndTime {
+			verr.add("start_time", "overlaps with activity %d of the same product", v.ActivityId)
+			break
+		}
+	}
+
+	if len(verr.Errors) > 0 {
+		return verr
+	}
+	return nil
+}
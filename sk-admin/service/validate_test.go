@@ -0,0 +1,79 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/lixichongAAA/seckill/sk-admin/model"
+)
+
+func newActivity(id int, start, end int64, total int) *model.Activity {
+	return &model.Activity{
+		ActivityId:   id,
+		ActivityName: "activity",
+		ProductId:    1,
+		StartTime:    start,
+		EndTime:      end,
+		Total:        total,
+		BuyLimit:     1,
+		BuyRate:      0.5,
+	}
+}
+
+func disabledActivity(activity *model.Activity) *model.Activity {
+	activity.Status = model.ActivityStatusDisable
+	return activity
+}
+
+func TestValidateActivity(t *testing.T) {
+	product := &model.Product{ProductId: 1, Total: 100}
+	published := newActivity(2, 1000, 2000, 50)
+	disabled := newActivity(3, 3000, 4000, 50)
+	disabled.Status = model.ActivityStatusDisable
+	activityList := []*model.Activity{published, disabled}
+
+	tests := []struct {
+		name     string
+		activity *model.Activity
+		product  *model.Product
+		fields   []string //未通过校验的字段, 为空表示校验通过
+	}{
+		{"valid", newActivity(0, 2500, 2800, 100), product, nil},
+		{"end before start", newActivity(0, 2500, 2400, 10), product, []string{"end_time"}},
+		{"end equals start", newActivity(0, 2500, 2500, 10), product, []string{"end_time"}},
+		{"no start time", newActivity(0, 0, 500, 10), product, []string{"start_time"}},
+		{"total exceeds stock", newActivity(0, 2500, 2800, 101), product, []string{"total"}},
+		{"zero total", newActivity(0, 2500, 2800, 0), product, []string{"total"}},
+		{"product not found", newActivity(0, 2500, 2800, 10), nil, []string{"product_id"}},
+		{"overlaps start", newActivity(0, 500, 1000, 10), product, []string{"start_time"}},
+		{"overlaps end", newActivity(0, 2000, 2500, 10), product, []string{"start_time"}},
+		{"contains published", newActivity(0, 500, 2500, 10), product, []string{"start_time"}},
+		{"inside published", newActivity(0, 1200, 1800, 10), product, []string{"start_time"}},
+		{"overlaps itself", newActivity(2, 1200, 1800, 10), product, nil},
+		{"overlaps disabled", newActivity(0, 3500, 3800, 10), product, nil},
+		{"disabled overlaps published", disabledActivity(newActivity(0, 1200, 1800, 10)), product, nil},
+		{"disabled total exceeds stock", disabledActivity(newActivity(0, 2500, 2800, 101)), product, []string{"total"}},
+		{"several errors", newActivity(0, 2500, 2400, 101), product, []string{"end_time", "total"}},
+	}
+	for _, test := range tests {
+		verr := validateActivity(test.activity, test.product, activityList)
+		if len(test.fields) == 0 {
+			if verr != nil {
+				t.Errorf("%s: unexpected error %v", test.name, verr)
+			}
+			continue
+		}
+		if verr == nil {
+			t.Errorf("%s: expected errors on %v", test.name, test.fields)
+			continue
+		}
+		if len(verr.Errors) != len(test.fields) {
+			t.Errorf("%s: got %v, want errors on %v", test.name, verr, test.fields)
+			continue
+		}
+		for i, field := range test.fields {
+			if verr.Errors[i].Field != field {
+				t.Errorf("%s: error %d is on %s, want %s", test.name, i, verr.Errors[i].Field, field)
+			}
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package setup
+
+import (
+	"github.com/lixichongAAA/seckill/sk-admin/service"
+)
+
+// InitPublisher 启动商品配置发布器，将 outbox 中的活动变更同步到 Zookeeper
+func InitPublisher() {
+	go service.DefaultPublisher.Run()
+}
+
+// InitProductConfHub 启动商品配置分发中心，向 sk-app/sk-core 推送商品配置变更
+func InitProductConfHub() {
+	go service.DefaultProductConfHub.Run()
+}
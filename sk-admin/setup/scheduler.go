@@ -0,0 +1,10 @@
+package setup
+
+import (
+	"github.com/lixichongAAA/seckill/sk-admin/service"
+)
+
+// InitScheduler 启动活动调度器，按时间和售罄情况自动推进活动状态
+func InitScheduler() {
+	go service.DefaultScheduler.Run()
+}
@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -13,6 +14,9 @@ import (
 
 	kitprometheus "github.com/go-kit/kit/metrics/prometheus"
 	kitzipkin "github.com/go-kit/kit/tracing/zipkin"
+	"github.com/lixichongAAA/seckill/pb"
+	"github.com/lixichongAAA/seckill/pkg/bootstrap"
+	"github.com/lixichongAAA/seckill/pkg/client"
 	register "github.com/lixichongAAA/seckill/pkg/discover"
 	"github.com/lixichongAAA/seckill/sk-admin/endpoint"
 	"github.com/lixichongAAA/seckill/sk-admin/plugins"
@@ -20,7 +24,9 @@ import (
 	"github.com/lixichongAAA/seckill/sk-admin/transport"
 	"github.com/lixichongAAA/seckill/user-service/config"
 	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/viper"
 	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
 )
 
 // 初始化Http服务
@@ -48,15 +54,25 @@ func InitServer(host string, servicePort string) {
 		Help:      "Total duration of requests in microseconds.",
 	}, fieldKeys)
 	ratebucket := rate.NewLimiter(rate.Every(time.Second*1), 100)
+	// 只读的 gRPC 接口允许这些客户端(其他服务)的令牌调用, 无需 OPERATOR 角色
+	trustedClients := viper.GetStringSlice("auth.trustedClients")
 
 	var (
-		activityService service.ActivityService
-		productService  service.ProductService
-		skAdminService  service.Service
+		activityService  service.ActivityService
+		productService   service.ProductService
+		dashboardService service.DashboardService
+		reportService    service.ReportService
+		blacklistService service.BlacklistService
+		auditService     service.AuditService
+		skAdminService   service.Service
 	)
 	skAdminService = service.SkAdminService{}
 	activityService = service.ActivityServiceImpl{}
 	productService = service.ProductServiceImpl{}
+	dashboardService = service.DashboardServiceImpl{}
+	reportService = service.ReportServiceImpl{}
+	blacklistService = service.BlacklistServiceImpl{}
+	auditService = service.AuditServiceImpl{}
 
 	// add logging middleware
 	skAdminService = plugins.SkAdminLoggingMiddleware(config.Logger)(skAdminService)
@@ -68,36 +84,201 @@ func InitServer(host string, servicePort string) {
 	productService = plugins.ProductLoggingMiddleware(config.Logger)(productService)
 	productService = plugins.ProductMetrics(requestCount, requestLatency)(productService)
 
+	dashboardService = plugins.DashboardLoggingMiddleware(config.Logger)(dashboardService)
+	dashboardService = plugins.DashboardMetrics(requestCount, requestLatency)(dashboardService)
+
+	reportService = plugins.ReportLoggingMiddleware(config.Logger)(reportService)
+	reportService = plugins.ReportMetrics(requestCount, requestLatency)(reportService)
+
+	blacklistService = plugins.BlacklistLoggingMiddleware(config.Logger)(blacklistService)
+	blacklistService = plugins.BlacklistMetrics(requestCount, requestLatency)(blacklistService)
+
+	auditService = plugins.AuditLoggingMiddleware(config.Logger)(auditService)
+	auditService = plugins.AuditMetrics(requestCount, requestLatency)(auditService)
+
 	createActivityEnd := endpoint.MakeCreateActivityEndpoint(activityService)
+	createActivityEnd = endpoint.MakeAuditMiddleware(auditService, service.AuditResourceActivity, "create")(createActivityEnd)
+	createActivityEnd = endpoint.MakeAuthorityAuthorizationdMiddleware(endpoint.RoleOperator, config.Logger)(createActivityEnd)
 	createActivityEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(createActivityEnd)
 	createActivityEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "create-activity")(createActivityEnd)
 
+	updateActivityEnd := endpoint.MakeUpdateActivityEndpoint(activityService)
+	updateActivityEnd = endpoint.MakeAuditMiddleware(auditService, service.AuditResourceActivity, "update")(updateActivityEnd)
+	updateActivityEnd = endpoint.MakeAuthorityAuthorizationdMiddleware(endpoint.RoleOperator, config.Logger)(updateActivityEnd)
+	updateActivityEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(updateActivityEnd)
+	updateActivityEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "update-activity")(updateActivityEnd)
+
+	disableActivityEnd := endpoint.MakeDisableActivityEndpoint(activityService)
+	disableActivityEnd = endpoint.MakeAuditMiddleware(auditService, service.AuditResourceActivity, "disable")(disableActivityEnd)
+	disableActivityEnd = endpoint.MakeAuthorityAuthorizationdMiddleware(endpoint.RoleOperator, config.Logger)(disableActivityEnd)
+	disableActivityEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(disableActivityEnd)
+	disableActivityEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "disable-activity")(disableActivityEnd)
+
+	enableActivityEnd := endpoint.MakeEnableActivityEndpoint(activityService)
+	enableActivityEnd = endpoint.MakeAuditMiddleware(auditService, service.AuditResourceActivity, "enable")(enableActivityEnd)
+	enableActivityEnd = endpoint.MakeAuthorityAuthorizationdMiddleware(endpoint.RoleOperator, config.Logger)(enableActivityEnd)
+	enableActivityEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(enableActivityEnd)
+	enableActivityEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "enable-activity")(enableActivityEnd)
+
+	deleteActivityEnd := endpoint.MakeDeleteActivityEndpoint(activityService)
+	deleteActivityEnd = endpoint.MakeAuditMiddleware(auditService, service.AuditResourceActivity, "delete")(deleteActivityEnd)
+	deleteActivityEnd = endpoint.MakeAuthorityAuthorizationdMiddleware(endpoint.RoleAdmin, config.Logger)(deleteActivityEnd)
+	deleteActivityEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(deleteActivityEnd)
+	deleteActivityEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "delete-activity")(deleteActivityEnd)
+
+	cloneActivityEnd := endpoint.MakeCloneActivityEndpoint(activityService)
+	cloneActivityEnd = endpoint.MakeAuditMiddleware(auditService, service.AuditResourceActivity, "clone")(cloneActivityEnd)
+	cloneActivityEnd = endpoint.MakeAuthorityAuthorizationdMiddleware(endpoint.RoleOperator, config.Logger)(cloneActivityEnd)
+	cloneActivityEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(cloneActivityEnd)
+	cloneActivityEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "clone-activity")(cloneActivityEnd)
+
 	GetActivityEnd := endpoint.MakeGetActivityEndpoint(activityService)
+	GetActivityEnd = endpoint.MakeAuthorityAuthorizationdMiddleware(endpoint.RoleOperator, config.Logger)(GetActivityEnd)
 	GetActivityEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(GetActivityEnd)
 	GetActivityEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "get-activity")(GetActivityEnd)
 
+	getActivityByIdEnd := endpoint.MakeGetActivityByIdEndpoint(activityService)
+	getActivityByIdEnd = endpoint.MakeServiceAuthorizationMiddleware(endpoint.RoleOperator, trustedClients, config.Logger)(getActivityByIdEnd)
+	getActivityByIdEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(getActivityByIdEnd)
+	getActivityByIdEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "get-activity-by-id")(getActivityByIdEnd)
+
+	listActivityEnd := endpoint.MakeListActivityEndpoint(activityService)
+	listActivityEnd = endpoint.MakeServiceAuthorizationMiddleware(endpoint.RoleOperator, trustedClients, config.Logger)(listActivityEnd)
+	listActivityEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(listActivityEnd)
+	listActivityEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "list-activity")(listActivityEnd)
+
+	getProductConfEnd := endpoint.MakeGetProductConfEndpoint(activityService)
+	getProductConfEnd = endpoint.MakeServiceAuthorizationMiddleware(endpoint.RoleOperator, trustedClients, config.Logger)(getProductConfEnd)
+	getProductConfEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(getProductConfEnd)
+	getProductConfEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "get-product-conf")(getProductConfEnd)
+
 	createProductEnd := endpoint.MakeCreateProductEndpoint(productService)
+	createProductEnd = endpoint.MakeAuditMiddleware(auditService, service.AuditResourceProduct, "create")(createProductEnd)
+	createProductEnd = endpoint.MakeAuthorityAuthorizationdMiddleware(endpoint.RoleOperator, config.Logger)(createProductEnd)
 	createProductEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(createProductEnd)
 	createProductEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "create-product")(createProductEnd)
 
 	GetProductEnd := endpoint.MakeGetProductEndpoint(productService)
+	GetProductEnd = endpoint.MakeAuthorityAuthorizationdMiddleware(endpoint.RoleOperator, config.Logger)(GetProductEnd)
 	GetProductEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(GetProductEnd)
 	GetProductEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "get-product")(GetProductEnd)
 
+	getProductByIdEnd := endpoint.MakeGetProductByIdEndpoint(productService)
+	getProductByIdEnd = endpoint.MakeAuthorityAuthorizationdMiddleware(endpoint.RoleOperator, config.Logger)(getProductByIdEnd)
+	getProductByIdEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(getProductByIdEnd)
+	getProductByIdEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "get-product-by-id")(getProductByIdEnd)
+
+	updateProductEnd := endpoint.MakeUpdateProductEndpoint(productService)
+	updateProductEnd = endpoint.MakeAuditMiddleware(auditService, service.AuditResourceProduct, "update")(updateProductEnd)
+	updateProductEnd = endpoint.MakeAuthorityAuthorizationdMiddleware(endpoint.RoleOperator, config.Logger)(updateProductEnd)
+	updateProductEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(updateProductEnd)
+	updateProductEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "update-product")(updateProductEnd)
+
+	deleteProductEnd := endpoint.MakeDeleteProductEndpoint(productService)
+	deleteProductEnd = endpoint.MakeAuditMiddleware(auditService, service.AuditResourceProduct, "delete")(deleteProductEnd)
+	deleteProductEnd = endpoint.MakeAuthorityAuthorizationdMiddleware(endpoint.RoleAdmin, config.Logger)(deleteProductEnd)
+	deleteProductEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(deleteProductEnd)
+	deleteProductEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "delete-product")(deleteProductEnd)
+
+	adjustStockEnd := endpoint.MakeAdjustStockEndpoint(productService)
+	adjustStockEnd = endpoint.MakeAuditMiddleware(auditService, service.AuditResourceProduct, "adjust_stock")(adjustStockEnd)
+	adjustStockEnd = endpoint.MakeAuthorityAuthorizationdMiddleware(endpoint.RoleAdmin, config.Logger)(adjustStockEnd)
+	adjustStockEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(adjustStockEnd)
+	adjustStockEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "adjust-stock")(adjustStockEnd)
+
+	getStockLogEnd := endpoint.MakeGetStockLogEndpoint(productService)
+	getStockLogEnd = endpoint.MakeAuthorityAuthorizationdMiddleware(endpoint.RoleOperator, config.Logger)(getStockLogEnd)
+	getStockLogEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(getStockLogEnd)
+	getStockLogEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "get-stock-log")(getStockLogEnd)
+
+	getDashboardEnd := endpoint.MakeGetDashboardEndpoint(dashboardService)
+	getDashboardEnd = endpoint.MakeAuthorityAuthorizationdMiddleware(endpoint.RoleOperator, config.Logger)(getDashboardEnd)
+	getDashboardEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(getDashboardEnd)
+	getDashboardEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "get-dashboard")(getDashboardEnd)
+
+	// 实时图表建立连接时的首次查询经过 getDashboardEnd, 之后的定时推送不占用管理接口的限流配额
+	dashboardTickEnd := endpoint.MakeGetDashboardEndpoint(dashboardService)
+	dashboardTickEnd = endpoint.MakeAuthorityAuthorizationdMiddleware(endpoint.RoleOperator, config.Logger)(dashboardTickEnd)
+
+	winnerReportEnd := endpoint.MakeWinnerReportEndpoint(reportService)
+	winnerReportEnd = endpoint.MakeAuthorityAuthorizationdMiddleware(endpoint.RoleOperator, config.Logger)(winnerReportEnd)
+	winnerReportEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(winnerReportEnd)
+	winnerReportEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "winner-report")(winnerReportEnd)
+
+	throughputReportEnd := endpoint.MakeThroughputReportEndpoint(reportService)
+	throughputReportEnd = endpoint.MakeAuthorityAuthorizationdMiddleware(endpoint.RoleOperator, config.Logger)(throughputReportEnd)
+	throughputReportEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(throughputReportEnd)
+	throughputReportEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "throughput-report")(throughputReportEnd)
+
+	rejectionReportEnd := endpoint.MakeRejectionReportEndpoint(reportService)
+	rejectionReportEnd = endpoint.MakeAuthorityAuthorizationdMiddleware(endpoint.RoleOperator, config.Logger)(rejectionReportEnd)
+	rejectionReportEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(rejectionReportEnd)
+	rejectionReportEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "rejection-report")(rejectionReportEnd)
+
+	addBlacklistEnd := endpoint.MakeAddBlacklistEndpoint(blacklistService)
+	addBlacklistEnd = endpoint.MakeAuditMiddleware(auditService, service.AuditResourceBlacklist, "add")(addBlacklistEnd)
+	addBlacklistEnd = endpoint.MakeAuthorityAuthorizationdMiddleware(endpoint.RoleAdmin, config.Logger)(addBlacklistEnd)
+	addBlacklistEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(addBlacklistEnd)
+	addBlacklistEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "add-blacklist")(addBlacklistEnd)
+
+	removeBlacklistEnd := endpoint.MakeRemoveBlacklistEndpoint(blacklistService)
+	removeBlacklistEnd = endpoint.MakeAuditMiddleware(auditService, service.AuditResourceBlacklist, "remove")(removeBlacklistEnd)
+	removeBlacklistEnd = endpoint.MakeAuthorityAuthorizationdMiddleware(endpoint.RoleAdmin, config.Logger)(removeBlacklistEnd)
+	removeBlacklistEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(removeBlacklistEnd)
+	removeBlacklistEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "remove-blacklist")(removeBlacklistEnd)
+
+	getBlacklistEnd := endpoint.MakeGetBlacklistEndpoint(blacklistService)
+	getBlacklistEnd = endpoint.MakeAuthorityAuthorizationdMiddleware(endpoint.RoleAdmin, config.Logger)(getBlacklistEnd)
+	getBlacklistEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(getBlacklistEnd)
+	getBlacklistEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "get-blacklist")(getBlacklistEnd)
+
+	getAuditLogEnd := endpoint.MakeGetAuditLogEndpoint(auditService)
+	getAuditLogEnd = endpoint.MakeAuthorityAuthorizationdMiddleware(endpoint.RoleAdmin, config.Logger)(getAuditLogEnd)
+	getAuditLogEnd = plugins.NewTokenBucketLimitterWithBuildIn(ratebucket)(getAuditLogEnd)
+	getAuditLogEnd = kitzipkin.TraceEndpoint(config.ZipkinTracer, "get-audit-log")(getAuditLogEnd)
+
 	//创建健康检查的Endpoint
 	healthEndpoint := endpoint.MakeHealthCheckEndpoint(skAdminService)
 	healthEndpoint = kitzipkin.TraceEndpoint(config.ZipkinTracer, "health-endpoint")(healthEndpoint)
 
 	endpts := endpoint.SkAdminEndpoints{
-		GetActivityEndpoint:    GetActivityEnd,
-		CreateActivityEndpoint: createActivityEnd,
-		CreateProductEndpoint:  createProductEnd,
-		GetProductEndpoint:     GetProductEnd,
-		HealthCheckEndpoint:    healthEndpoint,
+		GetActivityEndpoint:     GetActivityEnd,
+		GetActivityByIdEndpoint: getActivityByIdEnd,
+		ListActivityEndpoint:    listActivityEnd,
+		GetProductConfEndpoint:  getProductConfEnd,
+		CreateActivityEndpoint:  createActivityEnd,
+		UpdateActivityEndpoint:  updateActivityEnd,
+		DisableActivityEndpoint: disableActivityEnd,
+		EnableActivityEndpoint:  enableActivityEnd,
+		DeleteActivityEndpoint:  deleteActivityEnd,
+		CloneActivityEndpoint:   cloneActivityEnd,
+		CreateProductEndpoint:   createProductEnd,
+		GetProductEndpoint:      GetProductEnd,
+		GetProductByIdEndpoint:  getProductByIdEnd,
+		UpdateProductEndpoint:   updateProductEnd,
+		DeleteProductEndpoint:   deleteProductEnd,
+		AdjustStockEndpoint:     adjustStockEnd,
+		GetStockLogEndpoint:     getStockLogEnd,
+		GetDashboardEndpoint:    getDashboardEnd,
+		DashboardTickEndpoint:   dashboardTickEnd,
+		WinnerReportEndpoint:    winnerReportEnd,
+		ThroughputEndpoint:      throughputReportEnd,
+		RejectionReportEndpoint: rejectionReportEnd,
+		AddBlacklistEndpoint:    addBlacklistEnd,
+		RemoveBlacklistEndpoint: removeBlacklistEnd,
+		GetBlacklistEndpoint:    getBlacklistEnd,
+		GetAuditLogEndpoint:     getAuditLogEnd,
+		HealthCheckEndpoint:     healthEndpoint,
+	}
+	//通过 oauth 服务校验访问令牌, 审计日志据此记录操作人
+	oauthClient, err := client.NewOAuthClient("oauth", nil, nil)
+	if err != nil {
+		log.Printf("new oauth client failed, err : %v", err)
 	}
+
 	ctx := context.Background()
 	//创建http.Handler
-	r := transport.MakeHttpHandler(ctx, endpts, config.ZipkinTracer, config.Logger)
+	r := transport.MakeHttpHandler(ctx, endpts, oauthClient, config.ZipkinTracer, config.Logger)
 
 	//http server
 	go func() {
@@ -107,6 +288,20 @@ func InitServer(host string, servicePort string) {
 		handler := r
 		errChan <- http.ListenAndServe(":"+servicePort, handler)
 	}()
+	//grpc server, 端口通过服务注册的 rpcPort 元数据对外公布
+	go func() {
+		fmt.Println("grpc Server start at port:" + bootstrap.RpcConfig.Port)
+		listener, err := net.Listen("tcp", ":"+bootstrap.RpcConfig.Port)
+		if err != nil {
+			errChan <- err
+			return
+		}
+		serverTracer := kitzipkin.GRPCServerTrace(config.ZipkinTracer, kitzipkin.Name("grpc-transport"))
+		handler := transport.NewGRPCServer(ctx, endpts, service.DefaultProductConfHub, oauthClient, serverTracer, config.Logger)
+		gRPCServer := grpc.NewServer()
+		pb.RegisterActivityServiceServer(gRPCServer, handler)
+		errChan <- gRPCServer.Serve(listener)
+	}()
 
 	go func() {
 		c := make(chan os.Signal, 1)
@@ -0,0 +1,139 @@
+package transport
+
+import (
+	"context"
+
+	"github.com/lixichongAAA/seckill/pb"
+	endpts "github.com/lixichongAAA/seckill/sk-admin/endpoint"
+	"github.com/lixichongAAA/seckill/sk-admin/model"
+	"github.com/lixichongAAA/seckill/sk-admin/service"
+)
+
+func DecodeGRPCListActivitiesRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.ListActivitiesRequest)
+	status := make([]int, 0, len(req.Status))
+	for _, v := range req.Status {
+		status = append(status, int(v))
+	}
+	return endpts.ActivityListRequest{Status: status}, nil
+}
+
+func EncodeGRPCListActivitiesResponse(_ context.Context, r interface{}) (interface{}, error) {
+	resp := r.(endpts.ActivityListResponse)
+	activities := make([]*pb.Activity, 0, len(resp.Result))
+	for _, v := range resp.Result {
+		activities = append(activities, toPbActivity(v))
+	}
+	return &pb.ListActivitiesResponse{
+		Activities: activities,
+		Err:        errString(resp.Error),
+	}, nil
+}
+
+func DecodeGRPCGetActivityRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.GetActivityRequest)
+	if req.ActivityId <= 0 {
+		return nil, ErrorBadRequest
+	}
+	return endpts.ActivityIdRequest{ActivityId: int(req.ActivityId)}, nil
+}
+
+func DecodeGRPCActivityRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.Activity)
+	return model.Activity{
+		ActivityId:   int(req.ActivityId),
+		ActivityName: req.ActivityName,
+		ProductId:    int(req.ProductId),
+		StartTime:    req.StartTime,
+		EndTime:      req.EndTime,
+		Total:        int(req.Total),
+		Status:       int(req.Status),
+		Speed:        int(req.Speed),
+		BuyLimit:     int(req.BuyLimit),
+		BuyRate:      req.BuyRate,
+	}, nil
+}
+
+func EncodeGRPCActivityResponse(_ context.Context, r interface{}) (interface{}, error) {
+	resp := r.(endpts.ActivityResponse)
+	result := &pb.ActivityResponse{
+		Err: errString(resp.Error),
+	}
+	if resp.Result != nil {
+		result.Activity = toPbActivity(resp.Result)
+	}
+	return result, nil
+}
+
+func DecodeGRPCListProductConfigsRequest(_ context.Context, r interface{}) (interface{}, error) {
+	return endpts.GetListRequest{}, nil
+}
+
+func EncodeGRPCListProductConfigsResponse(_ context.Context, r interface{}) (interface{}, error) {
+	resp := r.(endpts.ProductConfListResponse)
+	configs := make([]*pb.SecProductInfoConf, 0, len(resp.Result))
+	for _, v := range resp.Result {
+		configs = append(configs, toPbSecProductInfoConf(v))
+	}
+	return &pb.ListProductConfigsResponse{
+		Configs: configs,
+		Err:     errString(resp.Error),
+	}, nil
+}
+
+func toPbProductConfigEvent(event *service.ProductConfEvent) *pb.ProductConfigEvent {
+	configs := make([]*pb.SecProductInfoConf, 0, len(event.Configs))
+	for _, v := range event.Configs {
+		configs = append(configs, toPbSecProductInfoConf(v))
+	}
+	removed := make([]int64, 0, len(event.Removed))
+	for _, v := range event.Removed {
+		removed = append(removed, int64(v))
+	}
+	return &pb.ProductConfigEvent{
+		Version: event.Version,
+		Full:    event.Full,
+		Configs: configs,
+		Removed: removed,
+	}
+}
+
+func toPbActivity(activity *model.Activity) *pb.Activity {
+	return &pb.Activity{
+		ActivityId:   int64(activity.ActivityId),
+		ActivityName: activity.ActivityName,
+		ProductId:    int64(activity.ProductId),
+		StartTime:    activity.StartTime,
+		EndTime:      activity.EndTime,
+		Total:        int64(activity.Total),
+		Status:       int64(activity.Status),
+		StartTimeStr: activity.StartTimeStr,
+		EndTimeStr:   activity.EndTimeStr,
+		StatusStr:    activity.StatusStr,
+		Speed:        int64(activity.Speed),
+		BuyLimit:     int64(activity.BuyLimit),
+		BuyRate:      activity.BuyRate,
+	}
+}
+
+func toPbSecProductInfoConf(conf *model.SecProductInfoConf) *pb.SecProductInfoConf {
+	return &pb.SecProductInfoConf{
+		ActivityId:        int64(conf.ActivityId),
+		ProductId:         int64(conf.ProductId),
+		StartTime:         conf.StartTime,
+		EndTime:           conf.EndTime,
+		Status:            int64(conf.Status),
+		Total:             int64(conf.Total),
+		Left:              int64(conf.Left),
+		OnePersonBuyLimit: int64(conf.OnePersonBuyLimit),
+		BuyRate:           conf.BuyRate,
+		SoldMaxLimit:      int64(conf.SoldMaxLimit),
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,164 @@
+package transport
+
+import (
+	"context"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/transport/grpc"
+	"github.com/lixichongAAA/seckill/pb"
+	"github.com/lixichongAAA/seckill/pkg/client"
+	endpts "github.com/lixichongAAA/seckill/sk-admin/endpoint"
+	"github.com/lixichongAAA/seckill/sk-admin/model"
+	"github.com/lixichongAAA/seckill/sk-admin/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type grpcServer struct {
+	listActivities     grpc.Handler
+	getActivity        grpc.Handler
+	createActivity     grpc.Handler
+	updateActivity     grpc.Handler
+	listProductConfigs grpc.Handler
+	hub                *service.ProductConfHub
+}
+
+func (s *grpcServer) ListActivities(ctx context.Context, r *pb.ListActivitiesRequest) (*pb.ListActivitiesResponse, error) {
+	_, resp, err := s.listActivities.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return resp.(*pb.ListActivitiesResponse), nil
+}
+
+func (s *grpcServer) GetActivity(ctx context.Context, r *pb.GetActivityRequest) (*pb.ActivityResponse, error) {
+	_, resp, err := s.getActivity.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return resp.(*pb.ActivityResponse), nil
+}
+
+func (s *grpcServer) CreateActivity(ctx context.Context, r *pb.Activity) (*pb.ActivityResponse, error) {
+	_, resp, err := s.createActivity.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return resp.(*pb.ActivityResponse), nil
+}
+
+func (s *grpcServer) UpdateActivity(ctx context.Context, r *pb.Activity) (*pb.ActivityResponse, error) {
+	_, resp, err := s.updateActivity.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return resp.(*pb.ActivityResponse), nil
+}
+
+func (s *grpcServer) ListProductConfigs(ctx context.Context, r *pb.ListProductConfigsRequest) (*pb.ListProductConfigsResponse, error) {
+	_, resp, err := s.listProductConfigs.ServeGRPC(ctx, r)
+	if err != nil {
+		return nil, toGRPCError(err)
+	}
+	return resp.(*pb.ListProductConfigsResponse), nil
+}
+
+// WatchProductConfig 先推送全量商品配置, 之后推送增量变更, 直到客户端断开
+// Go-kit 不支持流式接口, 因此直接订阅分发中心; 商品配置与 sk-app 的 /sec/list 一样是公开信息, 不校验令牌
+// 推送跟不上变更时返回 ResourceExhausted, 客户端需重新订阅
+func (s *grpcServer) WatchProductConfig(r *pb.WatchProductConfigRequest, stream pb.ActivityService_WatchProductConfigServer) error {
+	sub, snapshot, err := s.hub.Subscribe()
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	defer s.hub.Unsubscribe(sub)
+
+	if err = stream.Send(toPbProductConfigEvent(snapshot)); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case event, ok := <-sub.Events():
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "product config subscriber lagged behind")
+			}
+			if err = stream.Send(toPbProductConfigEvent(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// NewGRPCServer 与 http 接口共用 Endpoint, 访问令牌通过 authorization 元数据传递
+func NewGRPCServer(ctx context.Context, endpoints endpts.SkAdminEndpoints, hub *service.ProductConfHub, oauthClient client.OAuthClient, serverTracer grpc.ServerOption, logger log.Logger) pb.ActivityServiceServer {
+	options := []grpc.ServerOption{
+		grpc.ServerBefore(makeGRPCAuthorizationContext(oauthClient, logger)),
+		serverTracer,
+	}
+
+	return &grpcServer{
+		listActivities: grpc.NewServer(
+			endpoints.ListActivityEndpoint,
+			DecodeGRPCListActivitiesRequest,
+			EncodeGRPCListActivitiesResponse,
+			options...,
+		),
+		getActivity: grpc.NewServer(
+			endpoints.GetActivityByIdEndpoint,
+			DecodeGRPCGetActivityRequest,
+			EncodeGRPCActivityResponse,
+			options...,
+		),
+		createActivity: grpc.NewServer(
+			endpoints.CreateActivityEndpoint,
+			DecodeGRPCActivityRequest,
+			EncodeGRPCActivityResponse,
+			options...,
+		),
+		updateActivity: grpc.NewServer(
+			endpoints.UpdateActivityEndpoint,
+			DecodeGRPCActivityRequest,
+			EncodeGRPCActivityResponse,
+			options...,
+		),
+		listProductConfigs: grpc.NewServer(
+			endpoints.GetProductConfEndpoint,
+			DecodeGRPCListProductConfigsRequest,
+			EncodeGRPCListProductConfigsResponse,
+			options...,
+		),
+		hub: hub,
+	}
+}
+
+func makeGRPCAuthorizationContext(oauthClient client.OAuthClient, logger log.Logger) grpc.ServerRequestFunc {
+
+	return func(ctx context.Context, md metadata.MD) context.Context {
+		var authorization string
+		if values := md.Get("authorization"); len(values) > 0 {
+			authorization = values[0]
+		}
+		return authorizationContext(ctx, oauthClient, logger, authorization)
+	}
+}
+
+// toGRPCError 将 Endpoint 返回的错误转换为对应的 gRPC 状态码
+func toGRPCError(err error) error {
+	if verr, ok := err.(*service.ValidationError); ok {
+		return status.Error(codes.InvalidArgument, verr.Error())
+	}
+	switch err {
+	case ErrorBadRequest:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case ErrorTokenRequired, ErrorInvalidToken, endpts.ErrInvalidUserRequest:
+		return status.Error(codes.Unauthenticated, err.Error())
+	case endpts.ErrNotPermit:
+		return status.Error(codes.PermissionDenied, err.Error())
+	case model.ErrActivityNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return err
+}
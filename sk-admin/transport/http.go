@@ -6,6 +6,8 @@ import (
 	"errors"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/tracing/zipkin"
@@ -13,22 +15,29 @@ import (
 	kithttp "github.com/go-kit/kit/transport/http"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
+	"github.com/lixichongAAA/seckill/pb"
+	"github.com/lixichongAAA/seckill/pkg/client"
 	endpts "github.com/lixichongAAA/seckill/sk-admin/endpoint"
 	"github.com/lixichongAAA/seckill/sk-admin/model"
+	"github.com/lixichongAAA/seckill/sk-admin/service"
 	gozipkin "github.com/openzipkin/zipkin-go"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 var (
-	ErrorBadRequest = errors.New("invalid request parameter")
+	ErrorBadRequest    = errors.New("invalid request parameter")
+	ErrorTokenRequired = errors.New("authorization token required")
+	ErrorInvalidToken  = errors.New("invalid authorization token")
 )
 
 // MakeHttpHandler make http handler use mux
-func MakeHttpHandler(ctx context.Context, endpoints endpts.SkAdminEndpoints, zipkinTracer *gozipkin.Tracer, logger log.Logger) http.Handler {
+func MakeHttpHandler(ctx context.Context, endpoints endpts.SkAdminEndpoints, oauthClient client.OAuthClient, zipkinTracer *gozipkin.Tracer, logger log.Logger) http.Handler {
 	r := mux.NewRouter()
 	zipkinServer := zipkin.HTTPServerTrace(zipkinTracer, zipkin.Name("http-transport"))
 
+	authorizationContext := makeOAuth2AuthorizationContext(oauthClient, logger)
 	options := []kithttp.ServerOption{
+		kithttp.ServerBefore(authorizationContext),
 		//kithttp.ServerErrorLogger(logger),
 		kithttp.ServerErrorHandler(transport.NewLogErrorHandler(logger)),
 		//kithttp.ServerErrorEncoder(kithttp.DefaultErrorEncoder),
@@ -38,7 +47,42 @@ func MakeHttpHandler(ctx context.Context, endpoints endpts.SkAdminEndpoints, zip
 
 	r.Methods("GET").Path("/product/list").Handler(kithttp.NewServer(
 		endpoints.GetProductEndpoint,
-		decodeGetListRequest,
+		decodeProductQueryRequest,
+		encodeResponse,
+		options...,
+	))
+
+	r.Methods("GET").Path("/product/get").Handler(kithttp.NewServer(
+		endpoints.GetProductByIdEndpoint,
+		decodeProductIdQueryRequest,
+		encodeResponse,
+		options...,
+	))
+
+	r.Methods("POST").Path("/product/update").Handler(kithttp.NewServer(
+		endpoints.UpdateProductEndpoint,
+		decodeUpdateProductRequest,
+		encodeResponse,
+		options...,
+	))
+
+	r.Methods("POST").Path("/product/delete").Handler(kithttp.NewServer(
+		endpoints.DeleteProductEndpoint,
+		decodeProductIdRequest,
+		encodeResponse,
+		options...,
+	))
+
+	r.Methods("POST").Path("/product/stock/adjust").Handler(kithttp.NewServer(
+		endpoints.AdjustStockEndpoint,
+		decodeAdjustStockRequest,
+		encodeResponse,
+		options...,
+	))
+
+	r.Methods("GET").Path("/product/stock/log").Handler(kithttp.NewServer(
+		endpoints.GetStockLogEndpoint,
+		decodeProductIdQueryRequest,
 		encodeResponse,
 		options...,
 	))
@@ -57,6 +101,41 @@ func MakeHttpHandler(ctx context.Context, endpoints endpts.SkAdminEndpoints, zip
 		options...,
 	))
 
+	r.Methods("POST").Path("/activity/update").Handler(kithttp.NewServer(
+		endpoints.UpdateActivityEndpoint,
+		decodeCreateActivityCheckRequest,
+		encodeResponse,
+		options...,
+	))
+
+	r.Methods("POST").Path("/activity/disable").Handler(kithttp.NewServer(
+		endpoints.DisableActivityEndpoint,
+		decodeActivityIdRequest,
+		encodeResponse,
+		options...,
+	))
+
+	r.Methods("POST").Path("/activity/enable").Handler(kithttp.NewServer(
+		endpoints.EnableActivityEndpoint,
+		decodeActivityIdRequest,
+		encodeResponse,
+		options...,
+	))
+
+	r.Methods("POST").Path("/activity/delete").Handler(kithttp.NewServer(
+		endpoints.DeleteActivityEndpoint,
+		decodeActivityIdRequest,
+		encodeResponse,
+		options...,
+	))
+
+	r.Methods("POST").Path("/activity/clone").Handler(kithttp.NewServer(
+		endpoints.CloneActivityEndpoint,
+		decodeActivityIdRequest,
+		encodeResponse,
+		options...,
+	))
+
 	r.Methods("GET").Path("/activity/list").Handler(kithttp.NewServer(
 		endpoints.GetActivityEndpoint,
 		decodeGetListRequest,
@@ -64,6 +143,66 @@ func MakeHttpHandler(ctx context.Context, endpoints endpts.SkAdminEndpoints, zip
 		options...,
 	))
 
+	r.Methods("GET").Path("/dashboard").Handler(kithttp.NewServer(
+		endpoints.GetDashboardEndpoint,
+		decodeDashboardRequest,
+		encodeResponse,
+		options...,
+	))
+
+	r.Methods("GET").Path("/dashboard/stream").Handler(makeDashboardStreamHandler(endpoints.GetDashboardEndpoint, endpoints.DashboardTickEndpoint, authorizationContext, logger))
+
+	reportOptions := append([]kithttp.ServerOption{kithttp.ServerBefore(reportFormatToContext)}, options...)
+
+	r.Methods("GET").Path("/report/winners").Handler(kithttp.NewServer(
+		endpoints.WinnerReportEndpoint,
+		decodeReportRequest,
+		encodeReportResponse,
+		reportOptions...,
+	))
+
+	r.Methods("GET").Path("/report/throughput").Handler(kithttp.NewServer(
+		endpoints.ThroughputEndpoint,
+		decodeReportRequest,
+		encodeReportResponse,
+		reportOptions...,
+	))
+
+	r.Methods("GET").Path("/report/rejections").Handler(kithttp.NewServer(
+		endpoints.RejectionReportEndpoint,
+		decodeReportRequest,
+		encodeReportResponse,
+		reportOptions...,
+	))
+
+	r.Methods("POST").Path("/blacklist/add").Handler(kithttp.NewServer(
+		endpoints.AddBlacklistEndpoint,
+		decodeBlacklistRequest,
+		encodeResponse,
+		options...,
+	))
+
+	r.Methods("POST").Path("/blacklist/remove").Handler(kithttp.NewServer(
+		endpoints.RemoveBlacklistEndpoint,
+		decodeBlacklistRequest,
+		encodeResponse,
+		options...,
+	))
+
+	r.Methods("GET").Path("/blacklist/list").Handler(kithttp.NewServer(
+		endpoints.GetBlacklistEndpoint,
+		decodeGetListRequest,
+		encodeResponse,
+		options...,
+	))
+
+	r.Methods("GET").Path("/audit/list").Handler(kithttp.NewServer(
+		endpoints.GetAuditLogEndpoint,
+		decodeAuditQueryRequest,
+		encodeResponse,
+		options...,
+	))
+
 	r.Path("/metrics").Handler(promhttp.Handler())
 
 	// create health check handler
@@ -79,6 +218,31 @@ func MakeHttpHandler(ctx context.Context, endpoints endpts.SkAdminEndpoints, zip
 	return loggedRouter
 }
 
+// makeOAuth2AuthorizationContext 校验 Authorization 请求头中携带的访问令牌
+func makeOAuth2AuthorizationContext(oauthClient client.OAuthClient, logger log.Logger) kithttp.RequestFunc {
+
+	return func(ctx context.Context, r *http.Request) context.Context {
+		return authorizationContext(ctx, oauthClient, logger, r.Header.Get("Authorization"))
+	}
+}
+
+// authorizationContext 令牌有效时在请求上下文放入令牌绑定的用户信息, 供鉴权及审计记录操作人; 否则放入验证失败错误信息
+func authorizationContext(ctx context.Context, oauthClient client.OAuthClient, logger log.Logger, authorization string) context.Context {
+	accessToken := strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer "))
+	if accessToken == "" || oauthClient == nil {
+		return context.WithValue(ctx, endpts.OAuth2ErrorKey, ErrorTokenRequired)
+	}
+
+	resp, err := oauthClient.CheckToken(ctx, nil, &pb.CheckTokenRequest{Token: accessToken})
+	// 签发给服务的令牌可以不绑定用户, 但必须属于某个客户端
+	if err != nil || resp == nil || !resp.IsValidToken || (resp.UserDetails == nil && resp.ClientDetails == nil) {
+		logger.Log("check token failed", err)
+		return context.WithValue(ctx, endpts.OAuth2ErrorKey, ErrorInvalidToken)
+	}
+	ctx = context.WithValue(ctx, endpts.OAuth2ClientDetailsKey, resp.ClientDetails)
+	return context.WithValue(ctx, endpts.OAuth2DetailsKey, resp.UserDetails)
+}
+
 // decodeUserRequest decode request params to struct
 func decodeGetListRequest(_ context.Context, r *http.Request) (interface{}, error) {
 	return endpts.GetListRequest{}, nil
@@ -87,7 +251,25 @@ func decodeGetListRequest(_ context.Context, r *http.Request) (interface{}, erro
 // encode errors from business-logic
 func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if verr, ok := err.(*service.ValidationError); ok {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":  verr.Error(),
+			"fields": verr.Errors,
+		})
+		return
+	}
 	switch err {
+	case ErrorBadRequest, service.ErrInvalidBlacklist:
+		w.WriteHeader(http.StatusBadRequest)
+	case ErrorTokenRequired, ErrorInvalidToken, endpts.ErrInvalidUserRequest:
+		w.WriteHeader(http.StatusUnauthorized)
+	case endpts.ErrNotPermit:
+		w.WriteHeader(http.StatusForbidden)
+	case model.ErrProductNotFound, model.ErrActivityNotFound:
+		w.WriteHeader(http.StatusNotFound)
+	case model.ErrInsufficientStock, model.ErrStockBelowActivity, service.ErrProductInUse:
+		w.WriteHeader(http.StatusConflict)
 	default:
 		w.WriteHeader(http.StatusInternalServerError)
 	}
@@ -115,6 +297,17 @@ func decodeCreateProductCheckRequest(ctx context.Context, r *http.Request) (inte
 	return product, nil
 }
 
+func decodeUpdateProductRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var product model.Product
+	if err := json.NewDecoder(r.Body).Decode(&product); err != nil {
+		return nil, err
+	}
+	if product.ProductId <= 0 {
+		return nil, ErrorBadRequest
+	}
+	return product, nil
+}
+
 func decodeCreateActivityCheckRequest(ctx context.Context, r *http.Request) (interface{}, error) {
 	var activity model.Activity
 	if err := json.NewDecoder(r.Body).Decode(&activity); err != nil {
@@ -122,3 +315,120 @@ func decodeCreateActivityCheckRequest(ctx context.Context, r *http.Request) (int
 	}
 	return activity, nil
 }
+
+func decodeActivityIdRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req endpts.ActivityIdRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	if req.ActivityId <= 0 {
+		return nil, ErrorBadRequest
+	}
+	return req, nil
+}
+
+// decodeProductQueryRequest 从查询参数中解析分页及过滤条件
+// page, page_size, product_name, status
+func decodeProductQueryRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	values := r.URL.Query()
+	query := model.ProductQuery{
+		ProductName: values.Get("product_name"),
+		Status:      -1,
+	}
+
+	var err error
+	if v := values.Get("page"); v != "" {
+		if query.Page, err = strconv.Atoi(v); err != nil {
+			return nil, ErrorBadRequest
+		}
+	}
+	if v := values.Get("page_size"); v != "" {
+		if query.PageSize, err = strconv.Atoi(v); err != nil {
+			return nil, ErrorBadRequest
+		}
+	}
+	if v := values.Get("status"); v != "" {
+		if query.Status, err = strconv.Atoi(v); err != nil {
+			return nil, ErrorBadRequest
+		}
+	}
+	return query, nil
+}
+
+func decodeProductIdQueryRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	productId, err := strconv.Atoi(r.URL.Query().Get("product_id"))
+	if err != nil || productId <= 0 {
+		return nil, ErrorBadRequest
+	}
+	return endpts.ProductIdRequest{ProductId: productId}, nil
+}
+
+func decodeProductIdRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req endpts.ProductIdRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	if req.ProductId <= 0 {
+		return nil, ErrorBadRequest
+	}
+	return req, nil
+}
+
+func decodeAdjustStockRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req model.StockLog
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	if req.ProductId <= 0 || req.Delta == 0 {
+		return nil, ErrorBadRequest
+	}
+	return req, nil
+}
+
+// decodeDashboardRequest 解析查询参数 activity_id, 不传时返回全部活动
+func decodeDashboardRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req endpts.DashboardRequest
+	if v := r.URL.Query().Get("activity_id"); v != "" {
+		activityId, err := strconv.Atoi(v)
+		if err != nil || activityId <= 0 {
+			return nil, ErrorBadRequest
+		}
+		req.ActivityId = activityId
+	}
+	return req, nil
+}
+
+func decodeBlacklistRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	var req endpts.BlacklistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return nil, err
+	}
+	if req.Type == "" || req.Value == "" {
+		return nil, ErrorBadRequest
+	}
+	return req, nil
+}
+
+// decodeAuditQueryRequest 从查询参数中解析分页及过滤条件
+// page, page_size, resource, target_id, operator
+func decodeAuditQueryRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	values := r.URL.Query()
+	query := model.AuditQuery{
+		Resource: values.Get("resource"),
+		TargetId: values.Get("target_id"),
+		Operator: values.Get("operator"),
+	}
+
+	var err error
+	if v := values.Get("page"); v != "" {
+		if query.Page, err = strconv.Atoi(v); err != nil {
+			return nil, ErrorBadRequest
+		}
+	}
+	if v := values.Get("page_size"); v != "" {
+		if query.PageSize, err = strconv.Atoi(v); err != nil {
+			return nil, ErrorBadRequest
+		}
+	}
+	return query, nil
+}
@@ -0,0 +1,88 @@
+package transport
+
+import (
+	"context"
+	"encoding/csv"
+	"net/http"
+	"strconv"
+
+	endpts "github.com/lixichongAAA/seckill/sk-admin/endpoint"
+)
+
+type reportFormatKey struct{}
+
+const (
+	reportFormatJSON = "json"
+	reportFormatCSV  = "csv"
+)
+
+// reportFormatToContext 将查询参数 format 保存到 context 中, 供 encodeReportResponse 选择输出格式
+func reportFormatToContext(ctx context.Context, r *http.Request) context.Context {
+	format := r.URL.Query().Get("format")
+	if format != reportFormatCSV {
+		format = reportFormatJSON
+	}
+	return context.WithValue(ctx, reportFormatKey{}, format)
+}
+
+// decodeReportRequest 解析查询参数 activity_id
+func decodeReportRequest(ctx context.Context, r *http.Request) (interface{}, error) {
+	activityId, err := strconv.Atoi(r.URL.Query().Get("activity_id"))
+	if err != nil || activityId <= 0 {
+		return nil, ErrorBadRequest
+	}
+	return endpts.ReportRequest{ActivityId: activityId}, nil
+}
+
+// encodeReportResponse 按 format 以 JSON 或 CSV 输出报表
+func encodeReportResponse(ctx context.Context, w http.ResponseWriter, response interface{}) error {
+	if format, _ := ctx.Value(reportFormatKey{}).(string); format != reportFormatCSV {
+		return encodeResponse(ctx, w, response)
+	}
+
+	var name string
+	var records [][]string
+	switch resp := response.(type) {
+	case endpts.WinnerReportResponse:
+		name = "winners"
+		records = append(records, []string{"activity_id", "product_id", "user_id", "token", "token_time"})
+		for _, v := range resp.Result {
+			records = append(records, []string{
+				strconv.Itoa(v.ActivityId),
+				strconv.Itoa(v.ProductId),
+				strconv.Itoa(v.UserId),
+				v.Token,
+				strconv.FormatInt(v.TokenTime, 10),
+			})
+		}
+	case endpts.ThroughputReportResponse:
+		name = "throughput"
+		records = append(records, []string{"minute", "total", "winners"})
+		for _, v := range resp.Result {
+			records = append(records, []string{
+				strconv.FormatInt(v.Minute, 10),
+				strconv.FormatInt(v.Total, 10),
+				strconv.FormatInt(v.Winners, 10),
+			})
+		}
+	case endpts.RejectionReportResponse:
+		name = "rejections"
+		records = append(records, []string{"code", "count"})
+		for _, v := range resp.Result {
+			records = append(records, []string{
+				strconv.Itoa(v.Code),
+				strconv.FormatInt(v.Count, 10),
+			})
+		}
+	default:
+		return encodeResponse(ctx, w, response)
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename=\""+name+".csv\"")
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(records); err != nil {
+		return err
+	}
+	return writer.Error()
+}
@@ -0,0 +1,107 @@
+package transport
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/kit/endpoint"
+	"github.com/go-kit/kit/log"
+	kithttp "github.com/go-kit/kit/transport/http"
+	endpts "github.com/lixichongAAA/seckill/sk-admin/endpoint"
+)
+
+const (
+	defaultStreamInterval = time.Second
+	minStreamInterval     = time.Millisecond * 200
+)
+
+// streamAuthInterval 推送期间重新校验令牌的间隔
+var streamAuthInterval = time.Minute
+
+// makeDashboardStreamHandler 以 Server-Sent Events 的形式定时推送活动实时数据, 用于实时图表
+// 查询参数与 /dashboard 相同, 另外可通过 interval(毫秒) 指定推送间隔
+// 首次查询通过 open 并受管理接口限流, 失败(如令牌无效)时直接返回错误响应, 不建立推送连接;
+// 之后的推送通过 tick, 并定时重新校验令牌, 令牌失效或权限被收回时推送错误事件后断开连接
+func makeDashboardStreamHandler(open, tick endpoint.Endpoint, before kithttp.RequestFunc, logger log.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		request, err := decodeDashboardRequest(r.Context(), r)
+		if err != nil {
+			encodeError(r.Context(), err, w)
+			return
+		}
+		interval := defaultStreamInterval
+		if v := r.URL.Query().Get("interval"); v != "" {
+			ms, err := strconv.Atoi(v)
+			if err != nil {
+				encodeError(r.Context(), ErrorBadRequest, w)
+				return
+			}
+			interval = time.Duration(ms) * time.Millisecond
+			if interval < minStreamInterval {
+				interval = minStreamInterval
+			}
+		}
+
+		ctx := before(r.Context(), r)
+		authAt := time.Now()
+		response, err := open(ctx, request)
+		if err != nil {
+			encodeError(ctx, err, w)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			event, data := "dashboard", []byte(nil)
+			if err == nil {
+				data, err = json.Marshal(response)
+			}
+			if err != nil {
+				_ = logger.Log("stream", "dashboard", "err", err)
+				event = "error"
+				data, _ = json.Marshal(map[string]interface{}{"error": err.Error()})
+			}
+			if _, writeErr := w.Write([]byte("event: " + event + "\ndata: " + string(data) + "\n\n")); writeErr != nil {
+				return
+			}
+			flusher.Flush()
+			if isAuthError(err) {
+				return
+			}
+
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ticker.C:
+			}
+			if time.Since(authAt) >= streamAuthInterval {
+				ctx = before(r.Context(), r)
+				authAt = time.Now()
+			}
+			response, err = tick(ctx, request)
+		}
+	})
+}
+
+// isAuthError 令牌无效或权限不足
+func isAuthError(err error) bool {
+	switch err {
+	case ErrorTokenRequired, ErrorInvalidToken, endpts.ErrInvalidUserRequest, endpts.ErrNotPermit:
+		return true
+	}
+	return false
+}
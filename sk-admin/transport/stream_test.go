@@ -0,0 +1,79 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	endpts "github.com/lixichongAAA/seckill/sk-admin/endpoint"
+)
+
+type tokenKey struct{}
+
+func TestDashboardStreamRechecksToken(t *testing.T) {
+	defer func(interval time.Duration) { streamAuthInterval = interval }(streamAuthInterval)
+	streamAuthInterval = 0
+
+	var opened, ticks, checks int32
+	open := func(ctx context.Context, request interface{}) (interface{}, error) {
+		atomic.AddInt32(&opened, 1)
+		return endpts.DashboardResponse{}, nil
+	}
+	tick := func(ctx context.Context, request interface{}) (interface{}, error) {
+		if ctx.Value(tokenKey{}) != true {
+			return nil, endpts.ErrNotPermit
+		}
+		atomic.AddInt32(&ticks, 1)
+		return endpts.DashboardResponse{}, nil
+	}
+	// 第三次校验时令牌失效
+	before := func(ctx context.Context, r *http.Request) context.Context {
+		return context.WithValue(ctx, tokenKey{}, atomic.AddInt32(&checks, 1) < 3)
+	}
+
+	handler := makeDashboardStreamHandler(open, tick, before, log.NewNopLogger())
+	w := httptest.NewRecorder()
+	done := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/dashboard/stream?interval=1", nil))
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream should end once the token is no longer valid")
+	}
+
+	if opened != 1 {
+		t.Errorf("open should be called once, got %d", opened)
+	}
+	if ticks != 1 {
+		t.Errorf("got %d ticks before the token expired, want 1", ticks)
+	}
+	body := w.Body.String()
+	if strings.Count(body, "event: dashboard") != 2 || !strings.HasSuffix(body, "event: error\ndata: {\"error\":\"not permit\"}\n\n") {
+		t.Errorf("unexpected stream body %q", body)
+	}
+}
+
+func TestDashboardStreamOpenFailure(t *testing.T) {
+	open := func(ctx context.Context, request interface{}) (interface{}, error) {
+		return nil, ErrorInvalidToken
+	}
+	tick := func(ctx context.Context, request interface{}) (interface{}, error) {
+		t.Error("tick should not be called when open fails")
+		return nil, nil
+	}
+	before := func(ctx context.Context, r *http.Request) context.Context { return ctx }
+
+	w := httptest.NewRecorder()
+	makeDashboardStreamHandler(open, tick, before, log.NewNopLogger()).ServeHTTP(w, httptest.NewRequest("GET", "/dashboard/stream", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("got status %d, want 401", w.Code)
+	}
+}
@@ -6,7 +6,6 @@ import (
 
 	"github.com/go-kit/kit/log"
 	"github.com/lixichongAAA/seckill/pkg/bootstrap"
-	_ "github.com/lixichongAAA/seckill/pkg/bootstrap"
 	conf "github.com/lixichongAAA/seckill/pkg/config"
 	"github.com/lixichongAAA/seckill/sk-app/model"
 	"github.com/openzipkin/zipkin-go"
@@ -23,6 +22,7 @@ var ZipkinTracer *zipkin.Tracer
 var Logger log.Logger
 
 func init() {
+	bootstrap.Load()
 	Logger = log.NewLogfmtLogger(os.Stderr)
 	Logger = log.With(Logger, "ts", log.DefaultTimestampUTC)
 	Logger = log.With(Logger, "caller", log.DefaultCaller)
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"flag"
+
 	"github.com/lixichongAAA/seckill/pkg/bootstrap"
 	conf "github.com/lixichongAAA/seckill/pkg/config"
 	"github.com/lixichongAAA/seckill/pkg/mysql"
 	"github.com/lixichongAAA/seckill/sk-app/setup"
 )
 
+// 商品配置来源, 默认从 Zookeeper 加载, 设置为 sk-admin 时通过 gRPC 订阅 sk-admin 推送的配置
+var productConfSource = flag.String("product-conf", "zk", "load product config from zk or subscribe to sk-admin")
+
 // 秒杀业务系统主要为前端/移动端提供秒杀活动查询和进行秒杀的HTTP接口，处理有关用户ID和IP
 // 黑白名单 和进行流量限制的逻辑，并通过Redis将合法的秒杀请求发送给秒杀核心业务，
 // 并将秒杀核心业务的处理结果返回给前端/移动端
@@ -15,8 +20,13 @@ import (
 // 从 Zookeeper 中加载秒杀活动数据到内存中，监听Zookeeper中的数据变化,
 // 并实时更新数据到内存中.建立Redis连接，启动工作协程.
 func main() {
+	flag.Parse()
 	mysql.InitMysql(conf.MysqlConfig.Host, conf.MysqlConfig.Port, conf.MysqlConfig.User, conf.MysqlConfig.Pwd, conf.MysqlConfig.Db)
-	setup.InitZk()
+	if *productConfSource == "sk-admin" {
+		setup.InitProductConfWatch()
+	} else {
+		setup.InitZk()
+	}
 	setup.InitRedis()
 	setup.InitServer(bootstrap.HttpConfig.Host, bootstrap.HttpConfig.Port)
 }
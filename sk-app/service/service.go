@@ -7,6 +7,7 @@ import (
 	"time"
 
 	conf "github.com/lixichongAAA/seckill/pkg/config"
+	"github.com/lixichongAAA/seckill/pkg/stats"
 	"github.com/lixichongAAA/seckill/sk-app/config"
 	"github.com/lixichongAAA/seckill/sk-app/model"
 	"github.com/lixichongAAA/seckill/sk-app/service/srv_err"
@@ -61,19 +62,28 @@ func (s SkAppService) SecKill(req *model.SecRequest) (map[string]interface{}, in
 	config.SkAppContext.RWSecProductLock.RLock()
 	defer config.SkAppContext.RWSecProductLock.RUnlock()
 	var code int
+	// 记录活动的请求数, sk-app 中被拒绝的请求按错误码计数, sk-core 的处理结果由 sk-core 计数
+	var activityId int
+	if product, ok := conf.SecKill.SecProductInfoMap[req.ProductId]; ok {
+		activityId = product.ActivityId
+	}
+	stats.DefaultRecorder.Incr(activityId, stats.FieldRequests)
 	// 进行 ID和IP 的黑名单校验以及 秒级、分级 的访问频率限制
 	err := srv_limit.AntiSpam(req)
 	if err != nil {
 		code = srv_err.ErrUserServiceBusy
 		log.Printf("userId antiSpam [%d] failed, req[%v]", req.UserId, err)
+		stats.DefaultRecorder.Reject(activityId, code)
 		return nil, code, err
 	}
 	// 获取秒杀商品信息
 	data, code, err := SecInfoById(req.ProductId)
 	if err != nil {
 		log.Printf("userId[%d] secInfoById Id failed, req[%v]", req.UserId, req)
+		stats.DefaultRecorder.Reject(activityId, code)
 		return nil, code, err
 	}
+	stats.DefaultRecorder.Incr(activityId, stats.FieldAdmitted)
 
 	userKey := fmt.Sprintf("%d_%d", req.UserId, req.ProductId)
 	ResultChan := make(chan *model.SecResult, 1)
@@ -100,10 +110,12 @@ func (s SkAppService) SecKill(req *model.SecRequest) (map[string]interface{}, in
 	case <-ticker.C: // 超时
 		code = srv_err.ErrProcessTimeout
 		err = fmt.Errorf("request timeout")
+		stats.DefaultRecorder.Reject(activityId, code)
 		return nil, code, err
 	case <-req.CloseNotify: // Ctrl + c
 		code = srv_err.ErrClientClosed
 		err = fmt.Errorf("client already closed")
+		stats.DefaultRecorder.Reject(activityId, code)
 		return nil, code, err
 	case result := <-ResultChan: // 处理返回结果
 		code = result.Code
@@ -113,6 +125,7 @@ func (s SkAppService) SecKill(req *model.SecRequest) (map[string]interface{}, in
 		log.Printf("secKill success")
 		data["product_id"] = result.ProductId
 		data["token"] = result.Token
+		data["token_time"] = result.TokenTime
 		data["user_id"] = result.UserId
 		return data, code, nil
 	}
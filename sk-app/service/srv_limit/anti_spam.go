@@ -25,8 +25,14 @@ var SecLimitMgrVars = &SecLimitMgr{
 // 进行ID 和 IP 的黑名单校验
 // 针对ID 和 IP 进行流量限制，限制 秒级 和 分级 的访问频率
 func AntiSpam(req *model.SecRequest) (err error) {
+	//黑名单会被定时整体替换, 读取时需持有读锁
+	conf.SecKill.RWBlackLock.RLock()
+	_, idBlocked := conf.SecKill.IDBlackMap[req.UserId]
+	_, ipBlocked := conf.SecKill.IPBlackMap[req.ClientAddr]
+	conf.SecKill.RWBlackLock.RUnlock()
+
 	//判断用户Id是否在黑名单
-	_, ok := conf.SecKill.IDBlackMap[req.UserId]
+	ok := idBlocked
 	if ok {
 		err = fmt.Errorf("invalid request")
 		log.Printf("user[%v] is block by id black", req.UserId)
@@ -34,7 +40,7 @@ func AntiSpam(req *model.SecRequest) (err error) {
 	}
 
 	//判断客户端IP是否在黑名单
-	_, ok = conf.SecKill.IPBlackMap[req.ClientAddr]
+	ok = ipBlocked
 	if ok {
 		err = fmt.Errorf("invalid request")
 		log.Printf("userId[%v] ip[%v] is block by ip black", req.UserId, req.ClientAddr)
@@ -6,6 +6,7 @@ import (
 
 	"github.com/go-redis/redis"
 	conf "github.com/lixichongAAA/seckill/pkg/config"
+	"github.com/lixichongAAA/seckill/pkg/stats"
 	"github.com/lixichongAAA/seckill/sk-app/service/srv_redis"
 	"github.com/unknwon/com"
 )
@@ -30,44 +31,64 @@ func InitRedis() {
 
 	loadBlackList(client)
 	initRedisProcess()
+	go stats.DefaultRecorder.Run()
 }
 
-// 加载黑名单列表, 启动协程调用 syncIdBlackList 和 syncIpBlackList 来定时更新黑名单
+// blackListReloadInterval 定时从 hash 表重新加载黑名单的间隔, 从黑名单中删除的用户在重新加载后生效
+const blackListReloadInterval = time.Second * 10
+
+// 加载黑名单列表, 启动协程调用 syncIdBlackList 和 syncIpBlackList 来实时同步新增的黑名单,
+// 并定时重新加载整个黑名单
 func loadBlackList(conn *redis.Client) {
 	conf.SecKill.IPBlackMap = make(map[string]bool, 10000)
 	conf.SecKill.IDBlackMap = make(map[int]bool, 10000)
+	if err := reloadBlackList(conn); err != nil {
+		log.Printf("load black list failed. Error : %v", err)
+	}
 
+	go syncIpBlackList(conn)
+	go syncIdBlackList(conn)
+	go func() {
+		for range time.Tick(blackListReloadInterval) {
+			if err := reloadBlackList(conn); err != nil {
+				log.Printf("reload black list failed. Error : %v", err)
+			}
+		}
+	}()
+}
+
+// reloadBlackList 从 hash 表读取完整的黑名单并替换内存中的黑名单
+func reloadBlackList(conn *redis.Client) error {
 	//用户Id
 	idList, err := conn.HGetAll(conf.Redis.IdBlackListHash).Result()
-
 	if err != nil {
-		log.Printf("hget all failed. Error : %v", err)
-		return
+		return err
 	}
-
+	idBlackMap := make(map[int]bool, len(idList))
 	for _, v := range idList {
 		id, err := com.StrTo(v).Int()
 		if err != nil {
-			log.Printf("invalid user id [%v]", id)
+			log.Printf("invalid user id [%v]", v)
 			continue
 		}
-		conf.SecKill.IDBlackMap[id] = true
+		idBlackMap[id] = true
 	}
 
 	//用户Ip
 	ipList, err := conn.HGetAll(conf.Redis.IpBlackListHash).Result()
 	if err != nil {
-		log.Printf("hget all failed. Error : %v", err)
-		return
+		return err
 	}
-
+	ipBlackMap := make(map[string]bool, len(ipList))
 	for _, v := range ipList {
-		conf.SecKill.IPBlackMap[v] = true
+		ipBlackMap[v] = true
 	}
 
-	go syncIpBlackList(conn)
-	go syncIdBlackList(conn)
-	return
+	conf.SecKill.RWBlackLock.Lock()
+	conf.SecKill.IDBlackMap = idBlackMap
+	conf.SecKill.IPBlackMap = ipBlackMap
+	conf.SecKill.RWBlackLock.Unlock()
+	return nil
 }
 
 // 同步用户ID黑名单
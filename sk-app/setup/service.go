@@ -6,6 +6,9 @@ import (
 	"fmt"
 	//kitprometheus "github.com/go-kit/kit/metrics/prometheus"
 	kitzipkin "github.com/go-kit/kit/tracing/zipkin"
+	"github.com/lixichongAAA/seckill/pb"
+	"github.com/lixichongAAA/seckill/pkg/bootstrap"
+	"github.com/lixichongAAA/seckill/pkg/client"
 	localconfig "github.com/lixichongAAA/seckill/pkg/config"
 	register "github.com/lixichongAAA/seckill/pkg/discover"
 	"github.com/lixichongAAA/seckill/sk-app/endpoint"
@@ -15,6 +18,7 @@ import (
 
 	//stdprometheus "github.com/prometheus/client_golang/prometheus"
 	"log"
+	"net"
 	"net/http"
 	_ "net/http/pprof"
 	"os"
@@ -23,6 +27,7 @@ import (
 	"time"
 
 	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
 )
 
 // 初始化Http服务
@@ -105,6 +110,25 @@ func InitServer(host string, servicePort string) {
 		handler := r
 		errChan <- http.ListenAndServe(":"+servicePort, handler)
 	}()
+	//grpc server, 端口通过服务注册的 rpcPort 元数据对外公布
+	go func() {
+		fmt.Println("grpc Server start at port:" + bootstrap.RpcConfig.Port)
+		listener, err := net.Listen("tcp", ":"+bootstrap.RpcConfig.Port)
+		if err != nil {
+			errChan <- err
+			return
+		}
+		oauthClient, err := client.NewOAuthClient("oauth", nil, nil)
+		if err != nil {
+			errChan <- err
+			return
+		}
+		serverTracer := kitzipkin.GRPCServerTrace(localconfig.ZipkinTracer, kitzipkin.Name("grpc-transport"))
+		handler := transport.NewGRPCServer(ctx, endpts, oauthClient, serverTracer, localconfig.Logger)
+		gRPCServer := grpc.NewServer()
+		pb.RegisterSecKillServiceServer(gRPCServer, handler)
+		errChan <- gRPCServer.Serve(listener)
+	}()
 
 	go func() {
 		c := make(chan os.Signal, 1)
@@ -0,0 +1,68 @@
+package transport
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/transport/grpc"
+	"github.com/lixichongAAA/seckill/pb"
+	"github.com/lixichongAAA/seckill/pkg/client"
+	endpts "github.com/lixichongAAA/seckill/sk-app/endpoint"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// grpcUserIdKey 请求上下文中保存令牌所属用户Id的 key
+type grpcUserIdKey struct{}
+
+type grpcServer struct {
+	secKill grpc.Handler
+}
+
+func (s *grpcServer) SecKill(ctx context.Context, r *pb.SecRequest) (*pb.SecResponse, error) {
+	_, resp, err := s.secKill.ServeGRPC(ctx, r)
+	if err == ErrorIdentityMissing {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.SecResponse), nil
+}
+
+// NewGRPCServer 访问令牌通过 authorization 元数据传递, 秒杀用户为令牌所属的用户
+func NewGRPCServer(ctx context.Context, endpoints endpts.SkAppEndpoints, oauthClient client.OAuthClient, serverTracer grpc.ServerOption, logger log.Logger) pb.SecKillServiceServer {
+	return &grpcServer{
+		secKill: grpc.NewServer(
+			endpoints.SecKillEndpoint,
+			DecodeGRPCSecKillRequest,
+			EncodeGRPCSecKillResponse,
+			grpc.ServerBefore(makeGRPCIdentityContext(oauthClient, logger)),
+			serverTracer,
+		),
+	}
+}
+
+// makeGRPCIdentityContext 校验访问令牌, 将令牌所属的用户Id放入请求上下文; 未携带令牌或令牌无效时不放入
+func makeGRPCIdentityContext(oauthClient client.OAuthClient, logger log.Logger) grpc.ServerRequestFunc {
+
+	return func(ctx context.Context, md metadata.MD) context.Context {
+		var authorization string
+		if values := md.Get("authorization"); len(values) > 0 {
+			authorization = values[0]
+		}
+		accessToken := strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer "))
+		if accessToken == "" || oauthClient == nil {
+			return ctx
+		}
+
+		resp, err := oauthClient.CheckToken(ctx, nil, &pb.CheckTokenRequest{Token: accessToken})
+		if err != nil || resp == nil || !resp.IsValidToken || resp.UserDetails == nil {
+			logger.Log("check token failed", err)
+			return ctx
+		}
+		return context.WithValue(ctx, grpcUserIdKey{}, resp.UserDetails.UserId)
+	}
+}
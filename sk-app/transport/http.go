@@ -4,7 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/tracing/zipkin"
@@ -18,9 +22,13 @@ import (
 )
 
 var (
-	ErrorBadRequest = errors.New("invalid request parameter")
+	ErrorBadRequest      = errors.New("invalid request parameter")
+	ErrorIdentityMissing = errors.New("user identity is missing")
 )
 
+// 网关校验令牌后写入的用户Id请求头, 客户端携带的同名请求头会被网关清除
+const headerUserId = "X-User-Id"
+
 // MakeHttpHandler make http handler use mux
 func MakeHttpHandler(ctx context.Context, endpoints endpts.SkAppEndpoints, zipkinTracer *gozipkin.Tracer, logger log.Logger) http.Handler {
 	r := mux.NewRouter()
@@ -91,6 +99,8 @@ func decodeSecInfoRequest(_ context.Context, r *http.Request) (interface{}, erro
 func encodeError(_ context.Context, err error, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	switch err {
+	case ErrorIdentityMissing:
+		w.WriteHeader(http.StatusUnauthorized)
 	default:
 		w.WriteHeader(http.StatusInternalServerError)
 	}
@@ -118,10 +128,35 @@ func decodeSecInfoListRequest(ctx context.Context, r *http.Request) (interface{}
 	return nil, nil
 }
 
+// decodeSecKillRequest 用户Id取自网关写入的请求头, 客户端地址和访问时间由服务端填写, 忽略请求体中的同名字段
 func decodeSecKillRequest(ctx context.Context, r *http.Request) (interface{}, error) {
 	var secRequest model.SecRequest
 	if err := json.NewDecoder(r.Body).Decode(&secRequest); err != nil {
 		return nil, err
 	}
+
+	userId, err := strconv.Atoi(r.Header.Get(headerUserId))
+	if err != nil || userId <= 0 {
+		return nil, ErrorIdentityMissing
+	}
+	secRequest.UserId = userId
+	secRequest.ClientAddr = clientAddr(r)
+	secRequest.AccessTime = time.Now().Unix()
 	return secRequest, nil
 }
+
+// clientAddr 取 X-Forwarded-For 的最后一项, 即网关看到的对端地址, 前面的项可由客户端伪造;
+// 未经网关转发时使用 RemoteAddr
+func clientAddr(r *http.Request) string {
+	if values := r.Header.Values("X-Forwarded-For"); len(values) > 0 {
+		addrs := strings.Split(values[len(values)-1], ",")
+		if addr := strings.TrimSpace(addrs[len(addrs)-1]); addr != "" {
+			return addr
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,109 @@
+package transport
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/lixichongAAA/seckill/pb"
+	"github.com/lixichongAAA/seckill/sk-app/endpoint"
+	"github.com/lixichongAAA/seckill/sk-app/model"
+	"google.golang.org/grpc/peer"
+)
+
+// DecodeGRPCSecKillRequest 将 gRPC 请求转换为秒杀请求, 调用方取消或超时时通过 CloseNotify 通知秒杀服务
+// 与 http 接口相同, 用户Id取自访问令牌, 客户端地址取自连接的对端地址, 访问时间由服务端填写, 忽略请求中的同名字段
+func DecodeGRPCSecKillRequest(ctx context.Context, r interface{}) (interface{}, error) {
+	req := r.(*pb.SecRequest)
+	userId, ok := ctx.Value(grpcUserIdKey{}).(int64)
+	if !ok || userId <= 0 {
+		return nil, ErrorIdentityMissing
+	}
+	secTime, _ := strconv.ParseInt(req.SecTime, 10, 64)
+
+	closeNotify := make(chan bool, 1)
+	go func() {
+		<-ctx.Done()
+		closeNotify <- true
+	}()
+
+	return model.SecRequest{
+		ProductId:     int(req.ProductId),
+		Source:        req.Source,
+		AuthCode:      req.AuthCode,
+		SecTime:       secTime,
+		Nance:         req.Nance,
+		UserId:        int(userId),
+		UserAuthSign:  req.UserAuthSign,
+		AccessTime:    time.Now().Unix(),
+		ClientAddr:    peerAddr(ctx),
+		ClientRefence: req.ClientRefence,
+		CloseNotify:   closeNotify,
+	}, nil
+}
+
+// peerAddr 返回 gRPC 连接对端的 IP
+func peerAddr(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// EncodeGRPCSecKillResponse 秒杀失败时只返回错误码
+func EncodeGRPCSecKillResponse(_ context.Context, r interface{}) (interface{}, error) {
+	resp := r.(endpoint.Response)
+
+	result := &pb.SecResponse{
+		Code: int64(resp.Code),
+	}
+	if resp.Error != nil {
+		return result, nil
+	}
+	if v, ok := resp.Result["product_id"].(int); ok {
+		result.ProductId = int64(v)
+	}
+	if v, ok := resp.Result["user_id"].(int); ok {
+		result.UserId = int64(v)
+	}
+	if v, ok := resp.Result["token"].(string); ok {
+		result.Token = v
+	}
+	if v, ok := resp.Result["token_time"].(int64); ok {
+		result.TokenTime = v
+	}
+	return result, nil
+}
+
+func EncodeGRPCSecKillRequest(_ context.Context, r interface{}) (interface{}, error) {
+	req := r.(model.SecRequest)
+	return &pb.SecRequest{
+		ProductId:     int64(req.ProductId),
+		Source:        req.Source,
+		AuthCode:      req.AuthCode,
+		SecTime:       strconv.FormatInt(req.SecTime, 10),
+		Nance:         req.Nance,
+		UserId:        int64(req.UserId),
+		UserAuthSign:  req.UserAuthSign,
+		AccessTime:    req.AccessTime,
+		ClientAddr:    req.ClientAddr,
+		ClientRefence: req.ClientRefence,
+	}, nil
+}
+
+func DecodeGRPCSecKillResponse(_ context.Context, r interface{}) (interface{}, error) {
+	resp := r.(*pb.SecResponse)
+	return model.SecResult{
+		ProductId: int(resp.ProductId),
+		UserId:    int(resp.UserId),
+		Token:     resp.Token,
+		TokenTime: resp.TokenTime,
+		Code:      int(resp.Code),
+	}, nil
+}
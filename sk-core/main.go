@@ -1,11 +1,26 @@
 package main
 
-import "github.com/lixichongAAA/seckill/sk-core/setup"
+import (
+	"flag"
+
+	conf "github.com/lixichongAAA/seckill/pkg/config"
+	"github.com/lixichongAAA/seckill/pkg/mysql"
+	"github.com/lixichongAAA/seckill/sk-core/setup"
+)
+
+// 商品配置来源, 默认从 Zookeeper 加载, 设置为 sk-admin 时通过 gRPC 订阅 sk-admin 推送的配置
+var productConfSource = flag.String("product-conf", "zk", "load product config from zk or subscribe to sk-admin")
 
 // 首先，从 Zookeeper 中加载秒杀活动数据到内存中，监听Zookeeper中的数据变化,
 // 实时更新数据到内存中，建立Redis连接，启动工作协程，和秒杀业务系统中类似.
 func main() {
-	setup.InitZk()
+	flag.Parse()
+	mysql.InitMysql(conf.MysqlConfig.Host, conf.MysqlConfig.Port, conf.MysqlConfig.User, conf.MysqlConfig.Pwd, conf.MysqlConfig.Db)
+	if *productConfSource == "sk-admin" {
+		setup.InitProductConfWatch()
+	} else {
+		setup.InitZk()
+	}
 	setup.InitRedis()
 	setup.RunService()
 }
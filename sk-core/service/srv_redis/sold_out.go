@@ -0,0 +1,22 @@
+package srv_redis
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	conf "github.com/lixichongAAA/seckill/pkg/config"
+)
+
+// ReportSoldOut 将售罄的活动写入 Redis 的售罄活动hash表
+// sk-admin 的活动调度器据此将活动标记为已售罄并重新发布商品配置
+func ReportSoldOut(product *conf.SecProductInfoConf) {
+	conn := conf.Redis.RedisConn
+	if conn == nil || product.ActivityId <= 0 {
+		return
+	}
+	err := conn.HSet(conf.Redis.SoldOutHashName(), strconv.Itoa(product.ActivityId), time.Now().Unix()).Err()
+	if err != nil {
+		log.Printf("report sold out of activity %d failed, err : %v", product.ActivityId, err)
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"time"
 
 	conf "github.com/lixichongAAA/seckill/pkg/config"
+	"github.com/lixichongAAA/seckill/pkg/stats"
 	"github.com/lixichongAAA/seckill/sk-core/config"
 	"github.com/lixichongAAA/seckill/sk-core/service/srv_err"
+	"github.com/lixichongAAA/seckill/sk-core/service/srv_result"
 	"github.com/lixichongAAA/seckill/sk-core/service/srv_user"
 )
 
@@ -27,6 +29,7 @@ func HandleUser() {
 				Code: srv_err.ErrServiceBusy,
 			}
 		}
+		recordResult(req, res)
 		fmt.Println("处理中~~ ", res)
 		timer := time.NewTicker(time.Millisecond * time.Duration(conf.SecKill.SendToWriteChanTimeout))
 		select {
@@ -84,6 +87,9 @@ func HandleSeckill(req *config.SecRequest) (res *config.SecResult, err error) {
 
 	if curSoldCount >= product.Total {
 		res.Code = srv_err.ErrSoldout
+		if product.Status != srv_err.ProductStatusSoldout {
+			go ReportSoldOut(product)
+		}
 		product.Status = srv_err.ProductStatusSoldout
 		return
 	}
@@ -107,3 +113,30 @@ func HandleSeckill(req *config.SecRequest) (res *config.SecResult, err error) {
 
 	return
 }
+
+// recordResult 按处理结果记录活动的处理数、抢购成功数和按错误码统计的拒绝数,
+// 并将处理结果提交给 srv_result 异步写入 Mysql
+func recordResult(req *config.SecRequest, res *config.SecResult) {
+	config.SecLayerCtx.RWSecProductLock.RLock()
+	product, ok := conf.SecKill.SecProductInfoMap[req.ProductId]
+	config.SecLayerCtx.RWSecProductLock.RUnlock()
+	if !ok {
+		return
+	}
+
+	srv_result.DefaultWriter.Record(&srv_result.SecResult{
+		ActivityId: product.ActivityId,
+		ProductId:  req.ProductId,
+		UserId:     req.UserId,
+		Code:       res.Code,
+		Token:      res.Token,
+		TokenTime:  res.TokenTime,
+	})
+
+	stats.DefaultRecorder.Incr(product.ActivityId, stats.FieldHandled)
+	if res.Code == srv_err.ErrSecKillSucc {
+		stats.DefaultRecorder.Incr(product.ActivityId, stats.FieldWinners)
+		return
+	}
+	stats.DefaultRecorder.Reject(product.ActivityId, res.Code)
+}
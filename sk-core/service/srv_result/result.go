@@ -0,0 +1,114 @@
+package srv_result
+
+import (
+	"log"
+	"time"
+
+	"github.com/lixichongAAA/seckill/pkg/mysql"
+)
+
+const (
+	queueSize       = 10240                  //待写入结果的缓冲大小
+	batchSize       = 200                    //每批写入的最大条数
+	flushInterval   = time.Millisecond * 500 //不足一批时的写入间隔
+	retryBackoff    = time.Millisecond * 100 //写入失败后首次重试的等待时间, 之后每次翻倍
+	maxRetryBackoff = time.Second * 5        //写入失败后重试的最长等待时间
+	tableName       = "sec_result"
+)
+
+// SecResult 一次秒杀请求的处理结果, 供活动结束后统计中奖用户、吞吐量及拒绝原因
+type SecResult struct {
+	ActivityId int
+	ProductId  int
+	UserId     int
+	Code       int
+	Token      string
+	TokenTime  int64
+	CreateTime int64
+}
+
+// ResultWriter 将处理结果异步批量写入 Mysql, 每条结果都会被写入:
+// 写入失败的批次按退避时间重试直到成功, 重试期间不再从缓冲区取出结果;
+// 缓冲区满时 Record 阻塞等待, 以此降低秒杀处理的速度
+type ResultWriter struct {
+	queue  chan *SecResult
+	insert func(batch []map[string]interface{}) error
+}
+
+var DefaultWriter = NewResultWriter()
+
+func NewResultWriter() *ResultWriter {
+	return newResultWriter(queueSize, insertResults)
+}
+
+func newResultWriter(size int, insert func(batch []map[string]interface{}) error) *ResultWriter {
+	return &ResultWriter{
+		queue:  make(chan *SecResult, size),
+		insert: insert,
+	}
+}
+
+// Record 提交一条处理结果, 缓冲区满时阻塞直到有空间
+func (w *ResultWriter) Record(result *SecResult) {
+	if result.CreateTime == 0 {
+		result.CreateTime = time.Now().Unix()
+	}
+	select {
+	case w.queue <- result:
+	default:
+		log.Printf("result queue is full, wait for writing results")
+		w.queue <- result
+	}
+}
+
+// Run 循环从缓冲区中取出结果, 攒够一批或到达写入间隔时写入 Mysql
+func (w *ResultWriter) Run() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]map[string]interface{}, 0, batchSize)
+	for {
+		select {
+		case result := <-w.queue:
+			batch = append(batch, map[string]interface{}{
+				"activity_id": result.ActivityId,
+				"product_id":  result.ProductId,
+				"user_id":     result.UserId,
+				"code":        result.Code,
+				"token":       result.Token,
+				"token_time":  result.TokenTime,
+				"create_time": result.CreateTime,
+			})
+			if len(batch) < batchSize {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		}
+		w.flush(batch)
+		batch = make([]map[string]interface{}, 0, batchSize)
+	}
+}
+
+// flush 写入一批结果, 失败时按退避时间重试直到成功
+func (w *ResultWriter) flush(batch []map[string]interface{}) {
+	backoff := retryBackoff
+	for attempt := 1; ; attempt++ {
+		err := w.insert(batch)
+		if err == nil {
+			return
+		}
+		log.Printf("write %d results failed, attempt %d, retry after %v, err : %v", len(batch), attempt, backoff, err)
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
+
+func insertResults(batch []map[string]interface{}) error {
+	_, err := mysql.DB().Table(tableName).Data(batch).Insert()
+	return err
+}
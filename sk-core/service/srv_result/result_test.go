@@ -0,0 +1,63 @@
+package srv_result
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestResultWriterKeepsEveryResult(t *testing.T) {
+	var lock sync.Mutex
+	var calls int
+	written := make(map[int]bool)
+	// 前两次写入失败
+	insert := func(batch []map[string]interface{}) error {
+		lock.Lock()
+		defer lock.Unlock()
+		calls++
+		if calls <= 2 {
+			return errors.New("mysql unavailable")
+		}
+		for _, row := range batch {
+			written[row["user_id"].(int)] = true
+		}
+		return nil
+	}
+	writer := newResultWriter(1, insert)
+	go writer.Run()
+
+	// 缓冲区只有 1 个位置, 写入失败重试期间 Record 阻塞而不是丢弃结果
+	const total = 20
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < total; i++ {
+			writer.Record(&SecResult{ActivityId: 1, UserId: i})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("Record should return once results are written")
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		lock.Lock()
+		n := len(written)
+		lock.Unlock()
+		if n == total {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d results written, want %d", n, total)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	if calls < 3 {
+		t.Errorf("failed batches should be retried, got %d calls", calls)
+	}
+}
@@ -0,0 +1,19 @@
+package setup
+
+import (
+	"context"
+	"log"
+
+	"github.com/lixichongAAA/seckill/pkg/client"
+)
+
+// InitProductConfWatch 从 sk-admin 订阅商品配置, 替代从 Zookeeper 加载
+func InitProductConfWatch() {
+	activityClient, err := client.NewActivityClient("sk-admin", nil, nil)
+	if err != nil {
+		log.Printf("create activity client failed, err : %v", err)
+		return
+	}
+	watcher := client.NewProductConfigWatcher(activityClient, updateSecProductInfo)
+	go watcher.Run(context.Background())
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/go-redis/redis"
 	conf "github.com/lixichongAAA/seckill/pkg/config"
+	"github.com/lixichongAAA/seckill/pkg/stats"
 )
 
 // 初始化redis
@@ -21,4 +22,6 @@ func InitRedis() {
 	}
 	// 保存连接
 	conf.Redis.RedisConn = client
+	// 定时将活动计数写入Redis
+	go stats.DefaultRecorder.Run()
 }
@@ -8,11 +8,14 @@ import (
 
 	register "github.com/lixichongAAA/seckill/pkg/discover"
 	"github.com/lixichongAAA/seckill/sk-core/service/srv_redis"
+	"github.com/lixichongAAA/seckill/sk-core/service/srv_result"
 )
 
 func RunService() {
 	//启动处理线程
 	srv_redis.RunProcess()
+	//异步写入处理结果
+	go srv_result.DefaultWriter.Run()
 	errChan := make(chan error)
 	//http server
 	go func() {
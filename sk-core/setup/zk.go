@@ -74,10 +74,7 @@ func waitSecProductEvent(event zk.Event) {
 
 // 更新秒杀商品信息
 func updateSecProductInfo(secProductInfo []*conf.SecProductInfoConf) {
-	tmp := make(map[int]*conf.SecProductInfoConf, 1024)
-	for _, v := range secProductInfo {
-		tmp[v.ProductId] = v
-	}
+	tmp := conf.NewSecProductInfoMap(secProductInfo, time.Now().Unix())
 	conf.SecKill.RWBlackLock.Lock()
 	conf.SecKill.SecProductInfoMap = tmp
 	conf.SecKill.RWBlackLock.Unlock()